@@ -36,27 +36,47 @@ func main() {
 		return
 	}
 
-	model := &Model{}
+	if _, err := start(); err != nil {
+		document.Call("write", "<div id=\"board\" class=\"error\">"+err.Error()+"</div>")
+		return
+	}
+
+	if js.WRAPS == "syscall/js" {
+		select {}
+	}
+}
+
+// Creates the app model and its elements in the page document, shows the game from location hash and starts handling events.
+func start() (*Model, error) {
+	settings := NewSettings()
 
 	// is rotation supported?
 	if div := js.Global().Get("document").Call("createElement", "div"); !js.IsUndefined(div) {
 		if !js.IsUndefined(div.Get("style").Get("transform")) {
-			model.rotationSupported = true
+			settings.RotationSupported = true
 		}
 		div.Call("remove")
 	}
+	settings.AutoRotate.Off = !settings.RotationSupported
 	// is execCommand supported?
 	if exec := js.Global().Get("document").Get("execCommand"); !js.IsUndefined(exec) {
-		model.execSupported = true
+		settings.ExecSupported = true
 	}
 
+	// Settings (language, pieces, ...) have to be applied before the model creates its elements.
+	settings.Apply()
+
+	model := &Model{Settings: settings, sounds: &soundPlayer{}}
+
 	app, err := shf.Create(model)
 	if err != nil {
-		document.Call("write", "<div id=\"board\" class=\"error\">"+err.Error()+"</div>")
-		return
+		return nil, err
 	}
+	app.SetErrorHandler(func(err *shf.Error) {
+		model.handleError(app.Tools(), err)
+	})
 
-	body := document.Get("body")
+	body := js.Global().Get("document").Get("body")
 	body.Call("appendChild", model.Html.Header.Element.Object())
 	body.Call("appendChild", model.Html.Board.Element.Object())
 	body.Call("appendChild", model.Html.ThrownOuts.Element.Object())
@@ -64,30 +84,35 @@ func main() {
 	body.Call("appendChild", model.Html.Footer.Element.Object())
 	body.Call("appendChild", model.Html.Export.Element.Object())
 	body.Call("appendChild", model.Html.Notification.Element.Object())
+	body.Call("appendChild", model.Html.DragPiece.Element.Object())
+	body.Call("appendChild", model.Html.Announcer.Element.Object())
+	body.Call("appendChild", model.Html.Settings.Element.Object())
 
 	//TODO jezek - Make it so this is not needed and the board is rotated upon initialization.
-	model.RotateBoardForPlayer()
+	model.AutoRotateBoard()
+
+	// Tell the player about automatically played conditional reply.
+	model.showConditionalReplyNotification(app.Tools())
 
 	// If game ended, notify the player.
-	if st := model.ChessGame.game.Status(); st != game.InProgress {
+	if st := model.ChessGame.Game.Status(); st != game.InProgress {
 		model.showEndGameNotification(app.Tools())
 		//TODO jezek - Update only elements needed for showing notification. Or better, make it so the notification is shown upon init ant this is not needed.
 	}
 
 	model.Html.Cover.GameStatus.rebuild(app.Tools())
 
+	// Replay sound of the received game's last move. Browsers may block it until user interacts with the page.
+	model.playLastMoveSound()
+
 	//TODO jezek - Update only status move body.
 	if err := app.Update(); err != nil {
-		if model.Html.Board.Element != nil {
-			model.Html.Board.Element.Set("innerHTML", err.Error())
-			model.Html.Board.Element.Get("classList").Call("add", "error")
-		} else {
-			document.Call("write", "<div id=\"board\" class=\"error\">"+err.Error()+"</div>")
-		}
-		return
+		// Show the error and keep the app running.
+		app.ReportError("", nil, err)
 	}
 
-	if js.WRAPS == "syscall/js" {
-		select {}
-	}
+	// Show how the received game's last move was played.
+	model.animateLastMove(app.Tools())
+
+	return model, nil
 }
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"URLchess/shf"
+	"URLchess/shf/js"
+	"errors"
+	"strings"
+
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position/move"
+	"github.com/andrewbackes/chess/position/square"
+)
+
+var sanPieceLetterToName = map[byte]string{
+	'K': "king",
+	'Q': "queen",
+	'R': "rook",
+	'B': "bishop",
+	'N': "knight",
+}
+
+// Returns spoken form of a SAN move, e.g. "Nxe4+" -> "knight takes e4, check".
+func describeSAN(san string) string {
+	res, suffix := "", ""
+	if strings.HasSuffix(san, "#") {
+		suffix = tr(", checkmate")
+	} else if strings.HasSuffix(san, "+") {
+		suffix = tr(", check")
+	}
+	san = strings.TrimRight(san, "+#")
+
+	switch san {
+	case "O-O":
+		return tr("castles kingside") + suffix
+	case "O-O-O":
+		return tr("castles queenside") + suffix
+	case "":
+		return ""
+	}
+
+	promotion := ""
+	if i := strings.Index(san, "="); i != -1 {
+		if name, ok := sanPieceLetterToName[san[len(san)-1]]; ok {
+			promotion = tr(" promotes to %s", tr(name))
+		}
+		san = san[:i]
+	}
+
+	if name, ok := sanPieceLetterToName[san[0]]; ok {
+		res = tr(name)
+		san = san[1:]
+	} else {
+		res = tr("pawn")
+	}
+
+	if len(san) < 2 {
+		return res + " " + san + promotion + suffix
+	}
+	destination := san[len(san)-2:]
+	if i := strings.Index(san, "x"); i != -1 {
+		// Disambiguation (if any) is before the capture mark.
+		if from := san[:i]; from != "" {
+			res += " " + from
+		}
+		res += " " + tr("takes") + " " + destination
+	} else {
+		if from := san[:len(san)-2]; from != "" {
+			res += " " + from
+		}
+		res += " " + destination
+	}
+	return res + promotion + suffix
+}
+
+// Returns spoken form of the piece, e.g. "white knight".
+func describePiece(p piece.Piece) string {
+	if p.Type == piece.None {
+		return tr("empty")
+	}
+	return tr(strings.ToLower(p.Color.String())+" %s", tr(pieceTypesToName[p.Type]))
+}
+
+// Returns screen reader label for board square, e.g. "e4, white knight, last move".
+func (s *GridSquare) ariaLabel() string {
+	label := s.Id.String() + ", " + describePiece(s.Piece)
+	for _, color := range piece.Colors {
+		markers := s.Markers.ByColor[color]
+		if markers.LastMove.From || markers.LastMove.To {
+			label += tr(", last move")
+		}
+		if markers.NextMove.From {
+			label += tr(", selected")
+		}
+		if markers.NextMove.PossibleTo {
+			label += tr(", possible move")
+		}
+	}
+	if s.Markers.Check {
+		if s.Markers.Mate {
+			label += tr(", checkmate")
+		} else {
+			label += tr(", check")
+		}
+	}
+	return label
+}
+
+// Returns screen reader announcement describing the last move & game state, e.g. "Black plays knight takes e4, check. White to move".
+func (ch *ChessGameModel) announcement() string {
+	position := ch.Game.Positions[ch.currMoveNo]
+	text := tr("New game")
+	if position.LastMove != move.Null && ch.currMoveNo > 0 && ch.currMoveNo <= len(ch.pgn.Moves) {
+		text = tr(complementColor(position.ActiveColor).String()+" plays %s", describeSAN(ch.pgn.Moves[ch.currMoveNo-1]))
+	}
+	if st := ch.Game.Status(); st != game.InProgress {
+		return text + ". " + tr(st.String())
+	}
+	return text + ". " + tr(position.ActiveColor.String()+" to move")
+}
+
+// Live region for screen readers, announcing moves and game status.
+type ModelAnnouncer struct {
+	shf.Element
+	Text string
+
+	shownText string
+}
+
+func (a *ModelAnnouncer) Init(tools *shf.Tools) error {
+	if a.Element == nil {
+		a.Element = tools.CreateElement("div")
+		a.Set("id", "announcer")
+		a.Get("classList").Call("add", "visually-hidden")
+		a.Call("setAttribute", "role", "status")
+		a.Call("setAttribute", "aria-live", "polite")
+		a.Call("setAttribute", "aria-atomic", "true")
+	}
+	return nil
+}
+func (a *ModelAnnouncer) Update(tools *shf.Tools) error {
+	if a == nil {
+		return errors.New("ModelAnnouncer is nil")
+	}
+	if a.Text != a.shownText {
+		a.Set("textContent", a.Text)
+		a.shownText = a.Text
+	}
+	return nil
+}
+
+// Moves focus into a dialog when it is shown and returns it back to previously focused element, when the dialog hides.
+type dialogFocus struct {
+	shown    bool
+	returnTo js.Object
+}
+
+func (df *dialogFocus) update(shown bool, focus shf.Element) {
+	if shown == df.shown {
+		return
+	}
+	df.shown = shown
+
+	if shown {
+		df.returnTo = js.Global().Get("document").Get("activeElement")
+		if focus != nil {
+			focus.Call("focus")
+		}
+		return
+	}
+
+	if returnTo := df.returnTo; !js.IsUndefined(returnTo) && !js.IsNull(returnTo) {
+		returnTo.Call("focus")
+	}
+	df.returnTo = js.Undefined()
+}
+
+// Makes a non-button element focusable & clickable by keyboard (Enter or Space key).
+func makeKeyboardClickable(tools *shf.Tools, elm shf.Element, label string) error {
+	elm.Call("setAttribute", "role", "button")
+	elm.Call("setAttribute", "tabindex", "0")
+	if label != "" {
+		elm.Call("setAttribute", "aria-label", label)
+	}
+	return tools.KeyDown(elm, func(e shf.KeyboardEvent) error {
+		if key := e.Key(); key != "Enter" && key != " " && key != "Spacebar" {
+			return nil
+		}
+		e.Call("preventDefault")
+		e.Call("stopPropagation")
+		elm.Call("click")
+		return nil
+	})
+}
+
+// Moves keyboard focus between board squares using arrow keys, and clicks focused square with Enter or Space.
+func (m *Model) gridKeyDown(tools *shf.Tools, e shf.KeyboardEvent) error {
+	if e.AltKey() || e.CtrlKey() || e.MetaKey() {
+		return nil
+	}
+	m.stopReplay()
+	grid := m.Html.Board.Grid
+	focused := grid.Focused
+
+	// Direction in board file & rank, as seen by the player.
+	df, dr := 0, 0
+	switch e.Key() {
+	case "ArrowLeft", "Left":
+		df = -1
+	case "ArrowRight", "Right":
+		df = 1
+	case "ArrowUp", "Up":
+		dr = 1
+	case "ArrowDown", "Down":
+		dr = -1
+	case "Enter", " ", "Spacebar":
+		e.Call("preventDefault")
+		e.Call("stopPropagation")
+		grid.Squares[int(focused)].Call("click")
+		return nil
+	default:
+		return nil
+	}
+	e.Call("preventDefault")
+	e.Call("stopPropagation")
+
+	if m.Html.Rotated180deg {
+		df, dr = -df, -dr
+	}
+	// Square numbering goes from H1 (0) to A8 (63), so files decrease to the right.
+	file, rank := 7-int(focused)%8, int(focused)/8
+	file, rank = file+df, rank+dr
+	if file < 0 || file > 7 || rank < 0 || rank > 7 {
+		return nil
+	}
+
+	return grid.Focus(tools, square.Square(rank*8+7-file))
+}
+
+// Turns high contrast mode on or off. In high contrast mode board markers are distinguished by shapes & patterns, not by colour alone.
+func setHighContrast(on bool) {
+	js.Global().Get("document").Get("body").Get("classList").Call("toggle", "high-contrast", on)
+}
+
+// Returns true if user asked the system for more contrast.
+func prefersHighContrast() bool {
+	if js.IsUndefined(js.Global().Get("matchMedia")) {
+		return false
+	}
+	return js.Global().Call("matchMedia", "(prefers-contrast: more)").Get("matches").Bool()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"URLchess/shf"
+	"URLchess/urlchess"
+	"strconv"
+	"time"
+
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position/move"
+	"github.com/andrewbackes/chess/position/square"
+)
+
+const (
+	moveAnimationDuration    = 300 * time.Millisecond
+	captureAnimationDuration = 400 * time.Millisecond
+)
+
+// Animates the last move on board: moving piece (and rook, when castling) slides from its source square, captured piece fades out.
+// Board has to be updated to the position after the move already.
+func (m *Model) animateLastMove(tools *shf.Tools) {
+	ch := m.ChessGame
+	n := ch.currMoveNo
+	if n <= 0 || n >= len(ch.Game.Positions) {
+		return
+	}
+	prev, mv := ch.Game.Positions[n-1], ch.Game.Positions[n].LastMove
+	if mv == move.Null {
+		return
+	}
+	squares := m.Html.Board.Grid.Squares
+
+	if captured := urlchess.CapturedPiece(prev, mv); captured.Type != piece.None {
+		capturedSquare := mv.To()
+		if prev.OnSquare(capturedSquare).Type == piece.None {
+			// En passant, captured pawn is beside the source square.
+			capturedSquare = square.Square(int(mv.To())%8 + int(mv.From())/8*8)
+		}
+		fadeOutPiece(tools, squares[int(capturedSquare)], captured)
+	}
+
+	slidePiece(tools, squares[int(mv.From())], squares[int(mv.To())])
+	if isCastling(prev, mv) {
+		rookFrom, rookTo := castlingRookSquares(mv)
+		slidePiece(tools, squares[int(rookFrom)], squares[int(rookTo)])
+	}
+}
+
+// Returns rook's source & destination square for castling king move.
+func castlingRookSquares(kingMove move.Move) (square.Square, square.Square) {
+	// Square 0 is H1, so files are counted from the H file.
+	rank := int(kingMove.From()) / 8 * 8
+	if kingMove.To() < kingMove.From() {
+		// King side.
+		return square.Square(rank), square.Square(rank + 2)
+	}
+	// Queen side.
+	return square.Square(rank + 7), square.Square(rank + 4)
+}
+
+// Slides the piece shown on the to square from the from square.
+func slidePiece(tools *shf.Tools, from, to *GridSquare) {
+	dx := from.Get("offsetLeft").Float() - to.Get("offsetLeft").Float()
+	dy := from.Get("offsetTop").Float() - to.Get("offsetTop").Float()
+	if dx == 0 && dy == 0 {
+		return
+	}
+
+	style := to.piece.Get("style")
+	// Moving piece has to be above other squares & must not be slowed down by css transitions.
+	to.Get("style").Set("zIndex", "1")
+	style.Set("transition", "none")
+	tools.Animate(to.piece, moveAnimationDuration, shf.EaseInOut, func(progress float64) {
+		style.Set("left", strconv.FormatFloat(dx*(1-progress), 'f', 2, 64)+"px")
+		style.Set("top", strconv.FormatFloat(dy*(1-progress), 'f', 2, 64)+"px")
+	}, func() {
+		style.Set("left", "")
+		style.Set("top", "")
+		style.Set("transition", "")
+		to.Get("style").Set("zIndex", "")
+	})
+}
+
+// Shows piece p on square s, fading out until it disappears.
+// The fading piece is destroyed when the animation ends or is cancelled, and the animation is cancelled when the square is destroyed with the piece.
+func fadeOutPiece(tools *shf.Tools, s *GridSquare, p piece.Piece) {
+	ghost := tools.CreateElement("span")
+	renderPiece(ghost.Object(), p)
+	ghost.Get("classList").Call("add", "ghost")
+	ghost.Call("setAttribute", "aria-hidden", "true")
+	ghost.Get("style").Set("transition", "none")
+	s.marker.Call("insertBefore", ghost.Object(), s.piece.Object())
+
+	destroy := func() {
+		tools.Destroy(ghost)
+	}
+	tools.Animate(ghost, captureAnimationDuration, shf.EaseOut, func(progress float64) {
+		ghost.Get("style").Set("opacity", strconv.FormatFloat(1-progress, 'f', 3, 64))
+	}, destroy).OnCancel(destroy)
+}
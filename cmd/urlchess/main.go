@@ -0,0 +1,283 @@
+// Command urlchess converts between URLchess game links, PGN and FEN.
+//
+// Usage:
+//
+//	urlchess decode [-format pgn|fen|json] [-at half-move] <url|hash>
+//	urlchess encode [-base url] <pgn file|->
+//	urlchess validate [url|hash]...
+//	urlchess board [-at half-move] [-flip] <url|hash>
+package main
+
+import (
+	"URLchess/urlchess"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/pgn"
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position/move"
+	"github.com/andrewbackes/chess/position/square"
+)
+
+const defaultBaseURL = "https://jezek.github.io/URLchess/"
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  urlchess decode [-format pgn|fen|json] [-at half-move] <url|hash>
+	Prints game from URLchess link as PGN, FEN of the last (or given) position, or JSON.
+  urlchess encode [-base url] <pgn file|->
+	Prints URLchess link for every game in PGN file (or standard input).
+  urlchess validate [url|hash]...
+	Checks URLchess links (or links on standard input lines) and prints their state.
+  urlchess board [-at half-move] [-flip] <url|hash>
+	Prints board of the last (or given) position in URLchess link.`)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	commands := map[string]func(args []string) error{
+		"decode":   decode,
+		"encode":   encode,
+		"validate": validate,
+		"board":    board,
+	}
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := command(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "urlchess "+os.Args[1]+": "+err.Error())
+		os.Exit(1)
+	}
+}
+
+// Decodes game from link and cuts it after half-move at, if at is not negative.
+func linkGame(link string, at int) (*game.Game, error) {
+	g, err := urlchess.DecodeGame(urlchess.LinkHash(link))
+	if err != nil {
+		return nil, err
+	}
+	if at >= 0 {
+		if at >= len(g.Positions) {
+			return nil, fmt.Errorf("half-move %d is out of range, game has %d half-moves", at, len(g.Positions)-1)
+		}
+		g.Positions = g.Positions[:at+1]
+	}
+	return g, nil
+}
+
+// Parses flags of command, which has exactly one positional argument, and returns the argument.
+func parseOneArg(flags *flag.FlagSet, args []string) (string, error) {
+	if err := flags.Parse(args); err != nil {
+		return "", err
+	}
+	if flags.NArg() != 1 {
+		return "", errors.New("expecting exactly one argument")
+	}
+	return flags.Arg(0), nil
+}
+
+type decodedGame struct {
+	Hash      string   `json:"hash"`
+	HalfMoves int      `json:"halfMoves"`
+	Moves     []string `json:"moves"`
+	SAN       []string `json:"san"`
+	FEN       string   `json:"fen"`
+	Status    string   `json:"status"`
+	Result    string   `json:"result"`
+}
+
+func decode(args []string) error {
+	flags := flag.NewFlagSet("decode", flag.ContinueOnError)
+	format := flags.String("format", "pgn", "output format: pgn, fen or json")
+	at := flags.Int("at", -1, "show game only up to this half-move")
+	link, err := parseOneArg(flags, args)
+	if err != nil {
+		return err
+	}
+
+	g, err := linkGame(link, *at)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "pgn":
+		g.Tags["Site"] = link
+		fmt.Print(pgn.EncodeSAN(g).String())
+	case "fen":
+		fmt.Println(urlchess.FEN(g.Position()))
+	case "json":
+		hash, err := urlchess.EncodeGame(g)
+		if err != nil {
+			return err
+		}
+		d := decodedGame{
+			Hash:      hash,
+			HalfMoves: len(g.Positions) - 1,
+			Moves:     []string{},
+			SAN:       []string{},
+			FEN:       urlchess.FEN(g.Position()),
+			Status:    g.Status().String(),
+			Result:    g.Result(),
+		}
+		for i := 1; i < len(g.Positions); i++ {
+			m := g.Positions[i].LastMove
+			d.Moves = append(d.Moves, m.String())
+			d.SAN = append(d.SAN, g.Positions[i-1].SAN(m))
+		}
+		out := json.NewEncoder(os.Stdout)
+		out.SetIndent("", "  ")
+		return out.Encode(d)
+	default:
+		return errors.New("unknown format: " + *format)
+	}
+	return nil
+}
+
+func encode(args []string) error {
+	flags := flag.NewFlagSet("encode", flag.ContinueOnError)
+	base := flags.String("base", defaultBaseURL, "URLchess page URL the links point to")
+	file, err := parseOneArg(flags, args)
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	games, err := pgn.Read(in)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for i, p := range games {
+		g, err := pgnGame(p)
+		if err == nil {
+			var hash string
+			if hash, err = urlchess.EncodeGame(g); err == nil {
+				fmt.Println(strings.TrimSuffix(*base, "#") + "#" + hash)
+				continue
+			}
+		}
+		failed++
+		fmt.Fprintf(os.Stderr, "game %d: %s\n", i+1, err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d games could not be encoded", failed, len(games))
+	}
+	return nil
+}
+
+// Plays moves of PGN game from the initial position.
+func pgnGame(p *pgn.PGN) (*game.Game, error) {
+	if _, ok := p.Tags["FEN"]; ok {
+		return nil, errors.New("games from a set up position can not be encoded")
+	}
+	return urlchess.PlaySAN(p.Moves...)
+}
+
+func validate(args []string) error {
+	links := args
+	if len(links) == 0 {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				links = append(links, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	invalid := 0
+	for _, link := range links {
+		g, err := linkGame(link, -1)
+		if err != nil {
+			invalid++
+			fmt.Printf("invalid\t%s\t%s\n", link, err)
+			continue
+		}
+		fmt.Printf("ok\t%s\t%d half-moves, %s\n", link, len(g.Positions)-1, g.Status())
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d links are invalid", invalid, len(links))
+	}
+	return nil
+}
+
+func board(args []string) error {
+	flags := flag.NewFlagSet("board", flag.ContinueOnError)
+	at := flags.Int("at", -1, "show position after this half-move")
+	flip := flags.Bool("flip", false, "show board from black's side")
+	link, err := parseOneArg(flags, args)
+	if err != nil {
+		return err
+	}
+
+	g, err := linkGame(link, *at)
+	if err != nil {
+		return err
+	}
+	fmt.Print(asciiBoard(g, *flip))
+	return nil
+}
+
+// Returns position of game as text, white pieces are upper case letters, black lower case, empty squares are dots.
+func asciiBoard(g *game.Game, flipped bool) string {
+	p := g.Position()
+	files := "abcdefgh"
+	if flipped {
+		files = "hgfedcba"
+	}
+
+	var b strings.Builder
+	b.WriteString("  +-----------------+\n")
+	for row := 0; row < 8; row++ {
+		rank := 7 - row
+		if flipped {
+			rank = row
+		}
+		fmt.Fprintf(&b, "%d |", rank+1)
+		for col := 0; col < 8; col++ {
+			file := strings.IndexByte("abcdefgh", files[col])
+			// Square 0 is H1.
+			pce := p.OnSquare(square.Square(rank*8 + 7 - file))
+			if pce.Type == piece.None {
+				b.WriteString(" .")
+				continue
+			}
+			b.WriteString(" " + pce.String())
+		}
+		b.WriteString(" |\n")
+	}
+	b.WriteString("  +-----------------+\n")
+	b.WriteString("    " + strings.Join(strings.Split(files, ""), " ") + "\n")
+
+	fmt.Fprintf(&b, "half-move %d, %s to move", len(g.Positions)-1, p.ActiveColor)
+	if p.LastMove != move.Null {
+		fmt.Fprintf(&b, ", last move %s", g.Positions[len(g.Positions)-2].SAN(p.LastMove))
+	}
+	fmt.Fprintf(&b, ", %s\n", g.Status())
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// 1. e4 e5
+const e4e5 = "Lbzj"
+
+func TestLinkGame(t *testing.T) {
+	tests := []struct {
+		link      string
+		halfMoves int
+	}{
+		{"", 0},
+		{e4e5, 2},
+		{"#" + e4e5, 2},
+		{"  https://jezek.github.io/URLchess/#" + e4e5 + "\n", 2},
+		{"https://jezek.github.io/URLchess/#/game/" + e4e5, 2},
+		{"#/game/" + e4e5 + "~-tCm", 2},
+		{"#" + e4e5 + "~-tCm~.3", 2},
+	}
+	for _, test := range tests {
+		g, err := linkGame(test.link, -1)
+		if err != nil {
+			t.Errorf("linkGame(%q): %s", test.link, err)
+			continue
+		}
+		if got := len(g.Positions) - 1; got != test.halfMoves {
+			t.Errorf("linkGame(%q) has %d half-moves, want %d", test.link, got, test.halfMoves)
+		}
+	}
+
+	for _, link := range []string{"#L", "#LbLb", "#/settings", "#Lb!j"} {
+		if _, err := linkGame(link, -1); err == nil {
+			t.Errorf("linkGame(%q) is valid", link)
+		}
+	}
+}
+
+func TestLinkGameAt(t *testing.T) {
+	for at := -1; at <= 2; at++ {
+		g, err := linkGame(e4e5, at)
+		if err != nil {
+			t.Errorf("at %d: %s", at, err)
+			continue
+		}
+		want := at
+		if at < 0 {
+			want = 2
+		}
+		if got := len(g.Positions) - 1; got != want {
+			t.Errorf("at %d: game has %d half-moves, want %d", at, got, want)
+		}
+	}
+
+	if _, err := linkGame(e4e5, 3); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("at 3 of 2 half-moves: error %v, want out of range", err)
+	}
+	if err := decode([]string{"-at", "3", "-format", "fen", e4e5}); err == nil {
+		t.Error("decode -at 3 of 2 half-moves succeeded")
+	}
+	if err := decode([]string{"-format", "xml", e4e5}); err == nil {
+		t.Error("decode -format xml succeeded")
+	}
+	if err := board([]string{"-at", "-2", e4e5}); err != nil {
+		t.Errorf("board -at -2 (whole game): %s", err)
+	}
+}
+
+func TestAsciiBoard(t *testing.T) {
+	// 1. h4, the pawn is on the right side of the board from white's side and on the left from black's.
+	g, err := linkGame("#IY", -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		flip  bool
+		lines map[int]string
+	}{
+		{false, map[int]string{
+			1:  "8 | r n b q k b n r |",
+			5:  "4 | . . . . . . . P |",
+			7:  "2 | P P P P P P P . |",
+			8:  "1 | R N B Q K B N R |",
+			10: "    a b c d e f g h",
+		}},
+		{true, map[int]string{
+			1:  "1 | R N B K Q B N R |",
+			2:  "2 | . P P P P P P P |",
+			4:  "4 | P . . . . . . . |",
+			8:  "8 | r n b k q b n r |",
+			10: "    h g f e d c b a",
+		}},
+	}
+	for _, test := range tests {
+		lines := strings.Split(asciiBoard(g, test.flip), "\n")
+		for i, want := range test.lines {
+			if lines[i] != want {
+				t.Errorf("flip %v, line %d is %q, want %q", test.flip, i, lines[i], want)
+			}
+		}
+		if last := lines[11]; last != "half-move 1, Black to move, last move h4, In progress" {
+			t.Errorf("flip %v, summary is %q", test.flip, last)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"URLchess/shf"
+	"URLchess/urlchess"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/position"
+	"github.com/andrewbackes/chess/position/move"
+)
+
+// Conditional replies are appended to game moves in the URL hash, each line separated by urlchess.ConditionSeparator.
+// A line is a sequence of moves encoded the same way as game moves: opponent move, prepared reply, opponent move, prepared reply, ...
+// The segment starting with autoReplyPrefix holds the half-move number of the last automatically played reply,
+// so the player who prepared the reply is told about it, when the link comes back.
+//
+//	#<game moves>~<line>~<line>~.<half-move number>
+const autoReplyPrefix = "."
+
+// Conditional reply line. Moves on even indexes are expected opponent moves, moves on odd indexes are the replies.
+type conditionLine []move.Move
+
+func encodeConditions(lines []conditionLine, autoReply int) (string, error) {
+	res := ""
+	for _, line := range lines {
+		res += urlchess.ConditionSeparator
+		for _, m := range line {
+			em, err := urlchess.EncodeMove(m)
+			if err != nil {
+				return "", err
+			}
+			res += em
+		}
+	}
+	if autoReply > 0 {
+		res += urlchess.ConditionSeparator + autoReplyPrefix + strconv.Itoa(autoReply)
+	}
+	return res, nil
+}
+
+// Splits hash to game moves & decoded conditional reply lines and auto reply half-move number.
+func decodeConditions(hash string) (string, []conditionLine, int, error) {
+	segments := strings.Split(hash, urlchess.ConditionSeparator)
+	lines := []conditionLine{}
+	autoReply := 0
+	for _, segment := range segments[1:] {
+		if strings.HasPrefix(segment, autoReplyPrefix) {
+			n, err := strconv.Atoi(strings.TrimPrefix(segment, autoReplyPrefix))
+			if err != nil || n <= 0 {
+				return "", nil, 0, errors.New("invalid conditional reply number: " + segment)
+			}
+			autoReply = n
+			continue
+		}
+		moves, err := urlchess.DecodeMoves(segment)
+		if err != nil {
+			return "", nil, 0, errors.New("decoding conditional reply error: " + err.Error())
+		}
+		if len(moves) == 0 || len(moves)%2 != 0 {
+			return "", nil, 0, errors.New("conditional reply line has to contain pairs of moves: " + segment)
+		}
+		lines = append(lines, conditionLine(moves))
+	}
+	return segments[0], lines, autoReply, nil
+}
+
+// Returns location hash of the game, which contains game moves & conditional replies.
+func (ch *ChessGameModel) Hash() string {
+	if ch.heldConditions != "" {
+		return ch.Moves + ch.heldConditions
+	}
+	conditions, err := encodeConditions(ch.conditions, ch.autoReply)
+	if err != nil {
+		// should not happen, conditions were validated
+		return ch.Moves
+	}
+	return ch.Moves + conditions
+}
+
+// Returns hash for browsing to game moves. Earlier moves of the received game keep its conditional replies,
+// so they are not lost by going through the game and back to its last move.
+func (ch *ChessGameModel) browseHash(moves string) string {
+	initialMoves, _, found := strings.Cut(ch.initialHash, urlchess.ConditionSeparator)
+	if !found || !strings.HasPrefix(initialMoves, moves) {
+		return moves
+	}
+	return moves + strings.TrimPrefix(ch.initialHash, initialMoves)
+}
+
+// Returns browse hash of the current game after half-move n, see browseHash.
+func (ch *ChessGameModel) browseHashForHalfMove(n int) (string, error) {
+	hash, err := ch.HashForHalfMove(n)
+	if err != nil {
+		return "", err
+	}
+	return ch.browseHash(hash), nil
+}
+
+// Returns browse hash of the received game after half-move n, see browseHash.
+func (ch *ChessGameModel) browseHashForInitialHalfMove(n int) (string, error) {
+	hash, err := ch.HashForInitialHalfMove(n)
+	if err != nil {
+		return "", err
+	}
+	return ch.browseHash(hash), nil
+}
+
+// Plays prepared reply, if the last move matches some conditional reply line.
+// Lines, which do not match, are discarded. Matched lines are shortened to the moves after the reply,
+// so they are kept in the hash and can trigger again after the next opponent move.
+// Returns whether a reply was played.
+func (ch *ChessGameModel) playConditionalReplies() (bool, error) {
+	if len(ch.conditions) == 0 {
+		return false, nil
+	}
+	last := ch.Game.Positions[len(ch.Game.Positions)-1]
+	if ch.Game.Status() != game.InProgress || ch.currMoveNo != len(ch.Game.Positions)-1 {
+		ch.conditions = nil
+		return false, nil
+	}
+
+	reply := move.Null
+	remaining := []conditionLine{}
+	for _, line := range ch.conditions {
+		if line[0] != last.LastMove {
+			continue
+		}
+		if reply == move.Null {
+			reply = line[1]
+		}
+		if line[1] == reply && len(line) > 2 {
+			remaining = append(remaining, line[2:])
+		}
+	}
+	ch.conditions = remaining
+	if reply == move.Null || !isLegalMove(last, reply) {
+		ch.conditions = nil
+		return false, nil
+	}
+
+	ch.nextMove = reply
+	if err := ch.makeNextMove(); err != nil {
+		return false, err
+	}
+	ch.autoReply = ch.currMoveNo
+	if ch.Game.Status() != game.InProgress {
+		ch.conditions = nil
+	}
+	return true, nil
+}
+
+// Sets conditional replies for the opponent's next move. Replaces previous conditions.
+func (ch *ChessGameModel) SetConditions(lines []conditionLine) error {
+	if err := ch.Validate(); err != nil {
+		return err
+	}
+	if ch.Game.Status() != game.InProgress || ch.currMoveNo != len(ch.Game.Positions)-1 {
+		return errors.New(tr("Conditional replies can be added only to the last move of a running game"))
+	}
+	ch.conditions = lines
+	return nil
+}
+
+// Parses conditional reply lines typed by player (one line per condition, moves separated by spaces) in position p.
+// Move numbers (e.g. "12." or "12...") are ignored.
+func parseConditionLines(p *position.Position, text string) ([]conditionLine, error) {
+	lines := []conditionLine{}
+	for _, row := range strings.Split(text, "\n") {
+		line := conditionLine{}
+		pos := p
+		for _, token := range strings.Fields(row) {
+			if strings.HasSuffix(token, ".") && strings.Trim(token, "0123456789.") == "" {
+				continue
+			}
+			m, err := parseTypedMove(newLegalMoves(pos), token)
+			if err != nil {
+				return nil, err
+			}
+			if !isLegalMove(pos, m) {
+				return nil, errors.New(tr("promotion piece is missing in \"%s\"", token))
+			}
+			line = append(line, m)
+			pos = pos.MakeMove(m)
+		}
+		if len(line) == 0 {
+			continue
+		}
+		if len(line)%2 != 0 {
+			return nil, errors.New(tr("line \"%s\" has to end with your reply", strings.TrimSpace(row)))
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// Returns message about automatically played conditional reply, if the reply is relevant to the current player.
+// Empty string is returned if there is nothing to tell.
+func (ch *ChessGameModel) autoReplyMessage() string {
+	n := ch.autoReply
+	last := len(ch.Game.Positions) - 1
+	if n <= 1 || n > last || n < last-1 {
+		return ""
+	}
+	opponentMove := localizeSAN(ch.Game.Positions[n-2].SAN(ch.Game.Positions[n-1].LastMove))
+	reply := localizeSAN(ch.Game.Positions[n-1].SAN(ch.Game.Positions[n].LastMove))
+	if n == last {
+		// The player, who triggered the reply, is on the move.
+		return tr("Your opponent prepared reply %s to your move %s, it was played automatically.", reply, opponentMove)
+	}
+	// The player, who prepared the reply, received the link.
+	return tr("Your conditional reply %s to move %s was played automatically.", reply, opponentMove)
+}
+
+// Shows notification about automatically played conditional reply, if there is any.
+func (m *Model) showConditionalReplyNotification(tools *shf.Tools) {
+	if msg := m.ChessGame.autoReplyMessage(); msg != "" {
+		m.Html.Notification.Message(tools, tr("Conditional reply"), msg)
+	}
+}
+
+// Conditional replies editor in the move status. Model sets Save event and visibility.
+type MoveStatusConditions struct {
+	shf.Element
+	Shown bool
+
+	Input shf.Element
+	Save  shf.Element
+	Info  shf.Element
+}
+
+func (this *MoveStatusConditions) Init(tools *shf.Tools) error {
+	if this.Input == nil {
+		this.Input = tools.CreateElement("textarea")
+		this.Input.Set("rows", 2)
+		this.Input.Call("setAttribute", "placeholder", tr("e.g. Nxe5 Nxe5"))
+		this.Input.Call("setAttribute", "aria-label", tr("Conditional replies"))
+	}
+	if this.Save == nil {
+		this.Save = tools.CreateElement("button")
+		this.Save.Set("textContent", tr("attach to link"))
+	}
+	if this.Info == nil {
+		this.Info = tools.CreateElement("div")
+		this.Info.Set("className", "info")
+	}
+
+	if this.Element == nil {
+		this.Element = tools.CreateElement("div")
+		this.Get("classList").Call("add", "conditions")
+
+		this.Call("appendChild", tools.CreateTextNode(tr("Conditional replies: if your opponent plays the first move of a line, your reply is played automatically. One line per condition, your opponent will not see them.")))
+		this.Call("appendChild", this.Input.Object())
+		this.Call("appendChild", this.Save.Object())
+		this.Call("appendChild", this.Info.Object())
+	}
+	return nil
+}
+
+// Clears the editor, so conditions for previous move are not shown.
+func (this *MoveStatusConditions) reset() {
+	this.Input.Set("value", "")
+	this.Info.Set("textContent", "")
+}
+
+func (this *MoveStatusConditions) Update(tools *shf.Tools) error {
+	if this == nil {
+		return errors.New("MoveStatusConditions is nil")
+	}
+
+	if this.Shown {
+		this.Get("classList").Call("remove", "hidden")
+	} else {
+		this.Get("classList").Call("add", "hidden")
+	}
+	return nil
+}
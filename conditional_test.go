@@ -0,0 +1,106 @@
+package main
+
+import (
+	"URLchess/urlchess"
+	"testing"
+
+	"github.com/andrewbackes/chess/game"
+)
+
+// Records navigated hashes instead of changing the location.
+type testNavigator struct {
+	hashes []string
+}
+
+func (n *testNavigator) Navigate(hash string)  { n.hashes = append(n.hashes, hash) }
+func (n *testNavigator) Browse(hash string)    { n.hashes = append(n.hashes, hash) }
+func (n *testNavigator) StartGame(hash string) { n.hashes = append(n.hashes, hash) }
+
+// Returns moves hash and the game of SAN moves played from the initial position.
+func playedGame(t testing.TB, moves ...string) (string, *game.Game) {
+	t.Helper()
+	g, err := urlchess.PlaySAN(moves...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := urlchess.EncodeGame(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash, g
+}
+
+// Returns hash of game moves with conditional reply lines typed by player after the last move.
+func conditionsHash(t *testing.T, lines string, autoReply int, moves ...string) string {
+	t.Helper()
+	hash, g := playedGame(t, moves...)
+	parsed, err := parseConditionLines(g.Position(), lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conditions, err := encodeConditions(parsed, autoReply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash + conditions
+}
+
+func makeMove(t *testing.T, ch *ChessGameModel, san string) {
+	t.Helper()
+	m, err := ch.Game.Position().ParseMove(san)
+	if err != nil {
+		t.Fatalf("move %s: %s", san, err)
+	}
+	ch.nextMove = m
+	if err := ch.MakeNextMove(); err != nil {
+		t.Fatalf("move %s: %s", san, err)
+	}
+}
+
+func TestConditionalRepliesMultipleMoves(t *testing.T) {
+	nav := &testNavigator{}
+	ch, err := NewGame(conditionsHash(t, "e5 Nf3 Nc6 Bb5\nd5 exd5", 0, "e4"), nav)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reply is played and the rest of the matching line waits for the next opponent move.
+	makeMove(t, ch, "e5")
+	if want := conditionsHash(t, "Nc6 Bb5", 3, "e4", "e5", "Nf3"); ch.Hash() != want {
+		t.Errorf("hash after e5 is %q, want %q", ch.Hash(), want)
+	}
+	if ch.ownMove {
+		t.Error("automatically played reply is own move")
+	}
+
+	// The same after the link is opened again.
+	if err := ch.UpdateToHash(nav.hashes[len(nav.hashes)-1]); err != nil {
+		t.Fatal(err)
+	}
+	makeMove(t, ch, "Nc6")
+	if want := conditionsHash(t, "", 5, "e4", "e5", "Nf3", "Nc6", "Bb5"); ch.Hash() != want {
+		t.Errorf("hash after Nc6 is %q, want %q", ch.Hash(), want)
+	}
+	if len(ch.conditions) != 0 {
+		t.Errorf("conditions left after the whole line was played: %v", ch.conditions)
+	}
+}
+
+func TestConditionalRepliesNotMatching(t *testing.T) {
+	nav := &testNavigator{}
+	ch, err := NewGame(conditionsHash(t, "e5 Nf3 Nc6 Bb5", 0, "e4"), nav)
+	if err != nil {
+		t.Fatal(err)
+	}
+	makeMove(t, ch, "e5")
+
+	// Other move discards the conditions and the player answers by themself.
+	// The played reply is still told about to the player, who prepared it.
+	makeMove(t, ch, "d6")
+	if want := conditionsHash(t, "", 3, "e4", "e5", "Nf3", "d6"); ch.Hash() != want {
+		t.Errorf("hash after d6 is %q, want %q", ch.Hash(), want)
+	}
+	if !ch.ownMove {
+		t.Error("move after discarded conditions is not own move")
+	}
+}
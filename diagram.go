@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position"
+	"github.com/andrewbackes/chess/position/move"
+	"github.com/andrewbackes/chess/position/square"
+)
+
+// Diagram colours, the same as the default brown board theme.
+const (
+	diagramLightSquare = "#f0d9b5"
+	diagramDarkSquare  = "#b58863"
+	diagramLastMove    = "rgba(155,199,0,0.41)"
+	diagramCoordinates = "#555"
+)
+
+// Solid piece glyphs, the colour is given by fill. The variation selector prevents emoji rendering.
+var diagramPieceGlyphs = map[piece.Type]string{
+	piece.King:   "♚︎",
+	piece.Queen:  "♛︎",
+	piece.Rook:   "♜︎",
+	piece.Bishop: "♝︎",
+	piece.Knight: "♞︎",
+	piece.Pawn:   "♟︎",
+}
+
+// Returns board square shown in diagram's row & column (both from top-left), for given orientation.
+// If flipped, black pieces are at the bottom.
+func diagramSquare(row, col int, flipped bool) square.Square {
+	file, rank := col, 7-row
+	if flipped {
+		file, rank = 7-col, row
+	}
+	// Square 0 is H1.
+	return square.Square(rank*8 + 7 - file)
+}
+
+// Returns SVG image of position p with coordinates, last move highlight and side to move marker.
+// If flipped, the board is shown from black's side.
+func positionDiagramSVG(p *position.Position, flipped bool) string {
+	const sq, margin = 45, 22
+	size := 8*sq + 2*margin
+	itoa := strconv.Itoa
+
+	var b strings.Builder
+	b.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="` + itoa(size) + `" height="` + itoa(size) + `" viewBox="0 0 ` + itoa(size) + " " + itoa(size) + `">`)
+	b.WriteString(`<rect width="100%" height="100%" fill="#fff"/>`)
+
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			s := diagramSquare(row, col, flipped)
+			x, y := margin+col*sq, margin+row*sq
+			fill := diagramLightSquare
+			if (row+col)%2 == 1 {
+				fill = diagramDarkSquare
+			}
+			rect := `<rect x="` + itoa(x) + `" y="` + itoa(y) + `" width="` + itoa(sq) + `" height="` + itoa(sq) + `" fill="`
+			b.WriteString(rect + fill + `"/>`)
+			if p.LastMove != move.Null && (p.LastMove.From() == s || p.LastMove.To() == s) {
+				b.WriteString(rect + diagramLastMove + `"/>`)
+			}
+
+			pce := p.OnSquare(s)
+			if pce.Type == piece.None {
+				continue
+			}
+			fill, stroke := "#000", "#000"
+			if pce.Color == piece.White {
+				fill = "#fff"
+			}
+			b.WriteString(`<text x="` + itoa(x+sq/2) + `" y="` + itoa(y+sq/2) + `" font-family="DejaVu Sans, Segoe UI Symbol, Arial Unicode MS, sans-serif" font-size="38" text-anchor="middle" dominant-baseline="central" fill="` + fill + `" stroke="` + stroke + `" stroke-width="1">` + diagramPieceGlyphs[pce.Type] + `</text>`)
+		}
+	}
+
+	// Coordinates.
+	for i := 0; i < 8; i++ {
+		files, ranks := "abcdefgh", "87654321"
+		if flipped {
+			files, ranks = "hgfedcba", "12345678"
+		}
+		coordinate := `" font-family="sans-serif" font-size="13" fill="` + diagramCoordinates + `" text-anchor="middle" dominant-baseline="central">`
+		b.WriteString(`<text x="` + itoa(margin+i*sq+sq/2) + `" y="` + itoa(size-margin/2) + coordinate + files[i:i+1] + `</text>`)
+		b.WriteString(`<text x="` + itoa(margin/2) + `" y="` + itoa(margin+i*sq+sq/2) + coordinate + ranks[i:i+1] + `</text>`)
+	}
+
+	// Side to move marker, right of the board on the side of the player on the move.
+	markerY := size - margin - sq/2
+	if (p.ActiveColor == piece.Black) != flipped {
+		markerY = margin + sq/2
+	}
+	markerFill := "#fff"
+	if p.ActiveColor == piece.Black {
+		markerFill = "#000"
+	}
+	b.WriteString(`<circle cx="` + itoa(size-margin/2) + `" cy="` + itoa(markerY) + `" r="6" fill="` + markerFill + `" stroke="#000" stroke-width="1.5"/>`)
+
+	b.WriteString(`</svg>`)
+	return b.String()
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"URLchess/shf"
+	"errors"
+	"strconv"
+
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position/move"
+	"github.com/andrewbackes/chess/position/square"
+)
+
+// Minimal pointer travel distance (in pixels) to start dragging a piece. Shorter movements are treated as clicks.
+const dragStartDistance = 5
+
+// Piece following the pointer, while dragging it from one square to another.
+type ModelDragPiece struct {
+	shf.Element
+	Shown bool
+	Piece piece.Piece
+	From  square.Square
+	Over  square.Square
+	X, Y  float64 // pointer position in viewport pixels
+	Size  float64 // board square size in pixels
+
+	piece shf.Element
+}
+
+func (d *ModelDragPiece) Init(tools *shf.Tools) error {
+	d.From, d.Over = square.NoSquare, square.NoSquare
+
+	if d.piece == nil {
+		d.piece = pieceElement(tools, piece.New(piece.NoColor, piece.None))
+	}
+
+	if d.Element == nil {
+		d.Element = tools.CreateElement("div")
+		d.Set("id", "drag-piece")
+		d.Get("classList").Call("add", "hidden")
+		d.Call("appendChild", d.piece.Object())
+	}
+	return nil
+}
+func (d *ModelDragPiece) Update(tools *shf.Tools) error {
+	if d == nil {
+		return errors.New("ModelDragPiece is nil")
+	}
+
+	if !d.Shown {
+		d.Get("classList").Call("add", "hidden")
+		return nil
+	}
+
+	renderPiece(d.piece.Object(), d.Piece)
+
+	size := strconv.FormatFloat(d.Size, 'f', 2, 64) + "px"
+	d.Get("style").Set("width", size)
+	d.Get("style").Set("height", size)
+	d.Get("style").Set("fontSize", strconv.FormatFloat(d.Size*5/6, 'f', 2, 64)+"px")
+	d.MoveTo(d.X, d.Y)
+
+	d.Get("classList").Call("remove", "hidden")
+	return nil
+}
+
+// MoveTo moves drag piece element centered to x, y viewport coordinates, without full update.
+func (d *ModelDragPiece) MoveTo(x, y float64) {
+	d.X, d.Y = x, y
+	if d.Element == nil {
+		return
+	}
+	d.Get("style").Set("left", strconv.FormatFloat(x, 'f', 2, 64)+"px")
+	d.Get("style").Set("top", strconv.FormatFloat(y, 'f', 2, 64)+"px")
+}
+
+type boardDrag struct {
+	pointerId      int
+	from           square.Square
+	startX, startY float64
+	dragging       bool
+}
+
+// Returns board square under viewport coordinates x, y, or square.NoSquare if there is none.
+func (m *Model) squareAtPoint(x, y float64) square.Square {
+	rect := m.Html.Board.Grid.Call("getBoundingClientRect")
+	left, top := rect.Get("left").Float(), rect.Get("top").Float()
+	width, height := rect.Get("width").Float(), rect.Get("height").Float()
+	if width <= 0 || height <= 0 || x < left || y < top || x >= left+width || y >= top+height {
+		return square.NoSquare
+	}
+
+	col, row := int((x-left)*8/width), int((y-top)*8/height)
+	if m.Html.Rotated180deg {
+		col, row = 7-col, 7-row
+	}
+	// Grid squares are laid out from A8 (top-left) to H1 (bottom-right).
+	return square.Square(63 - (row*8 + col))
+}
+
+func (m *Model) boardPointerDown(tools *shf.Tools, e shf.PointerEvent) error {
+	m.drag = nil
+	// Any interaction with the board stops the replay.
+	m.stopReplay()
+	if !e.IsPrimary() || e.Button() != 0 {
+		return nil
+	}
+	if st := m.ChessGame.Game.Status(); st != game.InProgress {
+		return nil
+	}
+
+	from := m.squareAtPoint(e.ClientX(), e.ClientY())
+	if from == square.NoSquare {
+		return nil
+	}
+	position := m.ChessGame.Game.Positions[m.ChessGame.currMoveNo]
+	if position.OnSquare(from).Color != position.ActiveColor || !m.ChessGame.legalMoves(position).isLegalMoveFrom(from) {
+		return nil
+	}
+
+	// Remember drag candidate. Dragging starts after pointer moves far enough, until then it is a click.
+	m.drag = &boardDrag{
+		pointerId: e.PointerId(),
+		from:      from,
+		startX:    e.ClientX(),
+		startY:    e.ClientY(),
+	}
+	return nil
+}
+
+func (m *Model) boardPointerMove(tools *shf.Tools, e shf.PointerEvent) error {
+	if m.drag == nil || m.drag.pointerId != e.PointerId() {
+		return nil
+	}
+	x, y := e.ClientX(), e.ClientY()
+	dragPiece := m.Html.DragPiece
+
+	if !m.drag.dragging {
+		if dx, dy := x-m.drag.startX, y-m.drag.startY; dx*dx+dy*dy < dragStartDistance*dragStartDistance {
+			return nil
+		}
+
+		// Start dragging. Capture pointer, so the click event is not fired on squares after drop.
+		m.drag.dragging = true
+		m.Html.Board.Grid.Call("setPointerCapture", m.drag.pointerId)
+
+		// Select dragged piece as next move from, so possible moves get marked.
+		m.ChessGame.nextMove = move.Null
+		m.ChessGame.nextMove.Source = m.drag.from
+		m.Html.Cover.MoveStatus.Shown = false
+
+		position := m.ChessGame.Game.Positions[m.ChessGame.currMoveNo]
+		dragPiece.Shown = true
+		dragPiece.Piece = position.OnSquare(m.drag.from)
+		dragPiece.From = m.drag.from
+		dragPiece.Over = m.drag.from
+		dragPiece.X, dragPiece.Y = x, y
+		dragPiece.Size = m.Html.Board.Grid.Call("getBoundingClientRect").Get("width").Float() / 8
+		return tools.MarkDirty(m.Html.game)
+	}
+
+	e.Call("preventDefault")
+	dragPiece.MoveTo(x, y)
+
+	// Highlight square under dragged piece.
+	if over := m.squareAtPoint(x, y); over != dragPiece.Over {
+		squares := m.Html.Board.Grid.Squares
+		if dragPiece.Over != square.NoSquare {
+			squares[int(dragPiece.Over)].Markers.DragOver = false
+			if err := tools.Update(squares[int(dragPiece.Over)]); err != nil {
+				return err
+			}
+		}
+		if over != square.NoSquare {
+			squares[int(over)].Markers.DragOver = true
+			if err := tools.Update(squares[int(over)]); err != nil {
+				return err
+			}
+		}
+		dragPiece.Over = over
+	}
+	return nil
+}
+
+func (m *Model) boardPointerUp(tools *shf.Tools, e shf.PointerEvent) error {
+	if m.drag == nil || m.drag.pointerId != e.PointerId() {
+		return nil
+	}
+	drag := m.drag
+	m.drag = nil
+	if !drag.dragging {
+		// Not dragged, the click event takes care of it.
+		return nil
+	}
+
+	m.Html.Board.Grid.Call("releasePointerCapture", drag.pointerId)
+	m.Html.DragPiece.Shown = false
+	m.Html.DragPiece.From, m.Html.DragPiece.Over = square.NoSquare, square.NoSquare
+
+	to := m.squareAtPoint(e.ClientX(), e.ClientY())
+	if to != square.NoSquare && to != drag.from {
+		position := m.ChessGame.Game.Positions[m.ChessGame.currMoveNo]
+		dropMove := m.ChessGame.nextMove
+		dropMove.Destination = to
+		if nms, err := m.ChessGame.legalMoves(position).nextMoveState(dropMove); err == nil {
+			if nms == NMLegalMove {
+				m.ChessGame.nextMove = dropMove
+				m.Html.Cover.MoveStatus.Shown = true
+				m.dropped = true
+			} else if nms == NMWaitPromote {
+				m.ChessGame.nextMove = dropMove
+				m.Html.Board.PromotionOverlay.Shown = true
+			}
+		}
+		// Dropped to an illegal square, leave the piece selected.
+	}
+	return tools.MarkDirty(m.Html.game)
+}
+
+func (m *Model) boardPointerCancel(tools *shf.Tools, e shf.PointerEvent) error {
+	if m.drag == nil || m.drag.pointerId != e.PointerId() {
+		return nil
+	}
+	dragging := m.drag.dragging
+	m.drag = nil
+	if !dragging {
+		return nil
+	}
+
+	m.Html.DragPiece.Shown = false
+	m.Html.DragPiece.From, m.Html.DragPiece.Over = square.NoSquare, square.NoSquare
+	return tools.MarkDirty(m.Html.game)
+}
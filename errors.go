@@ -0,0 +1,54 @@
+package main
+
+import (
+	"URLchess/shf"
+	"URLchess/shf/js"
+	"time"
+)
+
+// Number of the most recent errors kept for diagnostics.
+const maxRecordedErrors = 50
+
+// Error of an event handler, timer or update, recorded for diagnostics.
+type recordedError struct {
+	Time    time.Time
+	Message string
+	// Stack trace, if the error was a panic.
+	Stack string
+}
+
+// Returns recorded errors, oldest first.
+func (m *Model) Errors() []recordedError {
+	return append([]recordedError{}, m.errors...)
+}
+
+// Error handler of the app. Records the error, logs it to the browser console and shows it to the player in a notification.
+// The app keeps running, so the player can continue the game.
+func (m *Model) handleError(tools *shf.Tools, err *shf.Error) {
+	m.errors = append(m.errors, recordedError{time.Now(), err.Error(), err.Stack})
+	if len(m.errors) > maxRecordedErrors {
+		m.errors = m.errors[len(m.errors)-maxRecordedErrors:]
+	}
+
+	if console := js.Global().Get("console"); !js.IsUndefined(console) {
+		if err.Stack != "" {
+			console.Call("error", "URLchess: "+err.Error()+"\n"+err.Stack)
+		} else {
+			console.Call("error", "URLchess: "+err.Error())
+		}
+	}
+
+	if m.Html == nil || m.Html.Notification == nil || m.Html.Notification.Element == nil {
+		// Nothing to show the error in.
+		return
+	}
+	m.Html.Notification.Message(
+		tools,
+		tr("Something went wrong"),
+		err.Err.Error(),
+	)
+	if uerr := tools.Update(m.Html.Notification); uerr != nil {
+		// Do not report again, the notification would fail again.
+		m.errors = append(m.errors, recordedError{time.Now(), "showing error notification: " + uerr.Error(), ""})
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"URLchess/urlchess"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position"
+	"github.com/andrewbackes/chess/position/move"
+)
+
+// Options of exported PGN.
+type pgnExportOptions struct {
+	// Moves in long algebraic notation (e.g. "Ng1-f3") instead of SAN.
+	LongAlgebraic bool
+	// Wrap movetext lines at 80 columns.
+	Wrap     bool
+	OmitTags bool
+	// Export moves only up to this half-move, negative for the whole game.
+	UpToHalfMove int
+}
+
+// Maximal line length of wrapped movetext, as recommended by PGN export format.
+const pgnWrapColumns = 80
+
+// Returns move m made in position p in long algebraic notation, like "e2-e4", "Ng1xf3", "e7-e8=Q+" or "O-O".
+func longAlgebraic(p *position.Position, m move.Move) string {
+	san := p.SAN(m)
+	suffix := ""
+	if strings.HasSuffix(san, "+") || strings.HasSuffix(san, "#") {
+		suffix = san[len(san)-1:]
+	}
+
+	if isCastling(p, m) {
+		// Square 0 is H1, so king moves to lower square when castling short.
+		if m.To() < m.From() {
+			return "O-O" + suffix
+		}
+		return "O-O-O" + suffix
+	}
+
+	pce := p.OnSquare(m.From())
+	s := ""
+	if pce.Type != piece.Pawn {
+		s = strings.ToUpper(pce.Type.String())
+	}
+	s += m.From().String()
+	if captured := urlchess.CapturedPiece(p, m); captured.Type != piece.None {
+		s += "x"
+	} else {
+		s += "-"
+	}
+	s += m.To().String()
+	if m.Promote != piece.None {
+		s += "=" + strings.ToUpper(m.Promote.String())
+	}
+	return s + suffix
+}
+
+// Returns game g in PGN with tags. Tags not in the Seven Tag Roster are written in tagOrder
+// and the rest of them sorted by name, so the output does not change between updates.
+// If the moves are cut before the end of the game, the result is unknown.
+func exportPGN(g *game.Game, tags map[string]string, tagOrder []string, options pgnExportOptions) string {
+	last := len(g.Positions) - 1
+	result := tags["Result"]
+	if options.UpToHalfMove >= 0 && options.UpToHalfMove < last {
+		last = options.UpToHalfMove
+		result = "*"
+	}
+	if result == "" {
+		result = "*"
+	}
+
+	var b strings.Builder
+	if !options.OmitTags {
+		written := map[string]bool{}
+		writeTag := func(name string) {
+			value, ok := tags[name]
+			if !ok || written[name] {
+				return
+			}
+			written[name] = true
+			switch {
+			case name == "Result":
+				value = result
+			case name == "Termination" && result == "*":
+				value = "unterminated"
+			}
+			b.WriteString("[" + name + " \"" + value + "\"]\n")
+		}
+
+		for _, name := range []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"} {
+			writeTag(name)
+		}
+		for _, name := range tagOrder {
+			writeTag(name)
+		}
+		rest := []string{}
+		for name := range tags {
+			if !written[name] {
+				rest = append(rest, name)
+			}
+		}
+		sort.Strings(rest)
+		for _, name := range rest {
+			writeTag(name)
+		}
+		b.WriteString("\n")
+	}
+
+	// Move number is kept on the same line with its move.
+	tokens := []string{}
+	for i := 1; i <= last; i++ {
+		p, m := g.Positions[i-1], g.Positions[i].LastMove
+		if m == move.Null {
+			continue
+		}
+		text := p.SAN(m)
+		if options.LongAlgebraic {
+			text = longAlgebraic(p, m)
+		}
+		if p.ActiveColor == piece.White {
+			text = strconv.Itoa(p.MoveNumber) + ". " + text
+		} else if len(tokens) == 0 {
+			text = strconv.Itoa(p.MoveNumber) + "... " + text
+		}
+		tokens = append(tokens, text)
+	}
+	tokens = append(tokens, result)
+
+	if !options.Wrap {
+		b.WriteString(strings.Join(tokens, " ") + "\n\n")
+		return b.String()
+	}
+	line := ""
+	for _, token := range tokens {
+		if line != "" && len(line)+1+len(token) > pgnWrapColumns {
+			b.WriteString(line + "\n")
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += token
+	}
+	b.WriteString(line + "\n\n")
+	return b.String()
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"URLchess/urlchess"
+	"strings"
+	"testing"
+
+	"github.com/andrewbackes/chess/fen"
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/position"
+)
+
+func TestLongAlgebraic(t *testing.T) {
+	for _, tc := range []struct {
+		// SAN moves played from the initial position, the last one is converted.
+		moves []string
+		want  string
+	}{
+		{[]string{"e4"}, "e2-e4"},
+		{[]string{"Nf3"}, "Ng1-f3"},
+		{[]string{"e4", "d5", "exd5"}, "e4xd5"},
+		{[]string{"e4", "d5", "exd5", "Qxd5"}, "Qd8xd5"},
+		{[]string{"e4", "a6", "e5", "d5", "exd6"}, "e5xd6"},
+		{[]string{"e4", "e5", "Bc4", "Nc6", "Bxf7+"}, "Bc4xf7+"},
+		{[]string{"f3", "e5", "g4", "Qh4#"}, "Qd8-h4#"},
+		{[]string{"e4", "e5", "Nf3", "Nc6", "Bc4", "Bc5", "O-O"}, "O-O"},
+		{[]string{"d4", "d5", "Nc3", "Nc6", "Bf4", "Bf5", "Qd2", "Qd7", "O-O-O"}, "O-O-O"},
+		{[]string{"a4", "b5", "axb5", "a6", "bxa6", "Bb7", "axb7", "Nc6", "bxa8=Q"}, "b7xa8=Q"},
+		{[]string{"a4", "b5", "axb5", "a6", "bxa6", "Bb7", "axb7", "Nc6", "b8=N"}, "b7-b8=N"},
+	} {
+		g, err := urlchess.PlaySAN(tc.moves...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		last := len(g.Positions) - 1
+		if got := longAlgebraic(g.Positions[last-1], g.Positions[last].LastMove); got != tc.want {
+			t.Errorf("%v: %q, want %q", tc.moves, got, tc.want)
+		}
+	}
+}
+
+func TestExportPGN(t *testing.T) {
+	scholars, err := urlchess.PlaySAN("e4", "e5", "Bc4", "Nc6", "Qh5", "Nf6", "Qxf7#")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags := map[string]string{
+		"Event":       "Casual game",
+		"Site":        "https://example.com/",
+		"Date":        "2024.05.??",
+		"Round":       "1",
+		"White":       "Alice",
+		"Black":       "Bob",
+		"Result":      "1-0",
+		"Termination": "normal",
+		"WhiteElo":    "1500",
+		"Annotator":   "Carol",
+		"ECO":         "C20",
+	}
+	tagOrder := []string{"Termination", "WhiteElo"}
+	const roster = "[Event \"Casual game\"]\n[Site \"https://example.com/\"]\n[Date \"2024.05.??\"]\n[Round \"1\"]\n[White \"Alice\"]\n[Black \"Bob\"]\n"
+
+	for _, tc := range []struct {
+		name    string
+		options pgnExportOptions
+		want    string
+	}{
+		{
+			"whole game",
+			pgnExportOptions{UpToHalfMove: -1},
+			roster + "[Result \"1-0\"]\n[Termination \"normal\"]\n[WhiteElo \"1500\"]\n[Annotator \"Carol\"]\n[ECO \"C20\"]\n\n" +
+				"1. e4 e5 2. Bc4 Nc6 3. Qh5 Nf6 4. Qxf7# 1-0\n\n",
+		},
+		{
+			"half-move beyond the end",
+			pgnExportOptions{UpToHalfMove: 7, OmitTags: true},
+			"1. e4 e5 2. Bc4 Nc6 3. Qh5 Nf6 4. Qxf7# 1-0\n\n",
+		},
+		{
+			"long algebraic",
+			pgnExportOptions{UpToHalfMove: -1, LongAlgebraic: true, OmitTags: true},
+			"1. e2-e4 e7-e5 2. Bf1-c4 Nb8-c6 3. Qd1-h5 Ng8-f6 4. Qh5xf7# 1-0\n\n",
+		},
+		{
+			"cut before the end",
+			pgnExportOptions{UpToHalfMove: 3},
+			roster + "[Result \"*\"]\n[Termination \"unterminated\"]\n[WhiteElo \"1500\"]\n[Annotator \"Carol\"]\n[ECO \"C20\"]\n\n" +
+				"1. e4 e5 2. Bc4 *\n\n",
+		},
+		{
+			"cut at the start",
+			pgnExportOptions{UpToHalfMove: 0, OmitTags: true},
+			"*\n\n",
+		},
+	} {
+		if got := exportPGN(scholars, tags, tagOrder, tc.options); got != tc.want {
+			t.Errorf("%s:\n%s\nwant:\n%s", tc.name, got, tc.want)
+		}
+	}
+
+	// Result tag is always written, even if missing.
+	if got := exportPGN(scholars, map[string]string{"Event": "?"}, nil, pgnExportOptions{UpToHalfMove: 2}); got != "[Event \"?\"]\n\n1. e4 e5 *\n\n" {
+		t.Errorf("without result:\n%s", got)
+	}
+}
+
+func TestExportPGNFromBlackMove(t *testing.T) {
+	// Game set up from position after 1. e4, black moves first.
+	p, err := fen.Decode("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := game.New()
+	g.Positions = []*position.Position{p}
+	for _, san := range []string{"e5", "Nf3"} {
+		m, err := g.Position().ParseMove(san)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.MakeMove(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "1... e5 2. Nf3 *\n\n"
+	if got := exportPGN(g, nil, nil, pgnExportOptions{UpToHalfMove: -1, OmitTags: true}); got != want {
+		t.Errorf("game from black move:\n%s\nwant:\n%s", got, want)
+	}
+	want = "1... e7-e5 2. Ng1-f3 *\n\n"
+	if got := exportPGN(g, nil, nil, pgnExportOptions{UpToHalfMove: -1, OmitTags: true, LongAlgebraic: true}); got != want {
+		t.Errorf("game from black move in long algebraic:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExportPGNWrap(t *testing.T) {
+	g, err := urlchess.DecodeGame(longGameHash(t, 120))
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := pgnExportOptions{UpToHalfMove: -1, OmitTags: true}
+	unwrapped := exportPGN(g, nil, nil, options)
+	options.Wrap = true
+	wrapped := exportPGN(g, nil, nil, options)
+
+	lines := strings.Split(strings.TrimSuffix(wrapped, "\n\n"), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("movetext is not wrapped:\n%s", wrapped)
+	}
+	for i, line := range lines {
+		if len(line) > pgnWrapColumns {
+			t.Errorf("line %d has %d columns: %q", i+1, len(line), line)
+		}
+		if i+1 < len(lines) {
+			next := strings.Fields(lines[i+1])[0]
+			if strings.HasSuffix(next, ".") {
+				next += " " + strings.Fields(lines[i+1])[1]
+			}
+			if len(line)+1+len(next) <= pgnWrapColumns {
+				t.Errorf("line %d is wrapped before %q, which fits", i+1, next)
+			}
+		}
+		// Move number is kept with its move.
+		if strings.HasSuffix(line, ".") {
+			t.Errorf("line %d ends with move number: %q", i+1, line)
+		}
+	}
+	if got := strings.Join(lines, " ") + "\n\n"; got != unwrapped {
+		t.Errorf("wrapped movetext differs from unwrapped:\n%s\nwant:\n%s", got, unwrapped)
+	}
+}
+
+func TestExportPGNEndedGameStatus(t *testing.T) {
+	g, err := urlchess.PlaySAN("f3", "e5", "g4", "Qh4#")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Status() == game.InProgress {
+		t.Fatal("fool's mate game is in progress")
+	}
+	tags := map[string]string{"Result": "0-1", "Termination": pgnTermination(g.Status())}
+	want := "[Result \"0-1\"]\n[Termination \"normal\"]\n\n1. f3 e5 2. g4 Qh4# 0-1\n\n"
+	if got := exportPGN(g, tags, nil, pgnExportOptions{UpToHalfMove: -1}); got != want {
+		t.Errorf("ended game:\n%s\nwant:\n%s", got, want)
+	}
+}
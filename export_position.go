@@ -0,0 +1,284 @@
+package main
+
+import (
+	"URLchess/shf"
+	"URLchess/shf/js"
+	"URLchess/urlchess"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/position"
+)
+
+// Saves content (string or JavaScript typed array) as a file with filename, using a Blob URL.
+func downloadFile(filename, mimeType string, content interface{}) error {
+	blob := js.Global().Get("Blob")
+	url := js.Global().Get("URL")
+	if js.IsUndefined(blob) || js.IsUndefined(url) || js.IsUndefined(url.Get("createObjectURL")) {
+		return errors.New(tr("Downloading files is not supported by this browser"))
+	}
+
+	href := url.Call("createObjectURL", blob.New([]interface{}{content}, map[string]interface{}{"type": mimeType}))
+	link := shf.CreateElementObject("a")
+	link.Set("href", href)
+	link.Set("download", filename)
+	link.Get("style").Set("display", "none")
+	body := js.Global().Get("document").Get("body")
+	body.Call("appendChild", link)
+	link.Call("click")
+	shf.DestroyElementObject(link)
+	url.Call("revokeObjectURL", href)
+	return nil
+}
+
+// Export of the position at current move, as FEN and as board diagram.
+// Model sets copy events & copy buttons visibility.
+type ModelExportPosition struct {
+	shf.Element
+	Position *position.Position
+	// Show diagram from black's side.
+	Flipped bool
+
+	FEN         shf.Element
+	CopyFEN     shf.Element
+	DownloadFEN shf.Element
+	Diagram     shf.Element
+	Source      shf.Element
+	Flip        shf.Element
+	CopySVG     shf.Element
+	Download    shf.Element
+
+	CopySupported bool
+}
+
+func (this *ModelExportPosition) Init(tools *shf.Tools) error {
+	if this.FEN == nil {
+		this.FEN = tools.CreateElement("input")
+		this.FEN.Set("type", "text")
+		this.FEN.Call("setAttribute", "readonly", "readonly")
+		this.FEN.Call("setAttribute", "aria-label", "FEN")
+	}
+	if this.CopyFEN == nil {
+		this.CopyFEN = tools.CreateElement("button")
+		this.CopyFEN.Set("textContent", tr("copy FEN"))
+	}
+	if this.DownloadFEN == nil {
+		this.DownloadFEN = tools.CreateElement("button")
+		this.DownloadFEN.Set("textContent", tr("download FEN"))
+		if err := tools.Click(this.DownloadFEN, func(_ shf.Event) error {
+			if this.Position == nil {
+				return nil
+			}
+			return downloadFile("URLchess-position.txt", "text/plain", urlchess.FEN(this.Position)+"\n")
+		}); err != nil {
+			return err
+		}
+	}
+	if this.Diagram == nil {
+		this.Diagram = tools.CreateElement("img")
+		this.Diagram.Set("alt", tr("Board diagram"))
+	}
+	if this.Source == nil {
+		// SVG source for copying to clipboard, hidden.
+		this.Source = tools.CreateElement("textarea")
+		this.Source.Call("setAttribute", "readonly", "readonly")
+		this.Source.Call("setAttribute", "aria-hidden", "true")
+		this.Source.Get("classList").Call("add", "source")
+	}
+	if this.Flip == nil {
+		this.Flip = tools.CreateElement("button")
+		this.Flip.Set("textContent", tr("flip diagram"))
+		if err := tools.Click(this.Flip, func(_ shf.Event) error {
+			this.Flipped = !this.Flipped
+			return tools.Update(this)
+		}); err != nil {
+			return err
+		}
+	}
+	if this.CopySVG == nil {
+		this.CopySVG = tools.CreateElement("button")
+		this.CopySVG.Set("textContent", tr("copy SVG"))
+	}
+	if this.Download == nil {
+		this.Download = tools.CreateElement("button")
+		this.Download.Set("textContent", tr("download diagram"))
+		if err := tools.Click(this.Download, func(_ shf.Event) error {
+			if this.Position == nil {
+				return nil
+			}
+			return downloadFile("URLchess-diagram.svg", "image/svg+xml", positionDiagramSVG(this.Position, this.Flipped))
+		}); err != nil {
+			return err
+		}
+	}
+
+	if this.Element == nil {
+		this.Element = tools.CreateElement("div")
+		this.Get("classList").Call("add", "position")
+
+		fen := tools.CreateElement("p")
+		fen.Get("classList").Call("add", "fen")
+		fen.Call("appendChild", tools.CreateTextNode("FEN: "))
+		fen.Call("appendChild", this.FEN.Object())
+		fen.Call("appendChild", this.CopyFEN.Object())
+		fen.Call("appendChild", this.DownloadFEN.Object())
+
+		buttons := tools.CreateElement("p")
+		buttons.Get("classList").Call("add", "buttons")
+		buttons.Call("appendChild", this.Flip.Object())
+		buttons.Call("appendChild", this.CopySVG.Object())
+		buttons.Call("appendChild", this.Download.Object())
+
+		this.Call("appendChild", fen.Object())
+		this.Call("appendChild", this.Diagram.Object())
+		this.Call("appendChild", this.Source.Object())
+		this.Call("appendChild", buttons.Object())
+	}
+	return nil
+}
+
+func (this *ModelExportPosition) Update(tools *shf.Tools) error {
+	if this == nil {
+		return errors.New("ModelExportPosition is nil")
+	}
+
+	for _, button := range []shf.Element{this.CopyFEN, this.CopySVG} {
+		if this.CopySupported {
+			button.Get("classList").Call("remove", "hidden")
+		} else {
+			button.Get("classList").Call("add", "hidden")
+		}
+	}
+
+	if this.Position == nil {
+		this.FEN.Set("value", "")
+		this.Source.Set("value", "")
+		this.Diagram.Set("src", "")
+		return nil
+	}
+
+	svg := positionDiagramSVG(this.Position, this.Flipped)
+	this.FEN.Set("value", urlchess.FEN(this.Position))
+	this.Source.Set("value", svg)
+	this.Diagram.Set("src", "data:image/svg+xml;charset=utf-8,"+js.Global().Call("encodeURIComponent", svg).String())
+	return nil
+}
+
+// Copies value of the export position input or textarea to clipboard.
+func (h *HtmlModel) CopyExportPositionToClipboard(input shf.Element) error {
+	positionX := js.Global().Get("pageXOffset")
+	positionY := js.Global().Get("pageYOffset")
+
+	input.Call("focus")
+	input.Call("setSelectionRange", 0, len(input.Get("value").String()))
+	js.Global().Get("document").Call("execCommand", "Copy")
+	input.Call("blur")
+
+	js.Global().Call("scrollTo", positionX, positionY)
+	return nil
+}
+
+// GIF frame delays in milliseconds & labels.
+var gifDelays = [][2]string{
+	{"500", "0.5 s"},
+	{"1000", "1 s"},
+	{"2000", "2 s"},
+	{"3000", "3 s"},
+}
+
+// Export of the whole game as animated GIF.
+type ModelExportGIF struct {
+	shf.Element
+	Game                 *game.Game
+	White, Black, Result string
+	// Show board from black's side.
+	Flipped bool
+
+	Delay       shf.Element
+	Orientation shf.Element
+	Download    shf.Element
+}
+
+func (this *ModelExportGIF) Init(tools *shf.Tools) error {
+	if this.Delay == nil {
+		this.Delay = tools.CreateElement("select")
+		for _, delay := range gifDelays {
+			option := tools.CreateElement("option")
+			option.Set("value", delay[0])
+			option.Set("textContent", delay[1])
+			this.Delay.Call("appendChild", option.Object())
+		}
+		this.Delay.Set("value", gifDelays[1][0])
+	}
+	if this.Orientation == nil {
+		this.Orientation = tools.CreateElement("select")
+		for _, o := range [][2]string{{"white", tr("white at bottom")}, {"black", tr("black at bottom")}} {
+			option := tools.CreateElement("option")
+			option.Set("value", o[0])
+			option.Set("textContent", o[1])
+			this.Orientation.Call("appendChild", option.Object())
+		}
+		if err := tools.Input(this.Orientation, func(_ shf.Event) error {
+			this.Flipped = this.Orientation.Get("value").String() == "black"
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if this.Download == nil {
+		this.Download = tools.CreateElement("button")
+		this.Download.Set("textContent", tr("download GIF"))
+		if err := tools.Click(this.Download, func(_ shf.Event) error {
+			if this.Game == nil {
+				return nil
+			}
+			ms, err := strconv.Atoi(this.Delay.Get("value").String())
+			if err != nil {
+				return err
+			}
+			white, black := this.White, this.Black
+			if white == "" {
+				white = tr("White")
+			}
+			if black == "" {
+				black = tr("Black")
+			}
+			data, err := gameGIF(this.Game, this.Flipped, time.Duration(ms)*time.Millisecond, white, black, this.Result)
+			if err != nil {
+				return err
+			}
+			return downloadFile("URLchess.gif", "image/gif", js.Uint8Array(data))
+		}); err != nil {
+			return err
+		}
+	}
+
+	if this.Element == nil {
+		this.Element = tools.CreateElement("p")
+		this.Get("classList").Call("add", "gif")
+
+		delay := tools.CreateElement("label")
+		delay.Call("appendChild", tools.CreateTextNode(tr("Frame delay")+" "))
+		delay.Call("appendChild", this.Delay.Object())
+
+		this.Call("appendChild", delay.Object())
+		this.Call("appendChild", this.Orientation.Object())
+		this.Call("appendChild", this.Download.Object())
+	}
+	return nil
+}
+
+func (this *ModelExportGIF) Update(tools *shf.Tools) error {
+	if this == nil {
+		return errors.New("ModelExportGIF is nil")
+	}
+
+	if this.Flipped {
+		this.Orientation.Set("value", "black")
+	} else {
+		this.Orientation.Set("value", "white")
+	}
+	return nil
+}
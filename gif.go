@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"strings"
+	"time"
+
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position"
+	"github.com/andrewbackes/chess/position/move"
+)
+
+// Colours of the GIF frames. Indexes are used for drawing.
+const (
+	gifWhite uint8 = iota
+	gifBlack
+	gifLightSquare
+	gifDarkSquare
+	gifLightLastMove
+	gifDarkLastMove
+	gifBar
+	gifBarText
+	gifBlackPiece
+)
+
+var gifPalette = color.Palette{
+	gifWhite:         color.RGBA{0xff, 0xff, 0xff, 0xff},
+	gifBlack:         color.RGBA{0x00, 0x00, 0x00, 0xff},
+	gifLightSquare:   color.RGBA{0xf0, 0xd9, 0xb5, 0xff},
+	gifDarkSquare:    color.RGBA{0xb5, 0x88, 0x63, 0xff},
+	gifLightLastMove: color.RGBA{0xcd, 0xd2, 0x6a, 0xff},
+	gifDarkLastMove:  color.RGBA{0xaa, 0xa2, 0x3a, 0xff},
+	gifBar:           color.RGBA{0x31, 0x2e, 0x2b, 0xff},
+	gifBarText:       color.RGBA{0xdd, 0xdd, 0xdd, 0xff},
+	gifBlackPiece:    color.RGBA{0x3a, 0x3a, 0x3a, 0xff},
+}
+
+// Piece sprites, 16x16 pixels. 'o' is outline, 'x' is fill in piece colour.
+var gifPieceSprites = map[piece.Type][16]string{
+	piece.King: {
+		"................",
+		".......oo.......",
+		"......oxxo......",
+		".......oo.......",
+		"..ooo.oxxo.ooo..",
+		".oxxxooxxooxxxo.",
+		".oxxxxoxxoxxxxo.",
+		".oxxxxxxxxxxxxo.",
+		"..oxxxxxxxxxxo..",
+		"...oxxxxxxxxo...",
+		"...oxxxxxxxxo...",
+		"...oooooooooo...",
+		"...oxxxxxxxxo...",
+		"..oxxxxxxxxxxo..",
+		"..oooooooooooo..",
+		"................",
+	},
+	piece.Queen: {
+		"................",
+		"..o....oo....o..",
+		".oxo..oxxo..oxo.",
+		"..oo...oo...oo..",
+		"..oxo.oxxo.oxo..",
+		"..oxxoxxxxoxxo..",
+		"..oxxxxxxxxxxo..",
+		"...oxxxxxxxxo...",
+		"...oxxxxxxxxo...",
+		"....oxxxxxxo....",
+		"...oooooooooo...",
+		"...oxxxxxxxxo...",
+		"..oxxxxxxxxxxo..",
+		"..oooooooooooo..",
+		"................",
+		"................",
+	},
+	piece.Rook: {
+		"................",
+		"................",
+		"..ooo.oooo.ooo..",
+		"..oxo.oxxo.oxo..",
+		"..oxoooxxoooxo..",
+		"..oxxxxxxxxxxo..",
+		"..oooooooooooo..",
+		"...oxxxxxxxxo...",
+		"...oxxxxxxxxo...",
+		"...oxxxxxxxxo...",
+		"...oxxxxxxxxo...",
+		"..oooooooooooo..",
+		"..oxxxxxxxxxxo..",
+		".oxxxxxxxxxxxxo.",
+		".oooooooooooooo.",
+		"................",
+	},
+	piece.Bishop: {
+		"................",
+		".......oo.......",
+		"......oxxo......",
+		".......oo.......",
+		"......oxxo......",
+		".....oxxoxo.....",
+		"....oxxxoxxo....",
+		"....oxxoxxxo....",
+		"....oxxxxxxo....",
+		".....oxxxxo.....",
+		"......oxxo......",
+		".....oooooo.....",
+		".....oxxxxo.....",
+		"...oooxxxxooo...",
+		"..oxxxxxxxxxxo..",
+		"..oooooooooooo..",
+	},
+	piece.Knight: {
+		"................",
+		"......o.o.......",
+		".....oxoxoo.....",
+		"....oxxxxxxo....",
+		"...oxxoxxxxxo...",
+		"..oxxxxxxxxxo...",
+		".oxxxxxxxxxxxo..",
+		".oxxxooxxxxxxo..",
+		"..ooo.oxxxxxxo..",
+		".....oxxxxxxo...",
+		"....oxxxxxxxo...",
+		"...oxxxxxxxxo...",
+		"...oooooooooo...",
+		"..oxxxxxxxxxxo..",
+		"..oooooooooooo..",
+		"................",
+	},
+	piece.Pawn: {
+		"................",
+		"................",
+		"................",
+		"......oooo......",
+		".....oxxxxo.....",
+		".....oxxxxo.....",
+		"......oxxo......",
+		".....oxxxxo.....",
+		"......oxxo......",
+		"......oxxo......",
+		".....oxxxxo.....",
+		"....oxxxxxxo....",
+		"...oxxxxxxxxo...",
+		"...oooooooooo...",
+		"................",
+		"................",
+	},
+}
+
+// Bitmap font for texts in GIF frames, 5x7 pixels per character. Only upper case letters are drawn.
+var gifFont = map[rune][7]string{
+	' ':  {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+	'A':  {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B':  {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C':  {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D':  {"####.", "#...#", "#...#", "#...#", "#...#", "#...#", "####."},
+	'E':  {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F':  {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G':  {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H':  {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I':  {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J':  {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K':  {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L':  {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M':  {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N':  {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O':  {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P':  {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q':  {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R':  {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S':  {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T':  {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U':  {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V':  {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W':  {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X':  {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y':  {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z':  {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	'0':  {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1':  {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2':  {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3':  {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4':  {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5':  {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6':  {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7':  {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8':  {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9':  {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'-':  {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'/':  {".....", "....#", "...#.", "..#..", ".#...", "#....", "....."},
+	'.':  {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	',':  {".....", ".....", ".....", ".....", ".##..", "..#..", ".#..."},
+	':':  {".....", ".##..", ".##..", ".....", ".##..", ".##..", "....."},
+	'?':  {".###.", "#...#", "....#", "...#.", "..#..", ".....", "..#.."},
+	'(':  {"...#.", "..#..", ".#...", ".#...", ".#...", "..#..", "...#."},
+	')':  {".#...", "..#..", "...#.", "...#.", "...#.", "..#..", ".#..."},
+	'\'': {"..#..", "..#..", ".#...", ".....", ".....", ".....", "....."},
+	'*':  {".....", "..#..", "#.#.#", ".###.", "#.#.#", "..#..", "....."},
+}
+
+// Letters with diacritics are drawn without them.
+var gifFontFold = strings.NewReplacer(
+	"Á", "A", "Ä", "A", "À", "A", "Â", "A", "Ã", "A", "Å", "A", "Ą", "A",
+	"Č", "C", "Ć", "C", "Ç", "C", "Ď", "D",
+	"É", "E", "Ě", "E", "È", "E", "Ê", "E", "Ë", "E", "Ę", "E",
+	"Í", "I", "Ì", "I", "Î", "I", "Ï", "I",
+	"Ĺ", "L", "Ľ", "L", "Ł", "L", "Ň", "N", "Ń", "N", "Ñ", "N",
+	"Ó", "O", "Ô", "O", "Ö", "O", "Ò", "O", "Õ", "O", "Ő", "O", "Ø", "O",
+	"Ŕ", "R", "Ř", "R", "Š", "S", "Ś", "S", "ß", "SS", "Ť", "T",
+	"Ú", "U", "Ů", "U", "Ü", "U", "Ù", "U", "Û", "U", "Ű", "U",
+	"Ý", "Y", "Ÿ", "Y", "Ž", "Z", "Ź", "Z", "Ż", "Z",
+)
+
+const (
+	gifSquare     = 40 // square size in pixels
+	gifPieceScale = 2  // sprite pixel size
+	gifTextScale  = 2  // font pixel size
+	gifBarHeight  = 11 * gifTextScale
+)
+
+func gifFillRect(img *image.Paletted, r image.Rectangle, c uint8) {
+	r = r.Intersect(img.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetColorIndex(x, y, c)
+		}
+	}
+}
+
+// Returns width of text drawn with scale.
+func gifTextWidth(text string, scale int) int {
+	return len([]rune(gifFontFold.Replace(strings.ToUpper(text)))) * 6 * scale
+}
+
+// Draws text with top-left corner at x, y. Characters without glyph are drawn as '?'.
+func gifDrawText(img *image.Paletted, x, y int, text string, scale int, c uint8) {
+	for _, r := range gifFontFold.Replace(strings.ToUpper(text)) {
+		glyph, ok := gifFont[r]
+		if !ok {
+			glyph = gifFont['?']
+		}
+		for gy, row := range glyph {
+			for gx, px := range row {
+				if px == '#' {
+					gifFillRect(img, image.Rect(x+gx*scale, y+gy*scale, x+(gx+1)*scale, y+(gy+1)*scale), c)
+				}
+			}
+		}
+		x += 6 * scale
+	}
+}
+
+func gifDrawPiece(img *image.Paletted, x, y int, p piece.Piece) {
+	sprite, ok := gifPieceSprites[p.Type]
+	if !ok {
+		return
+	}
+	fill := gifWhite
+	if p.Color == piece.Black {
+		fill = gifBlackPiece
+	}
+	offset := (gifSquare - 16*gifPieceScale) / 2
+	for sy, row := range sprite {
+		for sx, px := range row {
+			c := fill
+			switch px {
+			case 'o':
+				c = gifBlack
+			case 'x':
+			default:
+				continue
+			}
+			x0, y0 := x+offset+sx*gifPieceScale, y+offset+sy*gifPieceScale
+			gifFillRect(img, image.Rect(x0, y0, x0+gifPieceScale, y0+gifPieceScale), c)
+		}
+	}
+}
+
+// Draws player name bar. Player on the move is marked with a square in its colour.
+func gifDrawBar(img *image.Paletted, y int, name string, color piece.Color, onMove bool) {
+	width := img.Rect.Dx()
+	gifFillRect(img, image.Rect(0, y, width, y+gifBarHeight), gifBar)
+	textY := y + (gifBarHeight-7*gifTextScale)/2
+	gifDrawText(img, 2*gifTextScale, textY, name, gifTextScale, gifBarText)
+	if onMove {
+		marker := 7 * gifTextScale
+		fill := gifWhite
+		if color == piece.Black {
+			fill = gifBlackPiece
+		}
+		r := image.Rect(width-marker-2*gifTextScale, textY, width-2*gifTextScale, textY+marker)
+		gifFillRect(img, r, gifBarText)
+		gifFillRect(img, r.Inset(1), fill)
+	}
+}
+
+// Returns frame with position p. Top & bottom bars contain player names, if result is not empty, it is shown over the board.
+func gifFrame(p *position.Position, flipped bool, white, black, result string) *image.Paletted {
+	board := 8 * gifSquare
+	img := image.NewPaletted(image.Rect(0, 0, board, board+2*gifBarHeight), gifPalette)
+
+	top, bottom := piece.Black, piece.White
+	if flipped {
+		top, bottom = bottom, top
+	}
+	names := map[piece.Color]string{piece.White: white, piece.Black: black}
+	gifDrawBar(img, 0, names[top], top, result == "" && p.ActiveColor == top)
+	gifDrawBar(img, gifBarHeight+board, names[bottom], bottom, result == "" && p.ActiveColor == bottom)
+
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			s := diagramSquare(row, col, flipped)
+			x, y := col*gifSquare, gifBarHeight+row*gifSquare
+			c := gifLightSquare
+			if (row+col)%2 == 1 {
+				c = gifDarkSquare
+			}
+			if p.LastMove != move.Null && (p.LastMove.From() == s || p.LastMove.To() == s) {
+				c += gifLightLastMove - gifLightSquare
+			}
+			gifFillRect(img, image.Rect(x, y, x+gifSquare, y+gifSquare), c)
+			if pce := p.OnSquare(s); pce.Type != piece.None {
+				gifDrawPiece(img, x, y, pce)
+			}
+		}
+	}
+
+	if result != "" {
+		scale := 2 * gifTextScale
+		w, h := gifTextWidth(result, scale), 7*scale
+		x, y := (board-w)/2, gifBarHeight+(board-h)/2
+		box := image.Rect(x-2*scale, y-2*scale, x+w+scale, y+h+2*scale)
+		gifFillRect(img, box, gifBlack)
+		gifFillRect(img, box.Inset(2), gifWhite)
+		gifDrawText(img, x, y, result, scale, gifBlack)
+	}
+	return img
+}
+
+// Encodes all positions of game g as animated GIF. The last frame shows result (if not "*") and is shown 3 times longer.
+func gameGIF(g *game.Game, flipped bool, delay time.Duration, white, black, result string) ([]byte, error) {
+	if result == "*" {
+		result = ""
+	}
+	delay100 := int(delay / (10 * time.Millisecond))
+	anim := &gif.GIF{}
+	for i, p := range g.Positions {
+		frameResult, frameDelay := "", delay100
+		if i == len(g.Positions)-1 {
+			frameResult, frameDelay = result, 3*delay100
+		}
+		anim.Image = append(anim.Image, gifFrame(p, flipped, white, black, frameResult))
+		anim.Delay = append(anim.Delay, frameDelay)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := gif.EncodeAll(buf, anim); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"URLchess/shf/js"
+	"strings"
+)
+
+// Ways of recording game hash changes in browser history, chosen in settings.
+var historyModes = [][2]string{
+	{"moves", "Entry for every move"},
+	{"all", "Entry for every move and navigation"},
+	{"game", "One entry per game"},
+}
+
+// Kinds of location hash changes.
+type navigation int
+
+const (
+	// A move was made.
+	navigationMove navigation = iota
+	// Going through moves of the game, or changing the link of the shown position.
+	navigationBrowse
+	// Another game was started.
+	navigationGame
+)
+
+// Navigates by setting the browser location hash, which is then handled by hash change event.
+type locationNavigator struct {
+	// Returns history mode, see historyModes. It is asked on every navigation, so a changed setting applies immediately.
+	mode func() string
+}
+
+// Sets location hash. Browser history entry is pushed, or the current one is replaced, as chosen by history mode:
+//
+//	moves: moves and new games push, browsing replaces
+//	all:   everything pushes
+//	game:  new games push, moves and browsing replace
+//
+// Either way the hash change event follows, so back & forward navigation is handled the same as any other hash change.
+func (n locationNavigator) navigate(kind navigation, hash string) {
+	hash = "#" + strings.TrimPrefix(hash, "#")
+	push := true
+	switch n.mode() {
+	case "moves":
+		push = kind != navigationBrowse
+	case "game":
+		push = kind == navigationGame
+	}
+	location := js.Global().Get("location")
+	if push {
+		location.Set("hash", hash)
+	} else {
+		location.Call("replace", hash)
+	}
+}
+
+func (n locationNavigator) Navigate(hash string) {
+	n.navigate(navigationMove, hash)
+}
+func (n locationNavigator) Browse(hash string) {
+	n.navigate(navigationBrowse, hash)
+}
+func (n locationNavigator) StartGame(hash string) {
+	n.navigate(navigationGame, hash)
+}
+
+// Carries out navigation of a game to a game hash, e.g. by writing the location hash in a browser.
+// Game model makes its navigation side effects only through it, so it can be tested outside of the browser.
+// Moves, browsing & new games may be recorded in browser history differently.
+type gameNavigator interface {
+	Navigate(hash string)
+	Browse(hash string)
+	StartGame(hash string)
+}
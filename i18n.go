@@ -0,0 +1,559 @@
+package main
+
+import (
+	"URLchess/shf/js"
+	"strings"
+)
+
+// Languages with user interface translations. The first one is the default and the source language of the message catalogue.
+var languages = [][2]string{
+	{"en", "English"},
+	{"de", "Deutsch"},
+	{"sk", "Slovenčina"},
+}
+
+// Current user interface language code.
+var language = "en"
+
+// If true, piece letters in displayed moves are localized, e.g. "Sf3" instead of "Nf3" in German.
+var localizedSAN = false
+
+// Translations of english user interface messages. Messages can contain "%s" placeholders, which are filled in order by tr arguments.
+// Missing translations fall back to english.
+var messageCatalogue = map[string]map[string]string{
+	"de": {
+		// Buttons & labels.
+		"Go to game start":      "Zum Spielanfang",
+		"Previous move":         "Vorheriger Zug",
+		"Next move":             "Nächster Zug",
+		"Go to last move":       "Zum letzten Zug",
+		"Back to received game": "Zurück zur erhaltenen Partie",
+		"New game position":     "Ausgangsstellung",
+		"Copy to clipboard":     "In die Zwischenablage kopieren",
+		"back":                  "zurück",
+		"close":                 "schließen",
+		"new game":              "neue Partie",
+		"export":                "exportieren",
+		"export game":           "Partie exportieren",
+		"copy link":             "Link kopieren",
+		"toggle high contrast":  "hoher Kontrast an/aus",
+		"toggle zen mode":       "Zen-Modus an/aus",
+		"toggle sounds":         "Töne an/aus",
+		"keyboard shortcuts":    "Tastenkürzel",
+		"brown":                 "braun",
+		"green":                 "grün",
+		"blue":                  "blau",
+		"gray":                  "grau",
+		"classic":               "klassisch",
+		"flat":                  "flach",
+		"settings":              "Einstellungen",
+		"Settings":              "Einstellungen",
+		"open settings":         "Einstellungen öffnen",
+		"Rotate board for player on the move automatically": "Brett automatisch für den Spieler am Zug drehen",
+		"Zen mode":                         "Zen-Modus",
+		"High contrast markers":            "Kontrastreiche Markierungen",
+		"Always promote to queen":          "Immer in eine Dame umwandeln",
+		"Skip game link dialog after move": "Dialog mit Partie-Link nach dem Zug überspringen",
+		"Show tips":                        "Tipps anzeigen",
+		"Sound effects":                    "Soundeffekte",
+		"Localized piece letters in moves": "Lokalisierte Figurenbuchstaben in Zügen",
+		"Board colours":                    "Brettfarben",
+		"Pieces":                           "Figuren",
+		"Colour scheme":                    "Farbschema",
+		"automatic":                        "automatisch",
+		"dark":                             "dunkel",
+		"light":                            "hell",
+		"White name":                       "Name Weiß",
+		"Black name":                       "Name Schwarz",
+		"Round no.":                        "Runde",
+		"Start date":                       "Datum",
+		"Result":                           "Ergebnis",
+		"type move, e.g. Nf3 or e2e4":      "Zug eingeben, z. B. Sf3 oder e2e4",
+		" v%s by jEzEk. Source on ":        " v%s von jEzEk. Quellcode auf ",
+		"This URL link represents the state of current chess game. You can copy it and store it or send it.": "Dieser URL-Link stellt den aktuellen Stand der Schachpartie dar. Du kannst ihn kopieren, speichern oder versenden.",
+
+		// Notifications.
+		"Quick actions":                                        "Schnellaktionen",
+		"Language":                                             "Sprache",
+		"Keyboard shortcuts":                                   "Tastenkürzel",
+		"Game URL was copied to clipboard":                     "Partie-URL wurde in die Zwischenablage kopiert",
+		"Game PGN was copied to clipboard":                     "Partie-PGN wurde in die Zwischenablage kopiert",
+		"Game ended, no more moves can be made":                "Partie beendet, es können keine Züge mehr gemacht werden",
+		"no move typed":                                        "kein Zug eingegeben",
+		"\"%s\" is not a legal move":                           "„%s“ ist kein gültiger Zug",
+		"\"%s\" is ambiguous":                                  "„%s“ ist mehrdeutig",
+		"tip: %s":                                              "Tipp: %s",
+		"tip: click on last move piece to copy":                "Tipp: zum Kopieren auf die zuletzt gezogene Figur klicken",
+		"tip: double click on empty square to toggle zen mode": "Tipp: Doppelklick auf ein leeres Feld schaltet den Zen-Modus um",
+		"tip: also click anywhere outside to close this notification": "Tipp: zum Schließen auch irgendwo außerhalb klicken",
+		"tip: click anywhere outside to close this notification":      "Tipp: zum Schließen irgendwo außerhalb klicken",
+		"tip: press ? to see keyboard shortcuts":                      "Tipp: drücke ? für Tastenkürzel",
+
+		// Tips.
+		"click on this tip, to see next tip":                                                    "klicke auf diesen Tipp, um den nächsten zu sehen",
+		"to close notifications, click anywhere except buttons in notification":                 "um Benachrichtigungen zu schließen, klicke irgendwohin außer auf ihre Schaltflächen",
+		"to quick copy game to clipboard, click on last move piece":                             "um die Partie schnell zu kopieren, klicke auf die zuletzt gezogene Figur",
+		"to go back a move, click on last move FROM square":                                     "um einen Zug zurückzunehmen, klicke auf das Ausgangsfeld des letzten Zuges",
+		"to rotate board (if supported), click on corners with arrows (bottom-left, top-right)": "um das Brett zu drehen (falls unterstützt), klicke auf die Ecken mit Pfeilen (unten links, oben rechts)",
+		"to rotate board for current moving player, click on game status icon, or text":         "um das Brett für den Spieler am Zug zu drehen, klicke auf das Symbol oder den Text des Spielstatus",
+		"to toggle this game URL dialog, click on any empty square on board":                    "um diesen Dialog mit der Partie-URL ein- oder auszublenden, klicke auf ein leeres Feld",
+		"to toggle zen mode, try double click on empty chess square":                            "um den Zen-Modus umzuschalten, doppelklicke auf ein leeres Feld",
+		"to move a piece, you can also drag it to the destination square":                       "eine Figur kannst du auch auf das Zielfeld ziehen",
+		"to see keyboard shortcuts, press ? key":                                                "um die Tastenkürzel zu sehen, drücke ?",
+		"to make a move with keyboard, press / key and type the move (e.g. Nf3 or e2e4)":        "um mit der Tastatur zu ziehen, drücke / und tippe den Zug (z. B. Sf3 oder e2e4)",
+
+		// Keyboard shortcuts.
+		"previous / next move":                   "vorheriger / nächster Zug",
+		"go to game start":                       "zum Spielanfang",
+		"go to last move":                        "zum letzten Zug",
+		"cancel move, close dialogs":             "Zug abbrechen, Dialoge schließen",
+		"type a move (e.g. Nf3, e2e4)":           "Zug eintippen (z. B. Sf3, e2e4)",
+		"focus board, then use arrows and Enter": "Brett fokussieren, dann Pfeiltasten und Enter",
+		"rotate board":                           "Brett drehen",
+		"toggle high contrast markers":           "kontrastreiche Markierungen an/aus",
+		"show keyboard shortcuts":                "Tastenkürzel anzeigen",
+
+		// Game status.
+		"White player is on the move":   "Weiß ist am Zug",
+		"Black player is on the move":   "Schwarz ist am Zug",
+		"In progress":                   "Läuft",
+		"White checkmated Black":        "Weiß hat Schwarz mattgesetzt",
+		"Black checkmated White":        "Schwarz hat Weiß mattgesetzt",
+		"Black ran out of time":         "Schwarz hat die Zeit überschritten",
+		"White ran out of time":         "Weiß hat die Zeit überschritten",
+		"Black resigned":                "Schwarz hat aufgegeben",
+		"White resigned":                "Weiß hat aufgegeben",
+		"Black made an illegal move":    "Schwarz hat einen regelwidrigen Zug gemacht",
+		"White made an illegal move":    "Weiß hat einen regelwidrigen Zug gemacht",
+		"Draw by threefold repetition":  "Remis durch dreifache Stellungswiederholung",
+		"Draw by Fifty move rule":       "Remis durch die 50-Züge-Regel",
+		"Draw by stalemate":             "Remis durch Patt",
+		"Draw by insufficient material": "Remis durch ungenügendes Material",
+		"White won":                     "Weiß hat gewonnen",
+		"Black won":                     "Schwarz hat gewonnen",
+		"Draw":                          "Remis",
+
+		// Screen reader texts.
+		"White":                  "Weiß",
+		"Black":                  "Schwarz",
+		"king":                   "König",
+		"queen":                  "Dame",
+		"rook":                   "Turm",
+		"bishop":                 "Läufer",
+		"knight":                 "Springer",
+		"pawn":                   "Bauer",
+		"white %s":               "%s von Weiß",
+		"black %s":               "%s von Schwarz",
+		"empty":                  "leer",
+		"takes":                  "schlägt",
+		"castles kingside":       "kurze Rochade",
+		"castles queenside":      "lange Rochade",
+		" promotes to %s":        " wandelt um in %s",
+		", check":                ", Schach",
+		", checkmate":            ", Schachmatt",
+		", last move":            ", letzter Zug",
+		", selected":             ", ausgewählt",
+		", possible move":        ", möglicher Zug",
+		", current position":     ", aktuelle Stellung",
+		", not played":           ", nicht gespielt",
+		"New game":               "Neue Partie",
+		"White plays %s":         "Weiß spielt %s",
+		"Black plays %s":         "Schwarz spielt %s",
+		"White to move":          "Weiß am Zug",
+		"Black to move":          "Schwarz am Zug",
+		"promote to %s":          "umwandeln in %s",
+		"Choose promotion piece": "Umwandlungsfigur wählen",
+		"Chess board, use arrow keys to move between squares and Enter to select": "Schachbrett, mit Pfeiltasten zwischen Feldern wechseln und mit Enter auswählen",
+		"Rotate board":                        "Brett drehen",
+		"Rotate board for player on the move": "Brett für den Spieler am Zug drehen",
+		"Game link":                           "Partie-Link",
+		"Export game":                         "Partie exportieren",
+		"Notification":                        "Benachrichtigung",
+		"Conditional reply":                   "Bedingte Antwort",
+		"Conditional replies":                 "Bedingte Antworten",
+		"attach to link":                      "an Link anhängen",
+		"e.g. Nxe5 Nxe5":                      "z.B. Sxe5 Sxe5",
+		"Conditional replies: if your opponent plays the first move of a line, your reply is played automatically. One line per condition, your opponent will not see them.": "Bedingte Antworten: Spielt Ihr Gegner den ersten Zug einer Zeile, wird Ihre Antwort automatisch gespielt. Eine Zeile pro Bedingung, Ihr Gegner sieht sie nicht.",
+		"Conditional reply lines attached to link: %s":                                  "An den Link angehängte bedingte Antworten: %s",
+		"Conditional replies can be added only to the last move of a running game":      "Bedingte Antworten können nur zum letzten Zug einer laufenden Partie hinzugefügt werden",
+		"promotion piece is missing in \"%s\"":                                          "Umwandlungsfigur fehlt in \"%s\"",
+		"line \"%s\" has to end with your reply":                                        "Zeile \"%s\" muss mit Ihrer Antwort enden",
+		"Your opponent prepared reply %s to your move %s, it was played automatically.": "Ihr Gegner hat die Antwort %s auf Ihren Zug %s vorbereitet, sie wurde automatisch gespielt.",
+		"Your conditional reply %s to move %s was played automatically.":                "Ihre bedingte Antwort %s auf den Zug %s wurde automatisch gespielt.",
+		"Play replay":         "Wiedergabe starten",
+		"Pause replay":        "Wiedergabe anhalten",
+		"Replay position":     "Wiedergabeposition",
+		"Replay speed":        "Wiedergabegeschwindigkeit",
+		"half-move %s of %s":  "Halbzug %s von %s",
+		"play / pause replay": "Wiedergabe starten / anhalten",
+		"Downloading files is not supported by this browser": "Das Herunterladen von Dateien wird von diesem Browser nicht unterstützt",
+		"copy FEN":                             "FEN kopieren",
+		"Board diagram":                        "Brettdiagramm",
+		"flip diagram":                         "Diagramm drehen",
+		"copy SVG":                             "SVG kopieren",
+		"download diagram":                     "Diagramm herunterladen",
+		"Position FEN was copied to clipboard": "FEN der Stellung wurde in die Zwischenablage kopiert",
+		"Diagram SVG was copied to clipboard":  "SVG des Diagramms wurde in die Zwischenablage kopiert",
+		"white at bottom":                      "Weiß unten",
+		"black at bottom":                      "Schwarz unten",
+		"download GIF":                         "GIF herunterladen",
+		"Frame delay":                          "Bildverzögerung",
+		"Event":                                "Turnier",
+		"Site":                                 "Ort",
+		"Termination":                          "Beendigung",
+		"White Elo":                            "Elo Weiß",
+		"Black Elo":                            "Elo Schwarz",
+		"White title":                          "Titel Weiß",
+		"Black title":                          "Titel Schwarz",
+		"White team":                           "Mannschaft Weiß",
+		"Black team":                           "Mannschaft Schwarz",
+		"White FIDE ID":                        "FIDE-ID Weiß",
+		"Black FIDE ID":                        "FIDE-ID Schwarz",
+		"Time control":                         "Bedenkzeit",
+		"Board no.":                            "Brett",
+		"Annotator":                            "Kommentator",
+		"Date has to be in YYYY.MM.DD format, use ?? for unknown parts": "Datum muss im Format JJJJ.MM.TT sein, verwende ?? für unbekannte Teile",
+		"Month has to be from 01 to 12":                                 "Monat muss zwischen 01 und 12 liegen",
+		"Day has to be from 01 to 31":                                   "Tag muss zwischen 01 und 31 liegen",
+		"Round has to be a number, like 3 or 3.1":                       "Runde muss eine Zahl sein, z. B. 3 oder 3.1",
+		"Value has to be a number":                                      "Wert muss eine Zahl sein",
+		"Time control has to be in seconds, like 300+2 or 40/7200:3600": "Bedenkzeit muss in Sekunden sein, z. B. 300+2 oder 40/7200:3600",
+		"Tag name can contain only letters, digits and underscores":     "Tag-Name darf nur Buchstaben, Ziffern und Unterstriche enthalten",
+		"Tag is already in the list":                                    "Tag ist bereits in der Liste",
+		"remove tag":                                                    "Tag entfernen",
+		"Tag name":                                                      "Tag-Name",
+		"add tag":                                                       "Tag hinzufügen",
+		"download PGN":                                                  "PGN herunterladen",
+		"download FEN":                                                  "FEN herunterladen",
+		"short algebraic (SAN)":                                         "kurze algebraische (SAN)",
+		"long algebraic":                                                "lange algebraische",
+		"Notation":                                                      "Notation",
+		"whole game":                                                    "ganze Partie",
+		"up to current move":                                            "bis zum aktuellen Zug",
+		"Moves":                                                         "Züge",
+		"wrap lines at 80 columns":                                      "Zeilen nach 80 Zeichen umbrechen",
+		"include tags":                                                  "Tags einschließen",
+		"Something went wrong":                                          "Etwas ist schiefgelaufen",
+		"Invalid game link":                                             "Ungültiger Spiellink",
+		"Browser history":                                               "Browserverlauf",
+		"Entry for every move":                                          "Eintrag für jeden Zug",
+		"Entry for every move and navigation":                           "Eintrag für jeden Zug und jede Navigation",
+		"One entry per game":                                            "Ein Eintrag pro Partie",
+	},
+	"sk": {
+		// Buttons & labels.
+		"Go to game start":      "Na začiatok partie",
+		"Previous move":         "Predchádzajúci ťah",
+		"Next move":             "Nasledujúci ťah",
+		"Go to last move":       "Na posledný ťah",
+		"Back to received game": "Späť na prijatú partiu",
+		"New game position":     "Východisková pozícia",
+		"Copy to clipboard":     "Kopírovať do schránky",
+		"back":                  "späť",
+		"close":                 "zavrieť",
+		"new game":              "nová partia",
+		"export":                "exportovať",
+		"export game":           "exportovať partiu",
+		"copy link":             "kopírovať odkaz",
+		"toggle high contrast":  "prepnúť vysoký kontrast",
+		"toggle zen mode":       "prepnúť zen režim",
+		"toggle sounds":         "prepnúť zvuky",
+		"keyboard shortcuts":    "klávesové skratky",
+		"brown":                 "hnedá",
+		"green":                 "zelená",
+		"blue":                  "modrá",
+		"gray":                  "sivá",
+		"classic":               "klasické",
+		"flat":                  "ploché",
+		"settings":              "nastavenia",
+		"Settings":              "Nastavenia",
+		"open settings":         "otvoriť nastavenia",
+		"Rotate board for player on the move automatically": "Automaticky otáčať šachovnicu k hráčovi na ťahu",
+		"Zen mode":                         "Zen režim",
+		"High contrast markers":            "Kontrastné značky",
+		"Always promote to queen":          "Vždy premeniť na dámu",
+		"Skip game link dialog after move": "Preskočiť dialóg s odkazom na partiu po ťahu",
+		"Show tips":                        "Zobrazovať tipy",
+		"Sound effects":                    "Zvukové efekty",
+		"Localized piece letters in moves": "Lokalizované písmená figúrok v ťahoch",
+		"Board colours":                    "Farby šachovnice",
+		"Pieces":                           "Figúrky",
+		"Colour scheme":                    "Farebná schéma",
+		"automatic":                        "automaticky",
+		"dark":                             "tmavé",
+		"light":                            "svetlé",
+		"White name":                       "Meno bieleho",
+		"Black name":                       "Meno čierneho",
+		"Round no.":                        "Kolo",
+		"Start date":                       "Dátum začiatku",
+		"Result":                           "Výsledok",
+		"type move, e.g. Nf3 or e2e4":      "zadajte ťah, napr. Jf3 alebo e2e4",
+		" v%s by jEzEk. Source on ":        " v%s od jEzEk. Zdrojový kód na ",
+		"This URL link represents the state of current chess game. You can copy it and store it or send it.": "Tento URL odkaz predstavuje stav aktuálnej šachovej partie. Môžete ho skopírovať, uložiť alebo poslať.",
+
+		// Notifications.
+		"Quick actions":                                        "Rýchle akcie",
+		"Language":                                             "Jazyk",
+		"Keyboard shortcuts":                                   "Klávesové skratky",
+		"Game URL was copied to clipboard":                     "URL partie bola skopírovaná do schránky",
+		"Game PGN was copied to clipboard":                     "PGN partie bolo skopírované do schránky",
+		"Game ended, no more moves can be made":                "Partia skončila, ďalšie ťahy nie sú možné",
+		"no move typed":                                        "nebol zadaný žiadny ťah",
+		"\"%s\" is not a legal move":                           "„%s“ nie je platný ťah",
+		"\"%s\" is ambiguous":                                  "„%s“ je nejednoznačný",
+		"tip: %s":                                              "tip: %s",
+		"tip: click on last move piece to copy":                "tip: na skopírovanie kliknite na figúrku posledného ťahu",
+		"tip: double click on empty square to toggle zen mode": "tip: dvojklikom na prázdne pole prepnete zen režim",
+		"tip: also click anywhere outside to close this notification": "tip: oznámenie zavriete aj kliknutím kamkoľvek mimo neho",
+		"tip: click anywhere outside to close this notification":      "tip: oznámenie zavriete kliknutím kamkoľvek mimo neho",
+		"tip: press ? to see keyboard shortcuts":                      "tip: stlačením ? zobrazíte klávesové skratky",
+
+		// Tips.
+		"click on this tip, to see next tip":                                                    "kliknite na tento tip a zobrazí sa ďalší",
+		"to close notifications, click anywhere except buttons in notification":                 "oznámenie zavriete kliknutím kamkoľvek okrem tlačidiel v oznámení",
+		"to quick copy game to clipboard, click on last move piece":                             "partiu rýchlo skopírujete do schránky kliknutím na figúrku posledného ťahu",
+		"to go back a move, click on last move FROM square":                                     "o ťah späť sa vrátite kliknutím na východiskové pole posledného ťahu",
+		"to rotate board (if supported), click on corners with arrows (bottom-left, top-right)": "šachovnicu otočíte (ak je to podporované) kliknutím na rohy so šípkami (vľavo dole, vpravo hore)",
+		"to rotate board for current moving player, click on game status icon, or text":         "šachovnicu otočíte k hráčovi na ťahu kliknutím na ikonu alebo text stavu partie",
+		"to toggle this game URL dialog, click on any empty square on board":                    "tento dialóg s URL partie zobrazíte alebo skryjete kliknutím na ľubovoľné prázdne pole",
+		"to toggle zen mode, try double click on empty chess square":                            "zen režim prepnete dvojklikom na prázdne pole",
+		"to move a piece, you can also drag it to the destination square":                       "figúrku môžete presunúť aj potiahnutím na cieľové pole",
+		"to see keyboard shortcuts, press ? key":                                                "klávesové skratky zobrazíte stlačením klávesu ?",
+		"to make a move with keyboard, press / key and type the move (e.g. Nf3 or e2e4)":        "ťah klávesnicou zadáte stlačením klávesu / a napísaním ťahu (napr. Jf3 alebo e2e4)",
+
+		// Keyboard shortcuts.
+		"previous / next move":                   "predchádzajúci / nasledujúci ťah",
+		"go to game start":                       "na začiatok partie",
+		"go to last move":                        "na posledný ťah",
+		"cancel move, close dialogs":             "zrušiť ťah, zavrieť dialógy",
+		"type a move (e.g. Nf3, e2e4)":           "napísať ťah (napr. Jf3, e2e4)",
+		"focus board, then use arrows and Enter": "prejsť na šachovnicu, potom šípky a Enter",
+		"rotate board":                           "otočiť šachovnicu",
+		"toggle high contrast markers":           "prepnúť kontrastné značky",
+		"show keyboard shortcuts":                "zobraziť klávesové skratky",
+
+		// Game status.
+		"White player is on the move":   "Na ťahu je biely",
+		"Black player is on the move":   "Na ťahu je čierny",
+		"In progress":                   "Prebieha",
+		"White checkmated Black":        "Biely dal mat čiernemu",
+		"Black checkmated White":        "Čierny dal mat bielemu",
+		"Black ran out of time":         "Čiernemu vypršal čas",
+		"White ran out of time":         "Bielemu vypršal čas",
+		"Black resigned":                "Čierny sa vzdal",
+		"White resigned":                "Biely sa vzdal",
+		"Black made an illegal move":    "Čierny urobil neplatný ťah",
+		"White made an illegal move":    "Biely urobil neplatný ťah",
+		"Draw by threefold repetition":  "Remíza trojnásobným opakovaním pozície",
+		"Draw by Fifty move rule":       "Remíza podľa pravidla 50 ťahov",
+		"Draw by stalemate":             "Remíza patom",
+		"Draw by insufficient material": "Remíza pre nedostatok materiálu",
+		"White won":                     "Biely vyhral",
+		"Black won":                     "Čierny vyhral",
+		"Draw":                          "Remíza",
+
+		// Screen reader texts.
+		"White":                  "Biely",
+		"Black":                  "Čierny",
+		"king":                   "kráľ",
+		"queen":                  "dáma",
+		"rook":                   "veža",
+		"bishop":                 "strelec",
+		"knight":                 "jazdec",
+		"pawn":                   "pešiak",
+		"white %s":               "%s bieleho",
+		"black %s":               "%s čierneho",
+		"empty":                  "prázdne",
+		"takes":                  "berie",
+		"castles kingside":       "malá rošáda",
+		"castles queenside":      "veľká rošáda",
+		" promotes to %s":        " sa mení na %s",
+		", check":                ", šach",
+		", checkmate":            ", mat",
+		", last move":            ", posledný ťah",
+		", selected":             ", vybraté",
+		", possible move":        ", možný ťah",
+		", current position":     ", aktuálna pozícia",
+		", not played":           ", neodohrané",
+		"New game":               "Nová partia",
+		"White plays %s":         "Biely hrá %s",
+		"Black plays %s":         "Čierny hrá %s",
+		"White to move":          "Na ťahu biely",
+		"Black to move":          "Na ťahu čierny",
+		"promote to %s":          "premeniť na %s",
+		"Choose promotion piece": "Vyberte figúrku na premenu",
+		"Chess board, use arrow keys to move between squares and Enter to select": "Šachovnica, medzi poľami sa pohybujte šípkami a vyberte klávesom Enter",
+		"Rotate board":                        "Otočiť šachovnicu",
+		"Rotate board for player on the move": "Otočiť šachovnicu k hráčovi na ťahu",
+		"Game link":                           "Odkaz na partiu",
+		"Export game":                         "Exportovať partiu",
+		"Notification":                        "Oznámenie",
+		"Conditional reply":                   "Podmienená odpoveď",
+		"Conditional replies":                 "Podmienené odpovede",
+		"attach to link":                      "pripojiť k odkazu",
+		"e.g. Nxe5 Nxe5":                      "napr. Jxe5 Jxe5",
+		"Conditional replies: if your opponent plays the first move of a line, your reply is played automatically. One line per condition, your opponent will not see them.": "Podmienené odpovede: ak súper zahrá prvý ťah riadku, vaša odpoveď sa zahrá automaticky. Jeden riadok na podmienku, súper ich neuvidí.",
+		"Conditional reply lines attached to link: %s":                                  "Podmienené odpovede pripojené k odkazu: %s",
+		"Conditional replies can be added only to the last move of a running game":      "Podmienené odpovede možno pridať iba k poslednému ťahu prebiehajúcej partie",
+		"promotion piece is missing in \"%s\"":                                          "chýba figúrka premeny v \"%s\"",
+		"line \"%s\" has to end with your reply":                                        "riadok \"%s\" musí končiť vašou odpoveďou",
+		"Your opponent prepared reply %s to your move %s, it was played automatically.": "Súper pripravil odpoveď %s na váš ťah %s, bola zahraná automaticky.",
+		"Your conditional reply %s to move %s was played automatically.":                "Vaša podmienená odpoveď %s na ťah %s bola zahraná automaticky.",
+		"Play replay":         "Prehrať partiu",
+		"Pause replay":        "Pozastaviť prehrávanie",
+		"Replay position":     "Pozícia prehrávania",
+		"Replay speed":        "Rýchlosť prehrávania",
+		"half-move %s of %s":  "polťah %s z %s",
+		"play / pause replay": "prehrať / pozastaviť partiu",
+		"Downloading files is not supported by this browser": "Sťahovanie súborov tento prehliadač nepodporuje",
+		"copy FEN":                             "kopírovať FEN",
+		"Board diagram":                        "Diagram šachovnice",
+		"flip diagram":                         "otočiť diagram",
+		"copy SVG":                             "kopírovať SVG",
+		"download diagram":                     "stiahnuť diagram",
+		"Position FEN was copied to clipboard": "FEN pozície bol skopírovaný do schránky",
+		"Diagram SVG was copied to clipboard":  "SVG diagramu bolo skopírované do schránky",
+		"white at bottom":                      "biely dole",
+		"black at bottom":                      "čierny dole",
+		"download GIF":                         "stiahnuť GIF",
+		"Frame delay":                          "Oneskorenie snímky",
+		"Event":                                "Podujatie",
+		"Site":                                 "Miesto",
+		"Termination":                          "Ukončenie",
+		"White Elo":                            "Elo bieleho",
+		"Black Elo":                            "Elo čierneho",
+		"White title":                          "Titul bieleho",
+		"Black title":                          "Titul čierneho",
+		"White team":                           "Tím bieleho",
+		"Black team":                           "Tím čierneho",
+		"White FIDE ID":                        "FIDE ID bieleho",
+		"Black FIDE ID":                        "FIDE ID čierneho",
+		"Time control":                         "Tempo hry",
+		"Board no.":                            "Šachovnica č.",
+		"Annotator":                            "Komentátor",
+		"Date has to be in YYYY.MM.DD format, use ?? for unknown parts": "Dátum musí byť vo formáte RRRR.MM.DD, pre neznáme časti použite ??",
+		"Month has to be from 01 to 12":                                 "Mesiac musí byť od 01 do 12",
+		"Day has to be from 01 to 31":                                   "Deň musí byť od 01 do 31",
+		"Round has to be a number, like 3 or 3.1":                       "Kolo musí byť číslo, napr. 3 alebo 3.1",
+		"Value has to be a number":                                      "Hodnota musí byť číslo",
+		"Time control has to be in seconds, like 300+2 or 40/7200:3600": "Tempo hry musí byť v sekundách, napr. 300+2 alebo 40/7200:3600",
+		"Tag name can contain only letters, digits and underscores":     "Názov tagu môže obsahovať iba písmená, číslice a podčiarkovníky",
+		"Tag is already in the list":                                    "Tag už je v zozname",
+		"remove tag":                                                    "odstrániť tag",
+		"Tag name":                                                      "Názov tagu",
+		"add tag":                                                       "pridať tag",
+		"download PGN":                                                  "stiahnuť PGN",
+		"download FEN":                                                  "stiahnuť FEN",
+		"short algebraic (SAN)":                                         "krátka algebraická (SAN)",
+		"long algebraic":                                                "dlhá algebraická",
+		"Notation":                                                      "Notácia",
+		"whole game":                                                    "celá partia",
+		"up to current move":                                            "po aktuálny ťah",
+		"Moves":                                                         "Ťahy",
+		"wrap lines at 80 columns":                                      "zalomiť riadky na 80 znakoch",
+		"include tags":                                                  "vrátane tagov",
+		"Something went wrong":                                          "Niečo sa pokazilo",
+		"Invalid game link":                                             "Neplatný odkaz na partiu",
+		"Browser history":                                               "História prehliadača",
+		"Entry for every move":                                          "Záznam pre každý ťah",
+		"Entry for every move and navigation":                           "Záznam pre každý ťah a navigáciu",
+		"One entry per game":                                            "Jeden záznam na partiu",
+	},
+}
+
+// Localized SAN piece letters for king, queen, rook, bishop & knight.
+var sanPieceLetters = map[string]map[byte]byte{
+	"de": {'K': 'K', 'Q': 'D', 'R': 'T', 'B': 'L', 'N': 'S'},
+	"sk": {'K': 'K', 'Q': 'D', 'R': 'V', 'B': 'S', 'N': 'J'},
+}
+
+// Returns msg translated to current language, with "%s" placeholders replaced by args in order.
+func tr(msg string, args ...string) string {
+	if translated, ok := messageCatalogue[language][msg]; ok {
+		msg = translated
+	}
+	for _, arg := range args {
+		msg = strings.Replace(msg, "%s", arg, 1)
+	}
+	return msg
+}
+
+// Returns name of language with code, or the code itself, if language is not known.
+func languageName(code string) string {
+	for _, l := range languages {
+		if l[0] == code {
+			return l[1]
+		}
+	}
+	return code
+}
+
+// Returns supported language code matching the browser's preferred language, or the default language.
+func browserLanguage() string {
+	navigator := js.Global().Get("navigator")
+	if js.IsUndefined(navigator) {
+		return languages[0][0]
+	}
+	lang := navigator.Get("language")
+	if js.IsUndefined(lang) || js.IsNull(lang) {
+		return languages[0][0]
+	}
+
+	// Strip region, e.g. "de-AT" -> "de".
+	code := strings.ToLower(strings.SplitN(lang.String(), "-", 2)[0])
+	for _, l := range languages {
+		if l[0] == code {
+			return code
+		}
+	}
+	return languages[0][0]
+}
+
+// Sets user interface language. Empty or unknown code means language preferred by the browser.
+// Needs to be set before the model is created, cause texts are set on element creation.
+func setLanguage(code string) {
+	language = browserLanguage()
+	for _, l := range languages {
+		if l[0] == code {
+			language = code
+		}
+	}
+
+	if html := js.Global().Get("document").Get("documentElement"); !js.IsUndefined(html) && !js.IsNull(html) {
+		html.Call("setAttribute", "lang", language)
+	}
+}
+
+// Returns SAN move with piece letters in current language, if localized SAN is enabled.
+func localizeSAN(san string) string {
+	letters, ok := sanPieceLetters[language]
+	if !localizedSAN || !ok {
+		return san
+	}
+	res := []byte(san)
+	for i, c := range res {
+		if l, ok := letters[c]; ok {
+			res[i] = l
+		}
+	}
+	return string(res)
+}
+
+// Returns SAN move typed with current language piece letters converted to english ones.
+// English piece letters, which are not used by current language, are left untouched.
+func delocalizeSAN(san string) string {
+	letters, ok := sanPieceLetters[language]
+	if !ok {
+		return san
+	}
+	res := []byte(san)
+	for i, c := range res {
+		for english, localized := range letters {
+			if c == localized {
+				res[i] = english
+				break
+			}
+		}
+	}
+	return string(res)
+}
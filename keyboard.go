@@ -0,0 +1,349 @@
+package main
+
+import (
+	"URLchess/shf"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position"
+	"github.com/andrewbackes/chess/position/move"
+	"github.com/andrewbackes/chess/position/square"
+)
+
+// Keyboard shortcuts with their descriptions, as shown in the cheat sheet.
+var keyboardShortcuts = [][2]string{
+	{"← →", "previous / next move"},
+	{"Home ↑", "go to game start"},
+	{"End ↓", "go to last move"},
+	{"p", "play / pause replay"},
+	{"Esc", "cancel move, close dialogs"},
+	{"/ m", "type a move (e.g. Nf3, e2e4)"},
+	{"b", "focus board, then use arrows and Enter"},
+	{"r", "rotate board"},
+	{"h", "toggle high contrast markers"},
+	{"s", "open settings"},
+	{"?", "show keyboard shortcuts"},
+}
+
+type StatusMoveInput struct {
+	shf.Element
+	Input    shf.Element
+	Position *position.Position
+	Disabled bool
+	Invalid  bool
+
+	datalist      shf.Element
+	options       []shf.Element
+	shownPosition *position.Position
+}
+
+func (this *StatusMoveInput) Init(tools *shf.Tools) error {
+	if this.datalist == nil {
+		this.datalist = tools.CreateElement("datalist")
+		this.datalist.Set("id", "game-status-move-input-moves")
+	}
+
+	if this.Input == nil {
+		this.Input = tools.CreateElement("input")
+		this.Input.Set("type", "text")
+		this.Input.Call("setAttribute", "list", "game-status-move-input-moves")
+		this.Input.Call("setAttribute", "placeholder", tr("type move, e.g. Nf3 or e2e4"))
+		this.Input.Call("setAttribute", "autocomplete", "off")
+		this.Input.Call("setAttribute", "autocapitalize", "off")
+		this.Input.Call("setAttribute", "spellcheck", "false")
+	}
+
+	if this.Element == nil {
+		this.Element = tools.CreateElement("div")
+		this.Set("id", "game-status-move-input")
+		this.Call("appendChild", this.Input.Object())
+		this.Call("appendChild", this.datalist.Object())
+	}
+	return nil
+}
+func (this *StatusMoveInput) Update(tools *shf.Tools) error {
+	if this == nil {
+		return errors.New("StatusMoveInput is nil")
+	}
+
+	if this.shownPosition != this.Position {
+		// Position changed, refill autocompletion with legal moves.
+		tools.Destroy(this.options...)
+		this.options = nil
+		if this.Position != nil {
+			for _, san := range legalMovesSAN(this.Position) {
+				option := tools.CreateElement("option")
+				option.Set("value", localizeSAN(san))
+				this.datalist.Call("appendChild", option.Object())
+				this.options = append(this.options, option)
+			}
+		}
+		this.shownPosition = this.Position
+	}
+
+	if this.Disabled {
+		this.Input.Call("setAttribute", "disabled", "disabled")
+	} else {
+		this.Input.Call("removeAttribute", "disabled")
+	}
+	if this.Invalid {
+		this.Input.Get("classList").Call("add", "invalid")
+	} else {
+		this.Input.Get("classList").Call("remove", "invalid")
+	}
+	return nil
+}
+
+// Returns SAN representations of all legal moves in position p, sorted alphabetically.
+func legalMovesSAN(p *position.Position) []string {
+	res := []string{}
+	for m := range p.LegalMoves() {
+		if san := p.SAN(m); san != "" {
+			res = append(res, san)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Regexp explanation:                      (  source  )    (   dest   )  (  promotion  )
+var regexpTypedUCI = regexp.MustCompile("^([a-h][1-8])-?([a-h][1-8])=?([qrbnQRBN]?)$")
+
+var typedPromotionCharToPiece = map[string]piece.Type{
+	"q": piece.Queen,
+	"r": piece.Rook,
+	"b": piece.Bishop,
+	"n": piece.Knight,
+}
+
+// Strips check, mate and annotation suffixes from SAN and unifies castling notation.
+func normalizeSAN(san string) string {
+	san = strings.TrimRight(strings.TrimSpace(san), "+#!?")
+	switch san {
+	case "0-0", "o-o":
+		return "O-O"
+	case "0-0-0", "o-o-o":
+		return "O-O-O"
+	}
+	return san
+}
+
+// Returns SAN of a piece move without the source file or rank, which disambiguates it, e.g. "Nc3" for "Nbc3". Other moves are returned unchanged.
+func undisambiguatedSAN(san string) string {
+	if len(san) < 4 || !strings.ContainsRune("KQRBN", rune(san[0])) {
+		return san
+	}
+	dest, capture := san[len(san)-2:], ""
+	source := san[1 : len(san)-2]
+	if strings.HasSuffix(source, "x") {
+		source, capture = strings.TrimSuffix(source, "x"), "x"
+	}
+	if source == "" {
+		return san
+	}
+	return san[:1] + capture + dest
+}
+
+// Parses a move typed by user in SAN (e.g. "Nf3", "exd5", "O-O", "e8=Q") or UCI (e.g. "e2e4", "e7e8q") notation and returns the move from legal moves.
+// SAN piece letters of current language (e.g. "Sf3" in German) are accepted too.
+// If a promotion move is typed without the promotion piece, the returned move has no promotion piece, so the user can be asked for it.
+func parseTypedMove(legal *legalMoves, text string) (move.Move, error) {
+	typed := normalizeSAN(delocalizeSAN(text))
+	if typed == "" {
+		return move.Null, errors.New(tr("no move typed"))
+	}
+	notLegal := errors.New(tr("\"%s\" is not a legal move", strings.TrimSpace(text)))
+
+	if matches := regexpTypedUCI.FindStringSubmatch(typed); matches != nil {
+		m := move.Move{
+			Source:      square.Parse(matches[1]),
+			Destination: square.Parse(matches[2]),
+			Promote:     piece.None,
+		}
+		if matches[3] != "" {
+			m.Promote = typedPromotionCharToPiece[strings.ToLower(matches[3])]
+		}
+		if legal.isLegalMove(m) {
+			return m, nil
+		}
+		if m.Promote == piece.None && legal.isLegalMoveFromTo(m.From(), m.To()) {
+			// Promotion piece is missing.
+			return m, nil
+		}
+		return move.Null, notLegal
+	}
+
+	// Moves typed incompletely, without promotion piece or without the source square of a piece move.
+	incomplete := []move.Move{}
+	for m := range legal.moves {
+		san := normalizeSAN(legal.position.SAN(m))
+		if san == typed {
+			return m, nil
+		}
+		if strings.HasPrefix(san, typed+"=") || undisambiguatedSAN(san) == typed {
+			incomplete = append(incomplete, m)
+		}
+	}
+	if len(incomplete) > 0 {
+		// Only the promotion piece may be missing, the from & to squares have to be unambiguous.
+		m := incomplete[0]
+		for _, im := range incomplete[1:] {
+			if im.Source != m.Source || im.Destination != m.Destination {
+				return move.Null, errors.New(tr("\"%s\" is ambiguous", strings.TrimSpace(text)))
+			}
+		}
+		m.Promote = piece.None
+		return m, nil
+	}
+	return move.Null, notLegal
+}
+
+// Sets the typed move as next move, if it is legal in current game position.
+func (m *Model) submitTypedMove(tools *shf.Tools, text string) error {
+	mi := m.Html.Cover.GameStatus.MoveInput
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	if st := m.ChessGame.Game.Status(); st != game.InProgress {
+		m.Html.Notification.TimedMessage(tools, 3*time.Second, tr("Game ended, no more moves can be made"), "")
+		return tools.MarkDirty(m.Html.Notification)
+	}
+
+	position := m.ChessGame.Game.Positions[m.ChessGame.currMoveNo]
+	legal := m.ChessGame.legalMoves(position)
+	nextMove, err := parseTypedMove(legal, text)
+	if err != nil {
+		mi.Invalid = true
+		m.Html.Notification.TimedMessage(tools, 3*time.Second, err.Error(), tr("tip: press ? to see keyboard shortcuts"))
+		return tools.MarkDirty(mi, m.Html.Notification)
+	}
+
+	nextMoveState, err := legal.nextMoveState(nextMove)
+	if err != nil {
+		return err
+	}
+
+	mi.Invalid = false
+	mi.Input.Set("value", "")
+	mi.Input.Call("blur")
+
+	m.ChessGame.nextMove = nextMove
+	if nextMoveState == NMLegalMove {
+		m.Html.Cover.MoveStatus.Shown = true
+	} else if nextMoveState == NMWaitPromote {
+		m.Html.Board.PromotionOverlay.Shown = true
+	}
+	return tools.MarkDirty(m.Html.game)
+}
+
+// Resets the next move and closes all dialogs & overlays.
+func (m *Model) cancelNextMove() {
+	m.ChessGame.nextMove = move.Null
+	m.drag = nil
+	m.Html.DragPiece.Shown = false
+	m.Html.DragPiece.From, m.Html.DragPiece.Over = square.NoSquare, square.NoSquare
+	m.Html.Board.PromotionOverlay.Shown = false
+	m.Html.Cover.MoveStatus.Shown = false
+	m.Html.Notification.timer.Cancel()
+	m.Html.Notification.Shown = false
+	m.Html.Settings.Shown = false
+	if m.Html.Export.Shown {
+		m.Html.Export.Shown = false
+		m.Html.Export.Output.PGN = nil
+	}
+}
+
+func (m *Model) showKeyboardShortcuts(tools *shf.Tools) {
+	list := tools.CreateElement("div")
+	list.Get("classList").Call("add", "shortcuts")
+	for _, shortcut := range keyboardShortcuts {
+		key := tools.CreateElement("kbd")
+		key.Set("textContent", shortcut[0])
+
+		p := tools.CreateElement("p")
+		p.Call("appendChild", key.Object())
+		p.Call("appendChild", tools.CreateTextNode(tr(shortcut[1])))
+		list.Call("appendChild", p.Object())
+	}
+	m.Html.Notification.Message(
+		tools,
+		tr("Keyboard shortcuts"),
+		tr("tip: click anywhere outside to close this notification"),
+		list,
+	)
+	m.Html.Notification.Own(list)
+}
+
+// Handles key presses in the whole document.
+func (m *Model) keyDown(tools *shf.Tools, e shf.KeyboardEvent) error {
+	if e.AltKey() || e.CtrlKey() || e.MetaKey() {
+		// Leave shortcuts with modifiers to the browser.
+		return nil
+	}
+
+	switch e.Get("target").Get("tagName").String() {
+	case "INPUT", "TEXTAREA", "SELECT":
+		// User is typing somewhere.
+		return nil
+	}
+
+	// Components changed by the shortcut.
+	var dirty []shf.Updater
+	switch e.Key() {
+	case "ArrowLeft":
+		m.Html.Cover.GameStatus.Control.Previous.Press()
+		dirty = append(dirty, m.Html.Cover.GameStatus.Control)
+	case "ArrowRight":
+		m.Html.Cover.GameStatus.Control.Next.Press()
+		dirty = append(dirty, m.Html.Cover.GameStatus.Control)
+	case "Home", "ArrowUp":
+		m.Html.Cover.GameStatus.Control.Start.Press()
+		dirty = append(dirty, m.Html.Cover.GameStatus.Control)
+	case "End", "ArrowDown":
+		m.Html.Cover.GameStatus.Control.Initial.Press()
+		dirty = append(dirty, m.Html.Cover.GameStatus.Control)
+	case "p":
+		if m.Html.Cover.GameStatus.Replay.Playing {
+			m.stopReplay()
+		} else if err := m.startReplay(tools); err != nil {
+			return err
+		}
+		dirty = append(dirty, m.Html.game)
+	case "Escape", "Esc":
+		m.cancelNextMove()
+		dirty = append(dirty, m.Html.game, m.Html.Settings, m.Html.Export)
+	case "/", "m":
+		m.Html.Cover.MoveStatus.Shown = false
+		m.Html.Cover.GameStatus.MoveInput.Input.Call("focus")
+		dirty = append(dirty, m.Html.Cover.MoveStatus)
+	case "b":
+		if err := m.Html.Board.Grid.Focus(tools, m.Html.Board.Grid.Focused); err != nil {
+			return err
+		}
+		dirty = append(dirty, m.Html.Board)
+	case "r":
+		m.RotateBoard()
+		dirty = append(dirty, m.Html.game)
+	case "h":
+		m.Settings.HighContrast.Set(!m.Settings.HighContrast.Get())
+		dirty = append(dirty, m.Html.Settings)
+	case "s":
+		m.Html.Notification.Shown = false
+		m.Html.Settings.Shown = true
+		dirty = append(dirty, m.Html.Notification, m.Html.Settings)
+	case "?":
+		m.showKeyboardShortcuts(tools)
+		dirty = append(dirty, m.Html.Notification)
+	default:
+		return nil
+	}
+
+	e.Call("preventDefault")
+	return tools.MarkDirty(dirty...)
+}
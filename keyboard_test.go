@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewbackes/chess/fen"
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position/move"
+	"github.com/andrewbackes/chess/position/square"
+)
+
+func TestParseTypedMove(t *testing.T) {
+	const (
+		initial   = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+		castling  = "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+		promotion = "1n2k3/P1P5/8/8/8/8/8/4K3 w - - 0 1"
+		knights   = "4k3/8/8/8/8/8/8/1N1NK3 w - - 0 1"
+	)
+	mv := func(uci string, promote piece.Type) move.Move {
+		return move.Move{Source: square.Parse(uci[:2]), Destination: square.Parse(uci[2:]), Promote: promote}
+	}
+	for _, tc := range []struct {
+		fen, language, text string
+		want                 move.Move
+		// Part of expected error message, empty if no error is expected.
+		err string
+	}{
+		{initial, "en", "e4", mv("e2e4", piece.None), ""},
+		{initial, "en", " Nf3 ", mv("g1f3", piece.None), ""},
+		{initial, "en", "Nf3!?", mv("g1f3", piece.None), ""},
+		{initial, "en", "e2e4", mv("e2e4", piece.None), ""},
+		{initial, "en", "g1-f3", mv("g1f3", piece.None), ""},
+		{initial, "en", "e5", move.Null, "not a legal move"},
+		{initial, "en", "e2e5", move.Null, "not a legal move"},
+		{initial, "en", "Nf4", move.Null, "not a legal move"},
+		{initial, "en", "", move.Null, "no move typed"},
+		{initial, "en", "  ", move.Null, "no move typed"},
+		{castling, "en", "O-O", mv("e1g1", piece.None), ""},
+		{castling, "en", "0-0", mv("e1g1", piece.None), ""},
+		{castling, "en", "o-o", mv("e1g1", piece.None), ""},
+		{castling, "en", "0-0-0", mv("e1c1", piece.None), ""},
+		{castling, "en", "e1c1", mv("e1c1", piece.None), ""},
+		{castling, "en", "Rxa8+", mv("a1a8", piece.None), ""},
+		{promotion, "en", "a8=Q", mv("a7a8", piece.Queen), ""},
+		{promotion, "en", "c8=N", mv("c7c8", piece.Knight), ""},
+		{promotion, "en", "c8=Q+", mv("c7c8", piece.Queen), ""},
+		{promotion, "en", "axb8=R", mv("a7b8", piece.Rook), ""},
+		{promotion, "en", "a7a8q", mv("a7a8", piece.Queen), ""},
+		{promotion, "en", "c7c8=B", mv("c7c8", piece.Bishop), ""},
+		// Missing promotion piece, the player is asked for it.
+		{promotion, "en", "a8", mv("a7a8", piece.None), ""},
+		{promotion, "en", "cxb8", mv("c7b8", piece.None), ""},
+		{promotion, "en", "a7a8", mv("a7a8", piece.None), ""},
+		{promotion, "en", "a7b7", move.Null, "not a legal move"},
+		{knights, "en", "Nbc3", mv("b1c3", piece.None), ""},
+		{knights, "en", "b1c3", mv("b1c3", piece.None), ""},
+		{knights, "en", "Nc3", move.Null, "ambiguous"},
+		{knights, "en", "Nd2", mv("b1d2", piece.None), ""},
+		{initial, "de", "Sf3", mv("g1f3", piece.None), ""},
+		{initial, "de", "Nf3", mv("g1f3", piece.None), ""},
+		{initial, "sk", "Jc3", mv("b1c3", piece.None), ""},
+		{promotion, "de", "a8=D", mv("a7a8", piece.Queen), ""},
+		{promotion, "sk", "c8=J", mv("c7c8", piece.Knight), ""},
+	} {
+		p, err := fen.Decode(tc.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		language = tc.language
+		got, err := parseTypedMove(newLegalMoves(p), tc.text)
+		language = "en"
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s %q: error %v, want %q", tc.fen, tc.text, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %q: %s", tc.fen, tc.text, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s %q: move %s, want %s", tc.fen, tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestUndisambiguatedSAN(t *testing.T) {
+	for san, want := range map[string]string{
+		"Nbc3":  "Nc3",
+		"Nbxc3": "Nxc3",
+		"R1a3":  "Ra3",
+		"Qh4e1": "Qe1",
+		"Nf3":   "Nf3",
+		"Nxf3":  "Nxf3",
+		"exd5":  "exd5",
+		"O-O-O": "O-O-O",
+		"a8=Q":  "a8=Q",
+	} {
+		if got := undisambiguatedSAN(san); got != want {
+			t.Errorf("undisambiguatedSAN(%q) = %q, want %q", san, got, want)
+		}
+	}
+}
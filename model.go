@@ -3,6 +3,7 @@ package main
 import (
 	"URLchess/shf"
 	"URLchess/shf/js"
+	"URLchess/urlchess"
 	"errors"
 	"strconv"
 	"strings"
@@ -65,7 +66,7 @@ func (f *ModelFooter) Init(tools *shf.Tools) error {
 		}
 
 		f.Call("appendChild", linkURLchess.Object())
-		f.Call("appendChild", tools.CreateTextNode(" v"+Version+engine+" by jEzEk. Source on "))
+		f.Call("appendChild", tools.CreateTextNode(tr(" v%s by jEzEk. Source on ", Version+engine)))
 		f.Call("appendChild", linkGit.Object())
 	}
 	return nil
@@ -268,17 +269,20 @@ type MarkersByColor struct {
 }
 
 type SquareMarkers struct {
-	ByColor [2]MarkersByColor
-	Check   bool
-	Mate    bool
+	ByColor  [2]MarkersByColor
+	Check    bool
+	Mate     bool
+	Dragged  bool
+	DragOver bool
 }
 type GridSquare struct {
 	shf.Element
-	Id      square.Square
-	Piece   piece.Piece
-	Markers SquareMarkers
-	piece   shf.Element
-	marker  shf.Element
+	Id        square.Square
+	Piece     piece.Piece
+	Markers   SquareMarkers
+	Focusable bool
+	piece     shf.Element
+	marker    shf.Element
 }
 
 func (s *GridSquare) Init(tools *shf.Tools) error {
@@ -295,6 +299,8 @@ func (s *GridSquare) Init(tools *shf.Tools) error {
 		s.Element = tools.CreateElement("div")
 		s.Set("id", s.Id.String())
 		s.Get("classList").Call("add", boardGridSquareTones[(int(s.Id)%8+int(s.Id)/8)%2])
+		s.Call("setAttribute", "role", "button")
+		s.Call("setAttribute", "tabindex", "-1")
 
 		s.Call("appendChild", s.marker.Object())
 	}
@@ -345,15 +351,22 @@ func (s *GridSquare) Update(tools *shf.Tools) error {
 			s.marker.Get("classList").Call("add", "check")
 		}
 	}
-
-	s.piece.Set("className", "piece")
-	if s.Piece.Color != piece.NoColor {
-		s.piece.Get("classList").Call("add", strings.ToLower(s.Piece.Color.String()))
+	if s.Markers.Dragged {
+		s.marker.Get("classList").Call("add", "dragged")
+	}
+	if s.Markers.DragOver {
+		s.marker.Get("classList").Call("add", "drag-over")
 	}
-	if s.Piece.Type != piece.None {
-		s.piece.Get("classList").Call("add", pieceTypesToName[s.Piece.Type])
+
+	renderPiece(s.piece.Object(), s.Piece)
+	s.piece.Call("setAttribute", "aria-hidden", "true")
+
+	s.Call("setAttribute", "aria-label", s.ariaLabel())
+	if s.Focusable {
+		s.Call("setAttribute", "tabindex", "0")
+	} else {
+		s.Call("setAttribute", "tabindex", "-1")
 	}
-	s.piece.Set("textContent", s.Piece.Figurine())
 
 	return nil
 }
@@ -361,9 +374,33 @@ func (s *GridSquare) Update(tools *shf.Tools) error {
 type BoardGrid struct {
 	shf.Element
 	Squares [64]*GridSquare
+	Focused square.Square
+}
+
+// Focus moves keyboard focus to the square. Only the focused square is reachable by tab key.
+func (g *BoardGrid) Focus(tools *shf.Tools, sq square.Square) error {
+	if sq > square.LastSquare {
+		return errors.New("BoardGrid.Focus: invalid square " + strconv.Itoa(int(sq)))
+	}
+	if g.Focused != sq {
+		g.Squares[int(g.Focused)].Focusable = false
+		if err := tools.Update(g.Squares[int(g.Focused)]); err != nil {
+			return err
+		}
+		g.Focused = sq
+	}
+	g.Squares[int(sq)].Focusable = true
+	if err := tools.Update(g.Squares[int(sq)]); err != nil {
+		return err
+	}
+	g.Squares[int(sq)].Call("focus")
+	return nil
 }
 
 func (g *BoardGrid) Init(tools *shf.Tools) error {
+	if g.Element == nil {
+		g.Focused = square.A1
+	}
 	for i, sq := range g.Squares {
 		if sq == nil {
 			g.Squares[i] = &GridSquare{
@@ -378,6 +415,9 @@ func (g *BoardGrid) Init(tools *shf.Tools) error {
 	if g.Element == nil {
 		g.Element = tools.CreateElement("div")
 		g.Get("classList").Call("add", "grid")
+		g.Call("setAttribute", "role", "group")
+		g.Call("setAttribute", "aria-label", tr("Chess board, use arrow keys to move between squares and Enter to select"))
+		g.Squares[int(g.Focused)].Focusable = true
 		for i := int(63); i >= 0; i-- {
 			if g.Squares[i].Element != nil {
 				g.Call("appendChild", g.Squares[i].Element.Object())
@@ -411,9 +451,7 @@ func (p *PromotionPiece) RedrawElement(tools *shf.Tools) {
 		return
 	}
 
-	tools.Destroy(p.PieceElement)
-	//TODO jezek - Look for other places where to destroy element (innerHTML, ...).
-	p.Element.Set("innerHTML", "")
+	tools.DestroyChildren(p)
 
 	p.PieceElement = pieceElement(tools, p.Piece)
 	p.Element.Call("appendChild", p.PieceElement.Object())
@@ -422,6 +460,9 @@ func (p *PromotionPiece) Init(tools *shf.Tools) error {
 	if p.Element == nil {
 		p.Element = tools.CreateElement("span")
 		p.Element.Set("id", "promote-to-"+pieceTypesToName[p.Piece.Type])
+		p.Element.Call("setAttribute", "role", "button")
+		p.Element.Call("setAttribute", "tabindex", "0")
+		p.Element.Call("setAttribute", "aria-label", tr("promote to %s", tr(pieceTypesToName[p.Piece.Type])))
 
 		//TODO jezek - Test if this can be deleted.
 		//p.RedrawElement(tools)
@@ -441,6 +482,8 @@ type BoardPromotionOverlay struct {
 	Shown  bool
 	Color  piece.Color
 	Pieces []*PromotionPiece
+
+	focus dialogFocus
 }
 
 func (p *BoardPromotionOverlay) Init(tools *shf.Tools) error {
@@ -460,6 +503,8 @@ func (p *BoardPromotionOverlay) Init(tools *shf.Tools) error {
 	if p.Element == nil {
 		p.Element = tools.CreateElement("div")
 		p.Set("id", "promotion-overlay")
+		p.Call("setAttribute", "role", "dialog")
+		p.Call("setAttribute", "aria-label", tr("Choose promotion piece"))
 
 		for _, piece := range p.Pieces {
 			p.Element.Call("appendChild", piece.Object())
@@ -481,9 +526,18 @@ func (p *BoardPromotionOverlay) Update(tools *shf.Tools) error {
 			}
 		}
 		p.Get("classList").Call("add", "show")
+		p.Call("setAttribute", "aria-hidden", "false")
 	} else {
 		p.Get("classList").Call("remove", "show")
+		p.Call("setAttribute", "aria-hidden", "true")
+	}
+
+	// Focus queen, the most usual promotion choice.
+	var focus shf.Element
+	if len(p.Pieces) > 0 {
+		focus = p.Pieces[len(p.Pieces)-1].Element
 	}
+	p.focus.update(p.Shown, focus)
 
 	return nil
 }
@@ -726,7 +780,7 @@ func (sm *StatusIcons) Update(tools *shf.Tools) error {
 		return errors.New("StatusIcons is nil")
 	}
 
-	sm.Set("innerHTML", "")
+	tools.DestroyChildren(sm)
 	if sm.White {
 		sm.Call("appendChild", pieceElement(tools, piece.New(piece.White, piece.King)).Object())
 	}
@@ -799,8 +853,11 @@ func (gs *StatusHeader) Update(tools *shf.Tools) error {
 type ControlButton struct {
 	shf.Element
 	Text     string
+	Label    string // accessible label for buttons showing only an icon
 	Hash     string
 	Disabled bool
+
+	refNavigator gameNavigator
 }
 
 func (cb *ControlButton) Init(tools *shf.Tools) error {
@@ -809,9 +866,7 @@ func (cb *ControlButton) Init(tools *shf.Tools) error {
 		cb.Element = tools.CreateElement("button")
 
 		if err := tools.Click(cb.Element, func(e shf.Event) error {
-			if !cb.Disabled {
-				js.Global().Get("location").Set("hash", cb.Hash)
-			}
+			cb.Press()
 			return nil
 		}); err != nil {
 			return err
@@ -819,11 +874,24 @@ func (cb *ControlButton) Init(tools *shf.Tools) error {
 	}
 	return nil
 }
+
+// Press navigates to button's hash, if button is not disabled. Returns true if navigated.
+func (cb *ControlButton) Press() bool {
+	if cb == nil || cb.Disabled {
+		return false
+	}
+	cb.refNavigator.Browse(cb.Hash)
+	return true
+}
 func (cb *ControlButton) Update(tools *shf.Tools) error {
 	if cb == nil {
 		return errors.New("ControlButton is nil")
 	}
 	cb.Set("textContent", cb.Text)
+	if cb.Label != "" {
+		cb.Call("setAttribute", "aria-label", cb.Label)
+		cb.Call("setAttribute", "title", cb.Label)
+	}
 	if cb.Disabled {
 		cb.Call("setAttribute", "disabled", "disabled")
 	} else {
@@ -842,14 +910,14 @@ type StatusControl struct {
 
 func (sc *StatusControl) Init(tools *shf.Tools) error {
 	if sc.Start == nil {
-		sc.Start = &ControlButton{Text: ""}
+		sc.Start = &ControlButton{Text: "", Label: tr("Go to game start")}
 		if err := tools.Initialize(sc.Start); err != nil {
 			return err
 		}
 		sc.Start.Get("classList").Call("add", "start")
 	}
 	if sc.Previous == nil {
-		sc.Previous = &ControlButton{Text: ""}
+		sc.Previous = &ControlButton{Text: "", Label: tr("Previous move")}
 		if err := tools.Initialize(sc.Previous); err != nil {
 			return err
 		}
@@ -857,14 +925,14 @@ func (sc *StatusControl) Init(tools *shf.Tools) error {
 	}
 
 	if sc.Next == nil {
-		sc.Next = &ControlButton{Text: ""}
+		sc.Next = &ControlButton{Text: "", Label: tr("Next move")}
 		if err := tools.Initialize(sc.Next); err != nil {
 			return err
 		}
 		sc.Next.Get("classList").Call("add", "next")
 	}
 	if sc.Initial == nil {
-		sc.Initial = &ControlButton{Text: ""}
+		sc.Initial = &ControlButton{Text: "", Label: tr("Go to last move")}
 		if err := tools.Initialize(sc.Initial); err != nil {
 			return err
 		}
@@ -912,7 +980,7 @@ func (sc *StatusControl) rebuild(tools *shf.Tools) error {
 		sc.Start.Disabled = false
 		sc.Previous.Disabled = false
 
-		sc.Previous.Hash, err = sc.refGame.HashForHalfMove(lenCurrentMoves - 1)
+		sc.Previous.Hash, err = sc.refGame.browseHashForHalfMove(lenCurrentMoves - 1)
 		if err != nil {
 			return err
 		}
@@ -920,7 +988,7 @@ func (sc *StatusControl) rebuild(tools *shf.Tools) error {
 
 	sc.Next.Disabled = true
 	if !split && lenInitialMoves > lenCurrentMoves {
-		sc.Next.Hash, err = sc.refGame.HashForInitialHalfMove(lenCurrentMoves + 1)
+		sc.Next.Hash, err = sc.refGame.browseHashForInitialHalfMove(lenCurrentMoves + 1)
 		if err != nil {
 			return err
 		}
@@ -929,19 +997,22 @@ func (sc *StatusControl) rebuild(tools *shf.Tools) error {
 
 	sc.Initial.Disabled = true
 	if split || lenCurrentMoves != lenInitialMoves {
-		sc.Initial.Hash, err = sc.refGame.HashForInitialHalfMove(lenInitialMoves)
+		sc.Initial.Hash, err = sc.refGame.browseHashForInitialHalfMove(lenInitialMoves)
 		if err != nil {
 			return err
 		}
 		sc.Initial.Disabled = false
 		if split {
+			sc.Initial.Label = tr("Back to received game")
 			sc.Initial.Get("classList").Call("add", "initial")
 			sc.Initial.Get("classList").Call("remove", "end")
 		} else {
+			sc.Initial.Label = tr("Go to last move")
 			sc.Initial.Get("classList").Call("add", "end")
 			sc.Initial.Get("classList").Call("remove", "initial")
 		}
 	} else {
+		sc.Initial.Label = tr("Go to last move")
 		sc.Initial.Get("classList").Call("add", "end")
 		sc.Initial.Get("classList").Call("remove", "initial")
 	}
@@ -996,7 +1067,28 @@ func (sm *StatusMove) Update(tools *shf.Tools) error {
 	}
 
 	sm.Get("classList").Set("value", "move"+classColor+classClickable+classInitial+classCurrent+classFuture+classSplited)
-	sm.Set("textContent", sm.Text)
+
+	label := sm.Text
+	if sm.Color == piece.White || sm.Color == piece.Black {
+		sm.Set("textContent", localizeSAN(sm.Text))
+		label = tr(sm.Color.String()) + " " + describeSAN(strings.TrimPrefix(sm.Text, "... "))
+	} else {
+		sm.Set("textContent", sm.Text)
+	}
+	if sm.Current {
+		label += tr(", current position")
+		sm.Call("setAttribute", "aria-current", "step")
+	} else {
+		sm.Call("removeAttribute", "aria-current")
+	}
+	if sm.Future {
+		label += tr(", not played")
+	}
+	if sm.Text == "" {
+		sm.Call("setAttribute", "aria-hidden", "true")
+	} else {
+		sm.Call("setAttribute", "aria-label", label)
+	}
 	return nil
 }
 
@@ -1014,7 +1106,7 @@ type StatusMoves struct {
 func (sb *StatusMoves) Init(tools *shf.Tools) error {
 	//println("StatusMoves.Init")
 	if sb.MoveZero == nil {
-		mz, err := sb.createHalfMoveNo(tools, StatusMove{nil, "#", piece.NoColor, "New game position", false, false, false, false})
+		mz, err := sb.createHalfMoveNo(tools, StatusMove{nil, "#", piece.NoColor, tr("New game position"), false, false, false, false})
 		if err != nil {
 			return err
 		}
@@ -1037,7 +1129,9 @@ func (sb *StatusMoves) createHalfMoveNo(tools *shf.Tools, sm StatusMove) (*Statu
 		if err := tools.Click(sm.Element, func(e shf.Event) error {
 			//println("Clicked:", sm.Text)
 			e.Call("stopPropagation")
-			js.Global().Get("location").Set("hash", sm.Href)
+			// Navigate as chosen by history mode, not by the link.
+			e.Call("preventDefault")
+			sb.refGame.navigator.Browse(sm.Href)
 			return nil
 		}); err != nil {
 			return nil, err
@@ -1050,11 +1144,10 @@ func (sb *StatusMoves) createHalfMoveNo(tools *shf.Tools, sm StatusMove) (*Statu
 			sb.refModel.Notification.TimedMessage(
 				tools,
 				5*time.Second,
-				"Game URL was copied to clipboard",
+				tr("Game URL was copied to clipboard"),
 				"",
 			)
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			return tools.MarkDirty(sb.refModel.Notification)
 		}); err != nil {
 			return nil, err
 		}
@@ -1073,16 +1166,11 @@ func (sb *StatusMoves) rebuild(tools *shf.Tools) error {
 		return errors.New("StatusMoves.rebuild: Movezero is nil")
 	}
 
-	for _, mv := range sb.Moves {
-		tools.ClickRemove(mv)
-		tools.Destroy(mv)
-	}
+	// Keep move zero, destroy all other move elements with their click events.
+	sb.MoveZero.Call("remove")
+	tools.DestroyChildren(sb)
 	sb.Moves = nil
-	if sb.SplitLastMove != nil {
-		tools.ClickRemove(sb.SplitLastMove)
-		tools.Destroy(sb.SplitLastMove)
-	}
-	sb.Set("innerHTML", "")
+	sb.SplitLastMove = nil
 	sb.ScrollToCurrentMove = true
 
 	lenInitialMoves, lenCurrentMoves := len(sb.refGame.initialPgn.Moves), len(sb.refGame.pgn.Moves)
@@ -1091,6 +1179,7 @@ func (sb *StatusMoves) rebuild(tools *shf.Tools) error {
 	{ // Move zero
 		sb.MoveZero.Initial = lenInitialMoves == 0
 		sb.MoveZero.Current = lenCurrentMoves == 0
+		sb.MoveZero.Href = "#" + sb.refGame.browseHash("")
 
 		moveNo := tools.CreateElement("span")
 		moveNo.Get("classList").Call("add", "move-no")
@@ -1127,7 +1216,7 @@ func (sb *StatusMoves) rebuild(tools *shf.Tools) error {
 				moveNo.Get("classList").Call("add", "move-no")
 				moveNo.Set("textContent", strconv.Itoa(lenInitialMoves/2))
 
-				hash, err := sb.refGame.HashForInitialHalfMove(lenInitialMoves)
+				hash, err := sb.refGame.browseHashForInitialHalfMove(lenInitialMoves)
 				if err != nil {
 					return err
 				}
@@ -1166,7 +1255,7 @@ func (sb *StatusMoves) rebuild(tools *shf.Tools) error {
 		var err error
 		if future {
 			text = sb.refGame.initialPgn.Moves[i]
-			hash, err = sb.refGame.HashForInitialHalfMove(hno)
+			hash, err = sb.refGame.browseHashForInitialHalfMove(hno)
 			if err != nil {
 				return err
 			}
@@ -1174,7 +1263,7 @@ func (sb *StatusMoves) rebuild(tools *shf.Tools) error {
 			current = false
 		} else {
 			text = sb.refGame.pgn.Moves[i]
-			hash, err = sb.refGame.HashForHalfMove(hno)
+			hash, err = sb.refGame.browseHashForHalfMove(hno)
 			if err != nil {
 				return err
 			}
@@ -1262,7 +1351,7 @@ func (sb *StatusMoves) rebuild(tools *shf.Tools) error {
 			var err error
 			if future {
 				text = sb.refGame.initialPgn.Moves[j]
-				hash, err = sb.refGame.HashForInitialHalfMove(hno + 1)
+				hash, err = sb.refGame.browseHashForInitialHalfMove(hno + 1)
 				if err != nil {
 					return err
 				}
@@ -1270,7 +1359,7 @@ func (sb *StatusMoves) rebuild(tools *shf.Tools) error {
 				current = false
 			} else {
 				text = sb.refGame.pgn.Moves[j]
-				hash, err = sb.refGame.HashForHalfMove(hno + 1)
+				hash, err = sb.refGame.browseHashForHalfMove(hno + 1)
 				if err != nil {
 					return err
 				}
@@ -1340,9 +1429,11 @@ func (sb *StatusMoves) Update(tools *shf.Tools) error {
 
 type ModelGameStatus struct {
 	shf.Element
-	Header  *StatusHeader
-	Control *StatusControl
-	Moves   *StatusMoves
+	Header    *StatusHeader
+	Control   *StatusControl
+	MoveInput *StatusMoveInput
+	Replay    *StatusReplay
+	Moves     *StatusMoves
 }
 
 func (gs *ModelGameStatus) Init(tools *shf.Tools) error {
@@ -1358,6 +1449,18 @@ func (gs *ModelGameStatus) Init(tools *shf.Tools) error {
 			return err
 		}
 	}
+	if gs.MoveInput == nil {
+		gs.MoveInput = &StatusMoveInput{}
+		if err := tools.Initialize(gs.MoveInput); err != nil {
+			return err
+		}
+	}
+	if gs.Replay == nil {
+		gs.Replay = &StatusReplay{}
+		if err := tools.Initialize(gs.Replay); err != nil {
+			return err
+		}
+	}
 	if gs.Moves == nil {
 		gs.Moves = &StatusMoves{}
 		if err := tools.Initialize(gs.Moves); err != nil {
@@ -1370,6 +1473,8 @@ func (gs *ModelGameStatus) Init(tools *shf.Tools) error {
 		gs.Set("id", "game-status")
 		gs.Call("appendChild", gs.Header.Element.Object())
 		gs.Call("appendChild", gs.Control.Element.Object())
+		gs.Call("appendChild", gs.MoveInput.Element.Object())
+		gs.Call("appendChild", gs.Replay.Element.Object())
 		gs.Call("appendChild", gs.Moves.Element.Object())
 	}
 	return nil
@@ -1379,7 +1484,7 @@ func (gs *ModelGameStatus) Update(tools *shf.Tools) error {
 		return errors.New("ModelGameStatus is nil")
 	}
 
-	return tools.Update(gs.Header, gs.Control, gs.Moves)
+	return tools.Update(gs.Header, gs.Control, gs.MoveInput, gs.Replay, gs.Moves)
 }
 
 func (gs *ModelGameStatus) rebuild(tools *shf.Tools) error {
@@ -1401,7 +1506,7 @@ type CopyButton struct {
 func (this *CopyButton) Init(tools *shf.Tools) error {
 	if this.Element == nil {
 		this.Element = tools.CreateElement("button")
-		this.Set("textContent", "Copy to clipboard")
+		this.Set("textContent", tr("Copy to clipboard"))
 
 	}
 	return nil
@@ -1427,7 +1532,12 @@ type MoveStatusLink struct {
 }
 
 func (this *MoveStatusLink) GetURL() string {
-	hash := "#" + strings.TrimPrefix(this.MoveHash, "#")
+	return gameURL(this.MoveHash)
+}
+
+// Returns URL of this page with moves hash.
+func gameURL(moveHash string) string {
+	hash := "#" + strings.TrimPrefix(moveHash, "#")
 	loc := js.Global().Get("location")
 	return loc.Get("origin").String() + loc.Get("pathname").String() + hash
 }
@@ -1451,7 +1561,7 @@ func (this *MoveStatusLink) Init(tools *shf.Tools) error {
 
 		this.Call("appendChild", this.Input.Object())
 		this.Call("appendChild", this.Copy.Object())
-		this.Call("appendChild", tools.CreateTextNode("This URL link represents the state of current chess game. You can copy it and store it or send it."))
+		this.Call("appendChild", tools.CreateTextNode(tr("This URL link represents the state of current chess game. You can copy it and store it or send it.")))
 
 	}
 	return nil
@@ -1476,12 +1586,16 @@ type ModelMoveStatus struct {
 	shf.Element
 	Shown bool
 
-	Link *MoveStatusLink
+	Link       *MoveStatusLink
+	Conditions *MoveStatusConditions
 	//Navigation *MoveStatusNavigation
 	Undo  shf.Element
 	Close shf.Element
 	tip   shf.Element
-	tipNo int
+	// Next tip is shown on update. Tip changes with every interaction, which updates something, see Init.
+	nextTip bool
+
+	refSettings *Settings
 }
 
 func (this *ModelMoveStatus) Init(tools *shf.Tools) error {
@@ -1492,20 +1606,26 @@ func (this *ModelMoveStatus) Init(tools *shf.Tools) error {
 		}
 	}
 
+	if this.Conditions == nil {
+		this.Conditions = &MoveStatusConditions{}
+		if err := tools.Initialize(this.Conditions); err != nil {
+			return err
+		}
+	}
+
 	if this.Undo == nil {
 		this.Undo = tools.CreateElement("button")
-		this.Undo.Set("textContent", "back")
+		this.Undo.Set("textContent", tr("back"))
 		// Model sets Event
 		// Model.ChessGame.UpdateModel sets visibility
 	}
 
 	if this.Close == nil {
 		this.Close = tools.CreateElement("button")
-		this.Close.Set("textContent", "close")
+		this.Close.Set("textContent", tr("close"))
 		if err := tools.Click(this.Close, func(_ shf.Event) error {
 			this.Shown = false
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			return tools.MarkDirty(this)
 		}); err != nil {
 			return err
 		}
@@ -1515,18 +1635,28 @@ func (this *ModelMoveStatus) Init(tools *shf.Tools) error {
 		this.tip = tools.CreateElement("div")
 		this.tip.Set("className", "tip")
 		if err := tools.Click(this.tip, func(_ shf.Event) error {
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			// Update shows next tip.
+			return tools.MarkDirty(this)
 		}); err != nil {
 			return err
 		}
+		this.nextTip = true
+		tools.BeforeUpdate(func() {
+			this.nextTip = true
+			if this.Shown {
+				tools.MarkDirty(this)
+			}
+		})
 	}
 
 	if this.Element == nil {
 		this.Element = tools.CreateElement("div")
 		this.Set("id", "move-status")
+		this.Call("setAttribute", "role", "region")
+		this.Call("setAttribute", "aria-label", tr("Game link"))
 
 		this.Call("appendChild", this.Link.Object())
+		this.Call("appendChild", this.Conditions.Object())
 
 		{
 			div := tools.CreateElement("div")
@@ -1554,6 +1684,9 @@ var tips = []string{
 	"to rotate board for current moving player, click on game status icon, or text",
 	"to toggle this game URL dialog, click on any empty square on board",
 	"to toggle zen mode, try double click on empty chess square",
+	"to move a piece, you can also drag it to the destination square",
+	"to see keyboard shortcuts, press ? key",
+	"to make a move with keyboard, press / key and type the move (e.g. Nf3 or e2e4)",
 }
 
 func (this *ModelMoveStatus) Update(tools *shf.Tools) error {
@@ -1562,16 +1695,25 @@ func (this *ModelMoveStatus) Update(tools *shf.Tools) error {
 	}
 
 	if this.Shown {
-		if len(tips) > 0 {
-			this.tip.Set("textContent", "tip: "+tips[this.tipNo])
-			this.tipNo = (this.tipNo + 1) % len(tips)
+		if len(tips) > 0 && (this.refSettings == nil || this.refSettings.ShowTips.Get()) {
+			if this.nextTip {
+				tipNo := 0
+				if this.refSettings != nil {
+					tipNo = this.refSettings.NextTip(len(tips))
+				}
+				this.tip.Set("textContent", tr("tip: %s", tr(tips[tipNo])))
+				this.nextTip = false
+			}
+			this.tip.Get("classList").Call("remove", "hidden")
+		} else {
+			this.tip.Get("classList").Call("add", "hidden")
 		}
 		this.Get("classList").Call("remove", "hidden")
 	} else {
 		this.Get("classList").Call("add", "hidden")
 	}
 
-	return tools.Update(this.Link)
+	return tools.Update(this.Link, this.Conditions)
 }
 
 func (this *ModelCover) Init(tools *shf.Tools) error {
@@ -1604,12 +1746,63 @@ func (this *ModelCover) Update(tools *shf.Tools) error {
 type ModelExportTagInput struct {
 	shf.Element
 	Name, Label string
-	Input       shf.Element
+	// Example of a valid value, shown as placeholder.
+	Hint string
+	// Value is filled by the application and can not be edited.
+	Auto bool
+	// Tag was added by user and can be removed from editor.
+	Removable bool
+	Validate  func(value string) error
+	// Validation error message for current value, empty if the value is valid.
+	Invalid string
+
+	Input  shf.Element
+	Error  shf.Element
+	Remove shf.Element
+}
+
+// Returns input value without surrounding white space.
+func (this *ModelExportTagInput) Value() string {
+	return strings.TrimSpace(this.Input.Get("value").String())
+}
+
+// Returns input value, or empty string if the value is not valid. Sets validation error message.
+func (this *ModelExportTagInput) ValidValue() string {
+	value := this.Value()
+	this.Invalid = ""
+	if value == "" || this.Validate == nil {
+		return value
+	}
+	if err := this.Validate(value); err != nil {
+		this.Invalid = err.Error()
+		return ""
+	}
+	return value
 }
 
 func (this *ModelExportTagInput) Init(tools *shf.Tools) error {
+	id := "export-tag-" + strings.ToLower(this.Name)
 	if this.Input == nil {
 		this.Input = tools.CreateElement("input")
+		this.Input.Call("setAttribute", "aria-label", this.Label)
+		this.Input.Call("setAttribute", "aria-describedby", id+"-error")
+		if this.Hint != "" {
+			this.Input.Set("placeholder", this.Hint)
+		}
+		if this.Auto {
+			this.Input.Call("setAttribute", "readonly", "readonly")
+		}
+	}
+	if this.Error == nil {
+		this.Error = tools.CreateElement("small")
+		this.Error.Set("id", id+"-error")
+		this.Error.Get("classList").Call("add", "error")
+	}
+	if this.Remove == nil && this.Removable {
+		this.Remove = tools.CreateElement("button")
+		this.Remove.Set("textContent", "×")
+		this.Remove.Set("title", tr("remove tag"))
+		this.Remove.Call("setAttribute", "aria-label", tr("remove tag")+" "+this.Name)
 	}
 
 	if this.Element == nil {
@@ -1617,11 +1810,18 @@ func (this *ModelExportTagInput) Init(tools *shf.Tools) error {
 		label.Set("textContent", this.Label)
 
 		this.Element = tools.CreateElement("p")
-		this.Set("id", "export-tag-"+strings.ToLower(this.Name))
+		this.Set("id", id)
 		this.Element.Get("classList").Call("add", "tag")
+		if this.Auto {
+			this.Element.Get("classList").Call("add", "auto")
+		}
 
 		this.Call("appendChild", label.Object())
 		this.Call("appendChild", this.Input.Object())
+		if this.Remove != nil {
+			this.Call("appendChild", this.Remove.Object())
+		}
+		this.Call("appendChild", this.Error.Object())
 	}
 
 	return nil
@@ -1631,6 +1831,15 @@ func (this *ModelExportTagInput) Update(tools *shf.Tools) error {
 		return errors.New("ModelExportTagInput is nil")
 	}
 
+	if this.Invalid != "" {
+		this.Input.Get("classList").Call("add", "invalid")
+		this.Input.Call("setAttribute", "aria-invalid", "true")
+	} else {
+		this.Input.Get("classList").Call("remove", "invalid")
+		this.Input.Call("removeAttribute", "aria-invalid")
+	}
+	this.Error.Set("textContent", this.Invalid)
+
 	return nil
 }
 
@@ -1668,7 +1877,7 @@ func (this *ModelExportTagSelect) Update(tools *shf.Tools) error {
 	}
 
 	if this.Select != nil {
-		this.Select.Set("innerHTML", "")
+		tools.DestroyChildren(this.Select)
 
 		for _, o := range this.Options {
 
@@ -1694,79 +1903,138 @@ func (this *ModelExportTagSelect) Update(tools *shf.Tools) error {
 
 type ModelExportInput struct {
 	shf.Element
-	White, Black *ModelExportTagInput
-	Round, Date  *ModelExportTagInput
-	Result       *ModelExportTagSelect
-}
+	// Tag inputs in order of appearance, known tags shown by default first, then tags added by user.
+	Tags   []*ModelExportTagInput
+	Result *ModelExportTagSelect
 
-func (this *ModelExportInput) Init(tools *shf.Tools) error {
-	// PGN Tags known by lichess (from: https://github.com/lichess-org/lila/blob/master/modules/study/src/main/PgnTags.scala#L30)
-	// White,
-	// WhiteElo,
-	// WhiteTitle,
-	// WhiteTeam,
-	// WhiteFideId,
-	// Black,
-	// BlackElo,
-	// BlackTitle,
-	// BlackTeam,
-	// BlackFideId,
-	// TimeControl,
-	// Date,
-	// Result,
-	// Termination,
-	// Site,
-	// Event,
-	// Round,
-	// Board,
-	// Annotator
+	List shf.Element
+	// Adding of known or custom tags.
+	AddName    shf.Element
+	AddNames   shf.Element
+	Add        shf.Element
+	AddError   shf.Element
+	AddInvalid string
 
-	if this.White == nil {
-		this.White = &ModelExportTagInput{Name: "White", Label: "White name"}
-		if err := tools.Initialize(this.White); err != nil {
-			return err
+	addOptions []shf.Element
+	// Tag names offered in AddNames, separated by spaces.
+	shownAddNames string
+}
+
+// Returns input of tag with name, or nil if the tag is not in editor.
+func (this *ModelExportInput) Tag(name string) *ModelExportTagInput {
+	for _, t := range this.Tags {
+		if t.Name == name {
+			return t
 		}
 	}
-	if this.Black == nil {
-		this.Black = &ModelExportTagInput{Name: "Black", Label: "Black name"}
-		if err := tools.Initialize(this.Black); err != nil {
-			return err
-		}
+	return nil
+}
+
+// Returns names of tags in editor, in order of appearance.
+func (this *ModelExportInput) TagNames() []string {
+	names := make([]string, 0, len(this.Tags))
+	for _, t := range this.Tags {
+		names = append(names, t.Name)
 	}
-	if this.Round == nil {
-		this.Round = &ModelExportTagInput{Name: "Round", Label: "Round no."}
-		if err := tools.Initialize(this.Round); err != nil {
-			return err
-		}
+	return names
+}
+
+// Creates input for tag with name and appends it to editor. Known tags get label, hint & validation from schema.
+func (this *ModelExportInput) AddTag(tools *shf.Tools, name string) (*ModelExportTagInput, error) {
+	t := &ModelExportTagInput{Name: name, Label: name, Removable: true}
+	if schema := pgnTagSchemaByName(name); schema != nil {
+		t.Label, t.Hint, t.Auto, t.Validate = tr(schema.Label), schema.Hint, schema.Auto, schema.Validate
+		t.Removable = !schema.Shown
 	}
-	if this.Date == nil {
-		this.Date = &ModelExportTagInput{Name: "Date", Label: "Start date"}
-		if err := tools.Initialize(this.Date); err != nil {
-			return err
+	if err := tools.Initialize(t); err != nil {
+		return nil, err
+	}
+
+	this.Tags = append(this.Tags, t)
+	if this.List != nil {
+		this.List.Call("appendChild", t.Object())
+	}
+	return t, nil
+}
+
+// Removes tag input from editor.
+func (this *ModelExportInput) RemoveTag(tools *shf.Tools, t *ModelExportTagInput) {
+	for i := range this.Tags {
+		if this.Tags[i] == t {
+			this.Tags = append(this.Tags[:i], this.Tags[i+1:]...)
+			break
 		}
 	}
-	if this.Result == nil {
-		this.Result = &ModelExportTagSelect{Name: "Result", Label: "Result",
-			Options: [][2]string{
-				{"*", game.InProgress.String()},
-				{"1-0", game.WhiteWon.String()},
-				{"1/2-1/2", game.Draw.String()},
-				{"0-1", game.BlackWon.String()},
-			}}
-		if err := tools.Initialize(this.Result); err != nil {
-			return err
+	tools.Destroy(t.Element)
+}
+
+func (this *ModelExportInput) Init(tools *shf.Tools) error {
+	if this.List == nil {
+		this.List = tools.CreateElement("div")
+		this.List.Get("classList").Call("add", "list")
+	}
+
+	if this.Tags == nil {
+		for _, schema := range pgnTagSchemas {
+			if !schema.Shown {
+				continue
+			}
+			if _, err := this.AddTag(tools, schema.Name); err != nil {
+				return err
+			}
+			if schema.Name == "Black" {
+				// Result follows players, as in the Seven Tag Roster.
+				if this.Result == nil {
+					this.Result = &ModelExportTagSelect{Name: "Result", Label: tr("Result"),
+						Options: [][2]string{
+							{"*", tr(game.InProgress.String())},
+							{"1-0", tr(game.WhiteWon.String())},
+							{"1/2-1/2", tr(game.Draw.String())},
+							{"0-1", tr(game.BlackWon.String())},
+						}}
+					if err := tools.Initialize(this.Result); err != nil {
+						return err
+					}
+				}
+				this.List.Call("appendChild", this.Result.Object())
+			}
 		}
 	}
 
+	if this.AddNames == nil {
+		this.AddNames = tools.CreateElement("datalist")
+		this.AddNames.Set("id", "export-tag-names")
+	}
+	if this.AddName == nil {
+		this.AddName = tools.CreateElement("input")
+		this.AddName.Call("setAttribute", "list", "export-tag-names")
+		this.AddName.Call("setAttribute", "aria-label", tr("Tag name"))
+		this.AddName.Call("setAttribute", "aria-describedby", "export-tag-add-error")
+		this.AddName.Set("placeholder", tr("Tag name"))
+	}
+	if this.Add == nil {
+		this.Add = tools.CreateElement("button")
+		this.Add.Set("textContent", tr("add tag"))
+	}
+	if this.AddError == nil {
+		this.AddError = tools.CreateElement("small")
+		this.AddError.Set("id", "export-tag-add-error")
+		this.AddError.Get("classList").Call("add", "error")
+	}
+
 	if this.Element == nil {
+		add := tools.CreateElement("p")
+		add.Get("classList").Call("add", "add")
+		add.Call("appendChild", this.AddName.Object())
+		add.Call("appendChild", this.AddNames.Object())
+		add.Call("appendChild", this.Add.Object())
+		add.Call("appendChild", this.AddError.Object())
+
 		this.Element = tools.CreateElement("div")
 		this.Element.Get("classList").Call("add", "tags")
 
-		this.Element.Call("appendChild", this.White.Object())
-		this.Element.Call("appendChild", this.Black.Object())
-		this.Element.Call("appendChild", this.Round.Object())
-		this.Element.Call("appendChild", this.Date.Object())
-		this.Element.Call("appendChild", this.Result.Object())
+		this.Element.Call("appendChild", this.List.Object())
+		this.Element.Call("appendChild", add.Object())
 	}
 	return nil
 }
@@ -1775,9 +2043,47 @@ func (this *ModelExportInput) Update(tools *shf.Tools) error {
 		return errors.New("ModelExportInput is nil")
 	}
 
-	if err := tools.Update(this.White, this.Black, this.Round, this.Date, this.Result); err != nil {
+	for _, t := range this.Tags {
+		if err := tools.Update(t); err != nil {
+			return err
+		}
+	}
+	if err := tools.Update(this.Result); err != nil {
 		return err
 	}
+
+	// Offer known tags, which are not in editor yet.
+	offered := []*pgnTagSchema{}
+	names := ""
+	for i, schema := range pgnTagSchemas {
+		if this.Tag(schema.Name) != nil {
+			continue
+		}
+		offered = append(offered, &pgnTagSchemas[i])
+		names += schema.Name + " "
+	}
+	if names != this.shownAddNames {
+		// Offered tags changed, refill autocompletion.
+		tools.Destroy(this.addOptions...)
+		this.addOptions = nil
+		for _, schema := range offered {
+			option := tools.CreateElement("option")
+			option.Set("value", schema.Name)
+			option.Set("label", tr(schema.Label))
+			this.AddNames.Call("appendChild", option.Object())
+			this.addOptions = append(this.addOptions, option)
+		}
+		this.shownAddNames = names
+	}
+
+	if this.AddInvalid != "" {
+		this.AddName.Get("classList").Call("add", "invalid")
+		this.AddName.Call("setAttribute", "aria-invalid", "true")
+	} else {
+		this.AddName.Get("classList").Call("remove", "invalid")
+		this.AddName.Call("removeAttribute", "aria-invalid")
+	}
+	this.AddError.Set("textContent", this.AddInvalid)
 	return nil
 }
 
@@ -1788,7 +2094,7 @@ type CloseButton struct {
 func (this *CloseButton) Init(tools *shf.Tools) error {
 	if this.Element == nil {
 		this.Element = tools.CreateElement("button")
-		this.Set("textContent", "close")
+		this.Set("textContent", tr("close"))
 	}
 	return nil
 }
@@ -1802,12 +2108,65 @@ func (this *CloseButton) Update(tools *shf.Tools) error {
 type ModelExportOutput struct {
 	shf.Element
 	PGN *pgn.PGN
-
+	// Exported game & current half-move number in it.
+	Game       *game.Game
+	CurrMoveNo int
+	// Order of tags outside the Seven Tag Roster.
+	TagOrder []string
+
+	// Export options.
+	LongAlgebraic   bool
+	Wrap            bool
+	OmitTags        bool
+	UpToCurrentMove bool
+
+	Notation shf.Element
+	WrapBox  shf.Element
+	TagsBox  shf.Element
+	Range    shf.Element
 	TextArea shf.Element
 	Copy     *CopyButton
+	Download shf.Element
 	Close    *CloseButton
 }
 
+// Returns exported PGN with chosen options.
+func (this *ModelExportOutput) String() string {
+	if this.PGN == nil || this.Game == nil {
+		return ""
+	}
+	upTo := -1
+	if this.UpToCurrentMove {
+		upTo = this.CurrMoveNo
+	}
+	return exportPGN(this.Game, this.PGN.Tags, this.TagOrder, pgnExportOptions{
+		LongAlgebraic: this.LongAlgebraic,
+		Wrap:          this.Wrap,
+		OmitTags:      this.OmitTags,
+		UpToHalfMove:  upTo,
+	})
+}
+
+// Creates labeled export option control, which calls changed after every change of control.
+func (this *ModelExportOutput) option(tools *shf.Tools, control shf.Element, label string, changed func()) (shf.Element, error) {
+	if err := tools.Input(control, func(_ shf.Event) error {
+		changed()
+		return tools.Update(this)
+	}); err != nil {
+		return nil, err
+	}
+
+	option := tools.CreateElement("label")
+	if control.Get("type").String() == "checkbox" {
+		option.Call("appendChild", control.Object())
+		option.Call("appendChild", tools.CreateTextNode(" "+label))
+	} else {
+		option.Call("appendChild", tools.CreateTextNode(label+" "))
+		option.Call("appendChild", control.Object())
+	}
+	return option, nil
+}
+
 func (this *ModelExportOutput) Init(tools *shf.Tools) error {
 	if this.PGN == nil {
 		this.PGN = &pgn.PGN{}
@@ -1820,6 +2179,16 @@ func (this *ModelExportOutput) Init(tools *shf.Tools) error {
 		}
 	}
 
+	if this.Download == nil {
+		this.Download = tools.CreateElement("button")
+		this.Download.Set("textContent", tr("download PGN"))
+		if err := tools.Click(this.Download, func(_ shf.Event) error {
+			return downloadFile("URLchess.pgn", "application/x-chess-pgn", this.TextArea.Get("value").String())
+		}); err != nil {
+			return err
+		}
+	}
+
 	if this.Close == nil {
 		this.Close = &CloseButton{}
 		if err := tools.Initialize(this.Close); err != nil {
@@ -1834,14 +2203,67 @@ func (this *ModelExportOutput) Init(tools *shf.Tools) error {
 	}
 
 	if this.Element == nil {
+		this.Notation = tools.CreateElement("select")
+		for _, o := range [][2]string{{"san", tr("short algebraic (SAN)")}, {"lan", tr("long algebraic")}} {
+			option := tools.CreateElement("option")
+			option.Set("value", o[0])
+			option.Set("textContent", o[1])
+			this.Notation.Call("appendChild", option.Object())
+		}
+		notation, err := this.option(tools, this.Notation, tr("Notation"), func() {
+			this.LongAlgebraic = this.Notation.Get("value").String() == "lan"
+		})
+		if err != nil {
+			return err
+		}
+
+		this.Range = tools.CreateElement("select")
+		for _, o := range [][2]string{{"game", tr("whole game")}, {"current", tr("up to current move")}} {
+			option := tools.CreateElement("option")
+			option.Set("value", o[0])
+			option.Set("textContent", o[1])
+			this.Range.Call("appendChild", option.Object())
+		}
+		moves, err := this.option(tools, this.Range, tr("Moves"), func() {
+			this.UpToCurrentMove = this.Range.Get("value").String() == "current"
+		})
+		if err != nil {
+			return err
+		}
+
+		this.WrapBox = tools.CreateElement("input")
+		this.WrapBox.Set("type", "checkbox")
+		wrap, err := this.option(tools, this.WrapBox, tr("wrap lines at 80 columns"), func() {
+			this.Wrap = this.WrapBox.Get("checked").Bool()
+		})
+		if err != nil {
+			return err
+		}
+
+		this.TagsBox = tools.CreateElement("input")
+		this.TagsBox.Set("type", "checkbox")
+		tags, err := this.option(tools, this.TagsBox, tr("include tags"), func() {
+			this.OmitTags = !this.TagsBox.Get("checked").Bool()
+		})
+		if err != nil {
+			return err
+		}
+
+		options := tools.CreateElement("p")
+		options.Get("classList").Call("add", "options")
+		for _, o := range []shf.Element{notation, moves, wrap, tags} {
+			options.Call("appendChild", o.Object())
+		}
 
 		buttons := tools.CreateElement("p")
 		buttons.Get("classList").Call("add", "buttons")
 		buttons.Call("appendChild", this.Copy.Object())
+		buttons.Call("appendChild", this.Download.Object())
 		buttons.Call("appendChild", this.Close.Object())
 
 		this.Element = tools.CreateElement("div")
 		this.Get("classList").Call("add", "output")
+		this.Call("appendChild", options.Object())
 		this.Call("appendChild", this.TextArea.Object())
 		this.Call("appendChild", buttons.Object())
 	}
@@ -1853,8 +2275,21 @@ func (this *ModelExportOutput) Update(tools *shf.Tools) error {
 		return errors.New("ModelExportOutput is nil")
 	}
 
+	if this.LongAlgebraic {
+		this.Notation.Set("value", "lan")
+	} else {
+		this.Notation.Set("value", "san")
+	}
+	if this.UpToCurrentMove {
+		this.Range.Set("value", "current")
+	} else {
+		this.Range.Set("value", "game")
+	}
+	this.WrapBox.Set("checked", this.Wrap)
+	this.TagsBox.Set("checked", !this.OmitTags)
+
 	if this.PGN != nil {
-		this.TextArea.Set("value", this.PGN.String())
+		this.TextArea.Set("value", this.String())
 	}
 
 	return tools.Update(this.Copy, this.Close)
@@ -1864,8 +2299,12 @@ type ModelExport struct {
 	shf.Element
 	Shown bool
 
-	Input  *ModelExportInput
-	Output *ModelExportOutput
+	Input    *ModelExportInput
+	Output   *ModelExportOutput
+	Position *ModelExportPosition
+	GIF      *ModelExportGIF
+
+	focus dialogFocus
 }
 
 // EscapePGNString escapes special characters in a string for PGN tag values.
@@ -1877,6 +2316,21 @@ func EscapePGNString(s string) string {
 	return s
 }
 
+// UnescapePGNString reverts EscapePGNString.
+func UnescapePGNString(s string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 func (this *ModelExport) applyTag(key, value string) error {
 	if this.Output == nil || this.Output.PGN == nil || this.Output.PGN.Tags == nil {
 		return errors.New("output PGN tags are not initialized")
@@ -1889,6 +2343,31 @@ func (this *ModelExport) applyTag(key, value string) error {
 	}
 	return nil
 }
+
+// Binds tag input value change to update output PGN tag and remove button to remove the tag from editor & output.
+func (this *ModelExport) bindTagInput(tools *shf.Tools, t *ModelExportTagInput) error {
+	if err := tools.Input(t.Input, func(_ shf.Event) error {
+		if err := this.applyTag(t.Name, t.ValidValue()); err != nil {
+			return err
+		}
+
+		return tools.MarkDirty(this)
+	}); err != nil {
+		return err
+	}
+	if t.Remove == nil {
+		return nil
+	}
+	return tools.Click(t.Remove, func(_ shf.Event) error {
+		this.Input.RemoveTag(tools, t)
+		if err := this.applyTag(t.Name, ""); err != nil {
+			return err
+		}
+		this.Input.AddName.Call("focus")
+
+		return tools.MarkDirty(this)
+	})
+}
 func (this *ModelExport) Init(tools *shf.Tools) error {
 	if this.Output == nil {
 		this.Output = &ModelExportOutput{}
@@ -1899,13 +2378,24 @@ func (this *ModelExport) Init(tools *shf.Tools) error {
 		if err := tools.Click(this.Output.Close.Element, func(_ shf.Event) error {
 			this.Shown = false
 			this.Output.PGN = nil
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			return tools.MarkDirty(this)
 		}); err != nil {
 			return err
 		}
 
 	}
+	if this.Position == nil {
+		this.Position = &ModelExportPosition{}
+		if err := tools.Initialize(this.Position); err != nil {
+			return err
+		}
+	}
+	if this.GIF == nil {
+		this.GIF = &ModelExportGIF{}
+		if err := tools.Initialize(this.GIF); err != nil {
+			return err
+		}
+	}
 	if this.Input == nil {
 		this.Input = &ModelExportInput{}
 		if err := tools.Initialize(this.Input); err != nil {
@@ -1913,58 +2403,40 @@ func (this *ModelExport) Init(tools *shf.Tools) error {
 		}
 
 		// Bind tags input value change to update output PGN tags.
-		if err := tools.Input(this.Input.White.Input, func(_ shf.Event) error {
-			if err := this.applyTag(this.Input.White.Name, this.Input.White.Input.Get("value").String()); err != nil {
+		for _, t := range this.Input.Tags {
+			if err := this.bindTagInput(tools, t); err != nil {
 				return err
 			}
-
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
-		}); err != nil {
-			return err
 		}
-		if err := tools.Input(this.Input.Black.Input, func(_ shf.Event) error {
-			if err := this.applyTag(this.Input.Black.Name, this.Input.Black.Input.Get("value").String()); err != nil {
+		if err := tools.Input(this.Input.Result.Select, func(_ shf.Event) error {
+			this.Input.Result.Selected = this.Input.Result.Select.Get("value").String()
+			if err := this.applyTag(this.Input.Result.Name, this.Input.Result.Selected); err != nil {
 				return err
 
 			}
 
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			return tools.MarkDirty(this)
 		}); err != nil {
 			return err
 		}
-		if err := tools.Input(this.Input.Round.Input, func(_ shf.Event) error {
-			if err := this.applyTag(this.Input.Round.Name, this.Input.Round.Input.Get("value").String()); err != nil {
-				return err
-
+		if err := tools.Click(this.Input.Add, func(_ shf.Event) error {
+			name := strings.TrimSpace(this.Input.AddName.Get("value").String())
+			this.Input.AddInvalid = ""
+			if err := validatePGNTagName(name, this.Input.TagNames()); err != nil {
+				this.Input.AddInvalid = err.Error()
+				return tools.MarkDirty(this)
 			}
 
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
-		}); err != nil {
-			return err
-		}
-		if err := tools.Input(this.Input.Date.Input, func(_ shf.Event) error {
-			if err := this.applyTag(this.Input.Date.Name, this.Input.Date.Input.Get("value").String()); err != nil {
+			t, err := this.Input.AddTag(tools, name)
+			if err != nil {
 				return err
-
 			}
-
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
-		}); err != nil {
-			return err
-		}
-		if err := tools.Input(this.Input.Result.Select, func(_ shf.Event) error {
-			this.Input.Result.Selected = this.Input.Result.Select.Get("value").String()
-			if err := this.applyTag(this.Input.Result.Name, this.Input.Result.Selected); err != nil {
+			if err := this.bindTagInput(tools, t); err != nil {
 				return err
-
 			}
-
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			this.Input.AddName.Set("value", "")
+			t.Input.Call("focus")
+			return tools.MarkDirty(this)
 		}); err != nil {
 			return err
 		}
@@ -1973,13 +2445,17 @@ func (this *ModelExport) Init(tools *shf.Tools) error {
 	if this.Element == nil {
 		this.Element = tools.CreateElement("div")
 		this.Set("id", "export-overlay")
+		this.Call("setAttribute", "role", "dialog")
+		this.Call("setAttribute", "aria-modal", "true")
+		this.Call("setAttribute", "aria-label", tr("Export game"))
 		if err := tools.Click(this.Element, func(e shf.Event) error {
-			if e.Get("target").Get("id").String() == "export-overlay" {
-				this.Shown = false
-				this.Output.PGN = nil
+			// Clicks on export controls bubble here too. Updating the overlay would reset a clicked checkbox before its input event.
+			if e.Get("target").Get("id").String() != "export-overlay" {
+				return nil
 			}
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			this.Shown = false
+			this.Output.PGN = nil
+			return tools.MarkDirty(this)
 		}); err != nil {
 			return err
 		}
@@ -1989,6 +2465,8 @@ func (this *ModelExport) Init(tools *shf.Tools) error {
 
 		export.Call("appendChild", this.Input.Element.Object())
 		export.Call("appendChild", this.Output.Element.Object())
+		export.Call("appendChild", this.Position.Element.Object())
+		export.Call("appendChild", this.GIF.Element.Object())
 
 		this.Call("appendChild", export.Object())
 	}
@@ -1999,63 +2477,78 @@ func (this *ModelExport) Update(tools *shf.Tools) error {
 		return errors.New("ModelExport is nil")
 	}
 
-	if err := tools.Update(this.Input); err != nil {
-		return err
-	}
-
 	if this.Input != nil && this.Output != nil && this.Output.PGN != nil && this.Output.PGN.Tags != nil {
 		// Populate input tags to output PGN.
-		if this.Input.White != nil && this.Input.White.Input != nil {
-			this.applyTag(this.Input.White.Name, this.Input.White.Input.Get("value").String())
-		}
-		if this.Input.Black != nil && this.Input.Black.Input != nil {
-			this.applyTag(this.Input.Black.Name, this.Input.Black.Input.Get("value").String())
-		}
-		if this.Input.Round != nil && this.Input.Round.Input != nil {
-			this.applyTag(this.Input.Round.Name, this.Input.Round.Input.Get("value").String())
-		}
-		if this.Input.Date != nil && this.Input.Date.Input != nil {
-			this.applyTag(this.Input.Date.Name, this.Input.Date.Input.Get("value").String())
+		for _, t := range this.Input.Tags {
+			this.applyTag(t.Name, t.ValidValue())
 		}
 		if this.Input.Result != nil {
 			this.applyTag(this.Input.Result.Name, this.Input.Result.Selected)
 		}
+		this.Output.TagOrder = this.Input.TagNames()
+	}
+
+	if err := tools.Update(this.Input); err != nil {
+		return err
+	}
+
+	if this.Output != nil && this.Output.PGN != nil && this.GIF != nil {
+		// Player names & result for GIF are taken from output PGN tags.
+		this.GIF.White = UnescapePGNString(this.Output.PGN.Tags["White"])
+		this.GIF.Black = UnescapePGNString(this.Output.PGN.Tags["Black"])
+		this.GIF.Result = this.Output.PGN.Tags["Result"]
 	}
 
-	if err := tools.Update(this.Output); err != nil {
+	if err := tools.Update(this.Output, this.Position, this.GIF); err != nil {
 		return err
 	}
 
 	if this.Shown {
 		this.Get("classList").Call("remove", "invisible")
+		this.Call("setAttribute", "aria-hidden", "false")
 	} else {
 		this.Get("classList").Call("add", "invisible")
+		this.Call("setAttribute", "aria-hidden", "true")
+	}
+	var focus shf.Element
+	if white := this.Input.Tag("White"); white != nil {
+		focus = white.Input
 	}
+	this.focus.update(this.Shown, focus)
 	return nil
 }
 
 type ModelNotification struct {
 	shf.Element
-	Shown     bool
-	timeoutId int
+	Shown bool
+	// Hides timed message.
+	timer *shf.Timer
+	// Elements created for the shown message only.
+	owned []shf.Element
+
+	focusElement shf.Element
+	focus        dialogFocus
 }
 
-func (n *ModelNotification) cancelTimer() {
-	if n.timeoutId == 0 {
-		return
-	}
-	js.Global().Call("clearTimeout", n.timeoutId)
-	//js.Global().Call("alert", "timer "+strconv.Itoa(n.timeoutId)+" canceled")
-	n.timeoutId = 0
+// Own makes the notification destroy elements, when the shown message is replaced. Use it for elements created just for the message.
+func (n *ModelNotification) Own(elements ...shf.Element) {
+	n.owned = append(n.owned, elements...)
 }
-func (n *ModelNotification) Message(text, hint string, elements ...shf.Element) {
-	n.cancelTimer()
 
+// Cancels timer of the shown message and destroys its content.
+func (n *ModelNotification) clear(tools *shf.Tools) {
+	n.timer.Cancel()
+	tools.Destroy(n.owned...)
+	n.owned = nil
 	n.Set("innerHTML", "")
+}
+func (n *ModelNotification) Message(tools *shf.Tools, text, hint string, elements ...shf.Element) {
+	n.clear(tools)
 
 	//TODO jezek - Create properly using tools, save and destroy when changing.
 	notification := shf.CreateElementObject("div")
 	notification.Get("classList").Call("add", "notification")
+	notification.Call("setAttribute", "role", "alert")
 	{ // message
 		msg := shf.CreateElementObject("p")
 		msg.Get("classList").Call("add", "message")
@@ -2063,7 +2556,15 @@ func (n *ModelNotification) Message(text, hint string, elements ...shf.Element)
 		notification.Call("appendChild", msg)
 	}
 
+	n.focusElement = nil
 	for _, e := range elements {
+		if e == nil {
+			// Skip buttons, which could not be created.
+			continue
+		}
+		if n.focusElement == nil && e.Get("tagName").String() == "BUTTON" {
+			n.focusElement = e
+		}
 		notification.Call("appendChild", e.Object())
 	}
 	if hint != "" { // hint
@@ -2076,11 +2577,12 @@ func (n *ModelNotification) Message(text, hint string, elements ...shf.Element)
 	n.Shown = true
 }
 func (n *ModelNotification) TimedMessage(tools *shf.Tools, duration time.Duration, text, hint string, elements ...shf.Element) {
-	n.cancelTimer()
+	n.clear(tools)
 
 	//TODO jezek - Create properly using tools, save and destroy when changing.
 	notification := shf.CreateElementObject("div")
 	notification.Get("classList").Call("add", "notification")
+	notification.Call("setAttribute", "role", "alert")
 	{ // message
 		msg := shf.CreateElementObject("p")
 		msg.Get("classList").Call("add", "message")
@@ -2089,6 +2591,9 @@ func (n *ModelNotification) TimedMessage(tools *shf.Tools, duration time.Duratio
 	}
 
 	for _, e := range elements {
+		if e == nil {
+			continue
+		}
 		notification.Call("appendChild", e.Object())
 	}
 	if hint != "" { // hint
@@ -2098,13 +2603,12 @@ func (n *ModelNotification) TimedMessage(tools *shf.Tools, duration time.Duratio
 		notification.Call("appendChild", hintElm)
 	}
 
-	n.Set("innerHTML", "")
 	n.Call("appendChild", notification)
 
 	n.Shown = true
-	n.timeoutId = tools.Timer(duration, func() {
-		n.timeoutId = 0
+	n.timer = tools.After(duration, shf.TimerOptions{Owner: n, NoUpdate: true}, func() {
 		n.Shown = false
+		tools.MarkDirty(n)
 	})
 }
 
@@ -2113,13 +2617,15 @@ func (n *ModelNotification) Init(tools *shf.Tools) error {
 	if n.Element == nil {
 		n.Element = tools.CreateElement("div")
 		n.Set("id", "notification-overlay")
+		n.Call("setAttribute", "role", "dialog")
+		n.Call("setAttribute", "aria-modal", "true")
+		n.Call("setAttribute", "aria-label", tr("Notification"))
 		if err := tools.Click(n.Element, func(e shf.Event) error {
 			if e.Get("target").Get("id").String() == "notification-overlay" {
-				n.cancelTimer()
+				n.timer.Cancel()
 				n.Shown = false
 			}
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			return tools.MarkDirty(n)
 		}); err != nil {
 			return err
 		}
@@ -2133,9 +2639,12 @@ func (n *ModelNotification) Update(tools *shf.Tools) error {
 
 	if n.Shown {
 		n.Get("classList").Call("remove", "invisible")
+		n.Call("setAttribute", "aria-hidden", "false")
 	} else {
 		n.Get("classList").Call("add", "invisible")
+		n.Call("setAttribute", "aria-hidden", "true")
 	}
+	n.focus.update(n.Shown, n.focusElement)
 	return nil
 }
 
@@ -2148,7 +2657,13 @@ type HtmlModel struct {
 	Cover        *ModelCover
 	Export       *ModelExport
 	Notification *ModelNotification
+	DragPiece    *ModelDragPiece
+	Announcer    *ModelAnnouncer
+	Settings     *ModelSettings
 	Footer       *ModelFooter
+
+	// Marked dirty by events, which change the game, the next move or the board.
+	game gameView
 }
 
 func (h *HtmlModel) Init(tools *shf.Tools) error {
@@ -2188,6 +2703,24 @@ func (h *HtmlModel) Init(tools *shf.Tools) error {
 			return err
 		}
 	}
+	if h.DragPiece == nil {
+		h.DragPiece = &ModelDragPiece{}
+		if err := tools.Initialize(h.DragPiece); err != nil {
+			return err
+		}
+	}
+	if h.Announcer == nil {
+		h.Announcer = &ModelAnnouncer{}
+		if err := tools.Initialize(h.Announcer); err != nil {
+			return err
+		}
+	}
+	if h.Settings == nil {
+		h.Settings = &ModelSettings{}
+		if err := tools.Initialize(h.Settings); err != nil {
+			return err
+		}
+	}
 	if h.Footer == nil {
 		h.Footer = &ModelFooter{}
 		if err := tools.Initialize(h.Footer); err != nil {
@@ -2202,6 +2735,11 @@ func (h *HtmlModel) Update(tools *shf.Tools) error {
 		return errors.New("HtmlModel is nil")
 	}
 
+	h.updateRotation()
+	return tools.Update(h.Header, h.Board, h.ThrownOuts, h.Cover, h.Export, h.Notification, h.DragPiece, h.Announcer, h.Settings, h.Footer)
+}
+
+func (h *HtmlModel) updateRotation() {
 	if h.Rotated180deg {
 		h.Board.Get("classList").Call("add", "rotated180deg")
 		h.ThrownOuts.Get("classList").Call("add", "rotated180deg")
@@ -2209,12 +2747,10 @@ func (h *HtmlModel) Update(tools *shf.Tools) error {
 		h.Board.Get("classList").Call("remove", "rotated180deg")
 		h.ThrownOuts.Get("classList").Call("remove", "rotated180deg")
 	}
-
-	return tools.Update(h.Header, h.Board, h.ThrownOuts, h.Cover, h.Export, h.Notification, h.Footer)
 }
 
 func (m *Model) RotateBoard() {
-	if !m.rotationSupported {
+	if !m.Settings.RotationSupported {
 		return
 	}
 	m.Html.Rotated180deg = !m.Html.Rotated180deg
@@ -2264,125 +2800,79 @@ func (h *HtmlModel) CopyExportOutputToClipboard() error {
 	return nil
 }
 
-type ThrownOuts map[piece.Piece]uint8
-type GameThrownOuts []ThrownOuts
 type ChessGameModel struct {
-	gameHash   string
-	game       *game.Game
-	gameGc     GameThrownOuts
+	// Game moves hash, game & captured pieces after every half-move.
+	urlchess.Replay
+	// Carries out navigation to a new moves hash, after a move was made or taken back.
+	navigator gameNavigator
+
 	currMoveNo int
 	nextMove   move.Move
 	pgn        *pgn.PGN
 
+	// Conditional replies for the next move & half-move number of the last automatically played reply. See conditional.go
+	conditions []conditionLine
+	autoReply  int
+	// Last move was made by the player in this page, not received in link. Only then the player can add conditional replies.
+	ownMove bool
+	// Encoded conditional replies of the received game, kept in the hash while browsing its earlier moves. See browseHash.
+	heldConditions string
+
 	initialGame *game.Game
 	initialPgn  *pgn.PGN
-}
-
-func addedThrownOuts(prev, next ThrownOuts) ThrownOuts {
-	added := ThrownOuts{}
-	for p, c := range prev {
-		if next[p] > c {
-			added[p] = added[p] + (next[p] - c)
-		}
-	}
-	for p, c := range next {
-		if _, ok := prev[p]; !ok {
-			added[p] = added[p] + c
-		}
-	}
-	return added
-}
-
-func pMakeMove(p *position.Position, m move.Move) (*position.Position, piece.Piece) {
-	newPos := p.MakeMove(m)
-
-	// was a piece thrown out regulary? = move destination contains some piece
-	if pce := p.OnSquare(m.To()); pce.Type != piece.None {
-		return newPos, pce
-	}
+	// Hash of the received game, with its conditional replies.
+	initialHash string
 
-	// was en passant throw out? = moved piece is pawn and move destination is an en passan square in previous move
-	if mp := p.OnSquare(m.From()); mp.Type == piece.Pawn && m.To() == p.EnPassant {
-		return newPos, piece.New(piece.Colors[(p.ActiveColor+1)%2], piece.Pawn)
-	}
-
-	return newPos, piece.New(piece.NoColor, piece.None)
+	// Legal moves of the last asked position, see legalMoves.
+	legal *legalMoves
 }
 
 // Creates new chess game from moves string.
-// The moves string is basicaly move coordinates from & to (0...63) encoded in base64 (with some improvements for promotions, etc...). See encoding.go
-func NewGame(hash string) (*ChessGameModel, error) {
+// The moves string is basicaly move coordinates from & to (0...63) encoded in base64 (with some improvements for promotions, etc...). See urlchess/codec.go
+func NewGame(hash string, navigator gameNavigator) (*ChessGameModel, error) {
 	//println("NewGame(hash: \"" + hash + "\")")
-	chgm := &ChessGameModel{}
+	chgm := &ChessGameModel{navigator: navigator}
 
 	if err := chgm.UpdateToHash(hash); err != nil {
 		return nil, err
 	}
 
-	chgm.initialGame = chgm.game
+	chgm.initialGame = chgm.Game
 	chgm.initialPgn = chgm.pgn
+	chgm.initialHash = chgm.Hash()
 
 	return chgm, nil
 }
 
 // Updates chess game to match moves from the moves hash string.
-// The moves hash string are basically pair of move coordinates (from, to <0, 63>) encoded in base64 (with some improvements for promotions, etc...). See encoding.go
+// The moves hash string are basically pair of move coordinates (from, to <0, 63>) encoded in base64 (with some improvements for promotions, etc...). See urlchess/codec.go
 func (ch *ChessGameModel) UpdateToHash(hash string) error {
 	//println("UpdateToHash(" + hash + ")")
-	// Trim movesString from leading "#" character.
-	movesString := strings.TrimPrefix(hash, "#")
-
-	// Decode moves from hash string.
-	moves, err := DecodeMoves(movesString)
+	// Trim movesString from leading "#" character and split conditional replies from moves.
+	movesString, conditions, autoReply, err := decodeConditions(strings.TrimPrefix(hash, "#"))
 	if err != nil {
-		return errors.New("decoding moves error: " + err.Error())
+		return err
 	}
 
-	// Create new game and thrown outs structures.
-	g := game.New()
-	gtos := make(GameThrownOuts, len(moves))
-
-	// Apply decode game moves to new game.
-	for i, move := range moves {
-		if g.Status() != game.InProgress {
-			return errors.New("Too many moves in url string! " + strconv.Itoa(i+1) + " moves are enough")
-		}
-
-		// Store position before the move.
-		pbm := g.Position()
-
-		// Make the move and check validity.
-		_, merr := g.MakeMove(move)
-		if merr != nil {
-			return errors.New("Erroneous move number " + strconv.Itoa(i+1) + ": " + merr.Error())
-		}
-
-		// Create throw outs list for this move and copy thrown outs from previous move.
-		tos := ThrownOuts{}
-		if i > 0 {
-			for p, c := range gtos[i-1] {
-				tos[p] = c
-			}
-		}
-
-		// If there is a thrown out in this move, add it to this move thrown out list.
-		if _, top := pMakeMove(pbm, move); top.Type != piece.None {
-			tos[top] = tos[top] + 1
-		}
-
-		gtos[i] = tos
+	// Replay game moves with thrown outs.
+	if err := ch.Replay.UpdateToHash(movesString); err != nil {
+		return err
 	}
 
-	// Prepend one empty throw outs structure to the thrown outs list.
-	gtos = append(GameThrownOuts{ThrownOuts{}}, gtos...)
-
 	// Update the ChessGameModel structure.
-	ch.gameHash = movesString
-	ch.game = g
-	ch.gameGc = gtos
-	ch.currMoveNo = len(gtos) - 1
+	ch.currMoveNo = len(ch.Game.Positions) - 1
 	ch.nextMove = move.Null
-	ch.pgn = pgn.EncodeSAN(g)
+	ch.pgn = pgn.EncodeSAN(ch.Game)
+	ch.conditions = conditions
+	ch.autoReply = autoReply
+	ch.ownMove = false
+	ch.heldConditions = ""
+	if h := strings.TrimPrefix(hash, "#"); h != movesString && h == ch.browseHash(movesString) && h != ch.initialHash {
+		// Earlier move of the received game, its conditions apply only after its last move.
+		ch.heldConditions = strings.TrimPrefix(h, movesString)
+		ch.conditions = nil
+		ch.autoReply = 0
+	}
 
 	return nil
 }
@@ -2391,51 +2881,53 @@ func (ch *ChessGameModel) Validate() error {
 	if ch == nil {
 		return errors.New("ChessGame is nil")
 	}
-	if ch.currMoveNo < 0 || ch.currMoveNo >= len(ch.game.Positions) {
+	if ch.currMoveNo < 0 || ch.currMoveNo >= len(ch.Game.Positions) {
 		return errors.New("current move number is out of bounds")
 	}
-	if len(ch.game.Positions) != len(ch.gameGc) {
+	if len(ch.Game.Positions) != len(ch.ThrownOuts) {
 		return errors.New("count of game moves and thrown outs does not match")
 	}
-	if _, err := getNextMoveState(ch.game.Positions[ch.currMoveNo], ch.nextMove); err != nil {
+	if _, err := ch.legalMoves(ch.Game.Positions[ch.currMoveNo]).nextMoveState(ch.nextMove); err != nil {
 		return err
 	}
 	return nil
 }
 
+// Makes next move, plays conditional reply if the move triggers one and updates location hash.
 func (ch *ChessGameModel) MakeNextMove() error {
-	if err := ch.Validate(); err != nil {
-		return err
+	// Forget the automatically played reply, unless this move answers it.
+	if ch.autoReply != ch.currMoveNo {
+		ch.autoReply = 0
 	}
+	ch.heldConditions = ""
 
-	if !isLegalMove(ch.game.Positions[ch.currMoveNo], ch.nextMove) {
-		return errors.New("can not make next move, next move is not a legal move ")
+	if err := ch.makeNextMove(); err != nil {
+		return err
 	}
 
-	nextMoveHash, err := encodeMove(ch.nextMove)
+	played, err := ch.playConditionalReplies()
 	if err != nil {
 		return err
 	}
+	ch.ownMove = !played
 
-	{ // update game
-		if _, err := ch.game.MakeMove(ch.nextMove); err != nil {
-			return err
-		}
-		// update game hash
-		ch.gameHash = ch.gameHash + nextMoveHash
+	ch.navigator.Navigate(ch.Hash())
+
+	return nil
+}
+
+func (ch *ChessGameModel) makeNextMove() error {
+	if err := ch.Validate(); err != nil {
+		return err
+	}
 
+	if !ch.legalMoves(ch.Game.Positions[ch.currMoveNo]).isLegalMove(ch.nextMove) {
+		return errors.New("can not make next move, next move is not a legal move ")
 	}
-	{ // update throw outs
-		// copy previous move throw outs
-		nt := ThrownOuts{}
-		for p, c := range ch.gameGc[ch.currMoveNo] {
-			nt[p] = c
-		}
-		// add next move thrown out piece, if any
-		if _, top := pMakeMove(ch.game.Positions[ch.currMoveNo], ch.nextMove); top.Type != piece.None {
-			nt[top] = nt[top] + 1
-		}
-		ch.gameGc = append(ch.gameGc, nt)
+
+	// update game, game hash & thrown outs
+	if err := ch.Replay.MakeMove(ch.nextMove); err != nil {
+		return err
 	}
 
 	// advance move number
@@ -2444,10 +2936,7 @@ func (ch *ChessGameModel) MakeNextMove() error {
 	// reset next move
 	ch.nextMove = move.Null
 
-	ch.pgn = pgn.EncodeSAN(ch.game)
-
-	// update location hash
-	js.Global().Get("location").Set("hash", ch.gameHash)
+	ch.pgn = pgn.EncodeSAN(ch.Game)
 
 	return nil
 }
@@ -2455,67 +2944,51 @@ func (ch *ChessGameModel) BackToPreviousMove() error {
 	if err := ch.Validate(); err != nil {
 		return err
 	}
-	if ch.game.Positions[ch.currMoveNo].LastMove == move.Null {
+	if ch.Game.Positions[ch.currMoveNo].LastMove == move.Null {
 		// no previous move, just return
 		return nil
 	}
 
-	lastMove, err := encodeMove(ch.game.Positions[ch.currMoveNo].LastMove)
+	lastMove, err := urlchess.EncodeMove(ch.Game.Positions[ch.currMoveNo].LastMove)
 	if err != nil {
 		return err
 	}
 
-	if !strings.HasSuffix(ch.gameHash, lastMove) {
+	if !strings.HasSuffix(ch.Moves, lastMove) {
 		return errors.New("last move is not suffix of game moves")
 	}
 
-	previousGameMoves := strings.TrimSuffix(ch.gameHash, lastMove)
+	previousGameMoves := strings.TrimSuffix(ch.Moves, lastMove)
 
-	js.Global().Get("location").Set("hash", previousGameMoves)
+	ch.navigator.Browse(ch.browseHash(previousGameMoves))
 
 	return nil
 }
 
-func gameHashForHalfMove(g *game.Game, n int) (string, error) {
-	if n < 0 && n >= len(g.Positions) {
-		return "", errors.New("move no " + strconv.Itoa(n) + " is out of bounds <0, " + strconv.Itoa(len(g.Positions)-1) + ">")
-	}
-
-	hash := ""
-	for i := 1; i <= n; i++ {
-		em, err := encodeMove(g.Positions[i].LastMove)
-		if err != nil {
-			return "", err
-		}
-		hash += em
-	}
-
-	return hash, nil
-}
 func (ch *ChessGameModel) HashForInitialHalfMove(n int) (string, error) {
 	if err := ch.Validate(); err != nil {
 		return "", err
 	}
-	return gameHashForHalfMove(ch.initialGame, n)
+	return urlchess.HashForHalfMove(ch.initialGame, n)
 }
 func (ch *ChessGameModel) HashForHalfMove(n int) (string, error) {
 	if err := ch.Validate(); err != nil {
 		return "", err
 	}
-	return gameHashForHalfMove(ch.game, n)
+	return ch.Replay.HashForHalfMove(n)
 }
 
-func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSupported bool) error {
+func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, settings *Settings) error {
 	if err := ch.Validate(); err != nil {
 		return err
 	}
 
-	position := ch.game.Positions[ch.currMoveNo]
+	position := ch.Game.Positions[ch.currMoveNo]
 	nextMoveState := NMError
 	{ // set next move state & update game if next move is legal
 
 		// validate next move
-		nms, err := getNextMoveState(position, ch.nextMove)
+		nms, err := ch.legalMoves(position).nextMoveState(ch.nextMove)
 		if err != nil {
 			// this should not happen
 			return err
@@ -2524,15 +2997,25 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 		// next move is valid (a valid move, or waiting to fill some params)
 		nextMoveState = nms
 
+		if nextMoveState == NMWaitPromote && settings.AlwaysQueen.Get() {
+			// do not ask for promotion piece
+			ch.nextMove.Promote = piece.Queen
+			nextMoveState = NMLegalMove
+		}
+
 		if nextMoveState == NMLegalMove {
 			// next move is a legal move, do it
 			if err := ch.MakeNextMove(); err != nil {
 				return err
 			}
-			position = ch.game.Positions[ch.currMoveNo]
+			position = ch.Game.Positions[ch.currMoveNo]
 			nextMoveState = NMWaitFrom
 			m.Cover.GameStatus.rebuild(tools)
-
+			m.Cover.MoveStatus.Shown = !settings.SkipMoveStatus.Get()
+			m.Cover.MoveStatus.Conditions.reset()
+			if msg := ch.autoReplyMessage(); msg != "" && ch.autoReply == ch.currMoveNo {
+				m.Notification.Message(tools, tr("Conditional reply"), msg)
+			}
 		}
 	}
 	// from now on, nextMoveState != NMLegalMove
@@ -2543,13 +3026,13 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 		m.Board.PromotionOverlay.Shown = false
 	}
 
-	thrownOutPieces := ch.gameGc[ch.currMoveNo]
+	thrownOutPieces := ch.ThrownOuts[ch.currMoveNo]
 	lastMoveThrowOutPiece := piece.New(piece.NoColor, piece.None)
 
 	{ // update last move thorwn out piece
 		if ch.currMoveNo > 0 {
 			// derive last move throw out piece from curren and previous move throwouts
-			if added := addedThrownOuts(ch.gameGc[ch.currMoveNo-1], thrownOutPieces); len(added) > 1 {
+			if added := urlchess.AddedThrownOuts(ch.ThrownOuts[ch.currMoveNo-1], thrownOutPieces); len(added) > 1 {
 				// should not happen
 				return errors.New("more thrownouts added between previous and curren move")
 			} else if len(added) == 1 {
@@ -2576,7 +3059,7 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 				if position.Check(m.Board.Grid.Squares[i].Piece.Color) {
 					m.Board.Grid.Squares[i].Markers.Check = true
 
-					if ch.game.Status()&(game.WhiteWon|game.BlackWon) > 0 {
+					if ch.Game.Status()&(game.WhiteWon|game.BlackWon) > 0 {
 						// game ended with whith someone winning, has to be check mate
 						m.Board.Grid.Squares[i].Markers.Mate = true
 					}
@@ -2596,13 +3079,19 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 			m.Board.Grid.Squares[int(ch.nextMove.To())].Markers.ByColor[position.ActiveColor].NextMove.To = true
 		}
 
+		if m.DragPiece.Shown { // dragged piece markers
+			if m.DragPiece.From != square.NoSquare {
+				m.Board.Grid.Squares[int(m.DragPiece.From)].Markers.Dragged = true
+			}
+			if m.DragPiece.Over != square.NoSquare {
+				m.Board.Grid.Squares[int(m.DragPiece.Over)].Markers.DragOver = true
+			}
+		}
+
 		if ch.nextMove.From() != square.NoSquare && ch.nextMove.To() == square.NoSquare {
 			// fill possible moves
 			// mark possible to squares
-			for move, _ := range position.LegalMoves() {
-				if move.From() != ch.nextMove.From() {
-					continue
-				}
+			for _, move := range ch.legalMoves(position).From(ch.nextMove.From()) {
 				m.Board.Grid.Squares[int(move.To())].Markers.ByColor[position.ActiveColor].NextMove.PossibleTo = true
 			}
 		}
@@ -2631,9 +3120,9 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 	{ // update status & notification
 		m.Cover.GameStatus.Header.Icons.White = false
 		m.Cover.GameStatus.Header.Icons.Black = false
-		if st := ch.game.Status(); st != game.InProgress { // game ended
+		if st := ch.Game.Status(); st != game.InProgress { // game ended
 
-			m.Cover.GameStatus.Header.Message.Text = st.String()
+			m.Cover.GameStatus.Header.Message.Text = tr(st.String())
 			if st&game.Draw != 0 {
 				// game ended in draw
 				m.Cover.GameStatus.Header.Icons.White = true
@@ -2647,7 +3136,7 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 			}
 		} else {
 			// game in progress
-			m.Cover.GameStatus.Header.Message.Text = position.ActiveColor.String() + " player is on the move"
+			m.Cover.GameStatus.Header.Message.Text = tr(position.ActiveColor.String() + " player is on the move")
 			if position.ActiveColor == piece.White {
 				// white moves
 				m.Cover.GameStatus.Header.Icons.White = true
@@ -2658,8 +3147,18 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 		}
 	}
 
+	{ // update screen reader announcement
+		m.Announcer.Text = ch.announcement()
+	}
+
+	{ // update move input
+		m.Cover.GameStatus.MoveInput.Position = position
+		m.Cover.GameStatus.MoveInput.Disabled = ch.Game.Status() != game.InProgress
+	}
+
 	{ // update move status
-		m.Cover.MoveStatus.Link.MoveHash = ch.gameHash
+		m.Cover.MoveStatus.Link.MoveHash = ch.Hash()
+		m.Cover.MoveStatus.Conditions.Shown = ch.ownMove && ch.Game.Status() == game.InProgress
 
 		if position.LastMove != move.Null {
 			m.Cover.MoveStatus.Undo.Get("classList").Call("remove", "hidden")
@@ -2685,10 +3184,8 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 				// every empty square gets a double click callback for zen mode toggle
 				if sq.Piece.Type == piece.None {
 					if err := tools.DblClick(sq.Element, func(_ shf.Event) error {
-						// toggle zen mode by adding/removing "zen-mode" class to body
-						js.Global().Get("document").Get("body").Get("classList").Call("toggle", "zen-mode")
-						//TODO - Do only needed updates.
-						return tools.AppUpdate()
+						settings.ZenMode.Set(!settings.ZenMode.Get())
+						return tools.MarkDirty(m.Settings)
 					}); err != nil {
 						return err
 					}
@@ -2699,7 +3196,7 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 					// inspect next move state
 					squareNextMove := ch.nextMove
 					squareNextMove.Destination = sq.Id
-					squareNextMoveState, _ := getNextMoveState(position, squareNextMove)
+					squareNextMoveState, _ := ch.legalMoves(position).nextMoveState(squareNextMove)
 					if squareNextMoveState != NMLegalMove && squareNextMoveState != NMWaitPromote {
 						// should not happen
 						return errors.New("square " + sq.Id.String() + " is marked as possible to, but the next move here is not legal move or waiting to promoion")
@@ -2715,13 +3212,12 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 							// if next move is a legal move, show move status
 							m.Board.PromotionOverlay.Shown = true
 						}
-						//TODO - Do only needed updates.
-						return tools.AppUpdate()
+						return tools.MarkDirty(m.game)
 					}); err != nil {
 						return err
 					}
 
-					// a square can have only one click event, continue to next square
+					// Click replaces the square's previous click function, so the square gets only this one, continue to next square
 					continue
 				}
 
@@ -2730,20 +3226,19 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 					if err := tools.Click(sq.Element, func(_ shf.Event) error {
 						ch.nextMove = move.Null
 						m.Cover.MoveStatus.Shown = false
-						//TODO - Do only needed updates.
-						return tools.AppUpdate()
+						return tools.MarkDirty(m.game)
 					}); err != nil {
 						return err
 					}
 
-					// a square can have only one click event, continue to next square
+					// Click replaces the square's previous click function, so the square gets only this one, continue to next square
 					continue
 				}
 
 				// every moving player figure gets unique event
 				if position.ActiveColor == sq.Piece.Color {
 					// but only if game is in progress
-					if st := ch.game.Status(); st == game.InProgress {
+					if st := ch.Game.Status(); st == game.InProgress {
 						if err := tools.Click(sq.Element, func(_ shf.Event) error {
 							// set next move from
 							ch.nextMove.Source = sq.Id
@@ -2752,13 +3247,12 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 
 							// hide move status
 							m.Cover.MoveStatus.Shown = false
-							//TODO - Do only needed updates.
-							return tools.AppUpdate()
+							return tools.MarkDirty(m.game)
 						}); err != nil {
 							return err
 						}
 
-						// a square can have only one click event, continue to next square
+						// Click replaces the square's previous click function, so the square gets only this one, continue to next square
 						continue
 					}
 				}
@@ -2768,13 +3262,12 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 					if err := tools.Click(sq.Element, func(_ shf.Event) error {
 						ch.nextMove = move.Null
 						m.Cover.MoveStatus.Shown = false
-						//TODO - Do only needed updates.
-						return tools.AppUpdate()
+						return tools.MarkDirty(m.game)
 					}); err != nil {
 						return err
 					}
 
-					// a square can have only one click event, continue to next square
+					// Click replaces the square's previous click function, so the square gets only this one, continue to next square
 					continue
 				}
 
@@ -2787,8 +3280,7 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 					if sq.Piece.Type == piece.None {
 						if err := tools.Click(sq.Element, func(_ shf.Event) error {
 							m.Cover.MoveStatus.Shown = !m.Cover.MoveStatus.Shown
-							//TODO - Do only needed updates.
-							return tools.AppUpdate()
+							return tools.MarkDirty(m.Cover.MoveStatus)
 						}); err != nil {
 							return err
 						}
@@ -2797,7 +3289,7 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 
 				// last move gets some events
 				if position.LastMove != move.Null {
-					if execSupported {
+					if settings.ExecSupported {
 						// last move to square gets copy to clipboard
 						if err := tools.Click(m.Board.Grid.Squares[int(position.LastMove.To())].Element, func(_ shf.Event) error {
 							if err := m.CopyGameURLToClipboard(); err != nil {
@@ -2806,12 +3298,11 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 							m.Notification.TimedMessage(
 								tools,
 								5*time.Second,
-								"Game URL was copied to clipboard",
+								tr("Game URL was copied to clipboard"),
 								"",
 							)
 							m.Cover.MoveStatus.Shown = false
-							//TODO - Do only needed updates.
-							return tools.AppUpdate()
+							return tools.MarkDirty(m.Cover.MoveStatus, m.Notification)
 						}); err != nil {
 							return err
 						}
@@ -2819,8 +3310,7 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 						// copy is not supported, just show move status
 						if err := tools.Click(m.Board.Grid.Squares[int(position.LastMove.To())].Element, func(_ shf.Event) error {
 							m.Cover.MoveStatus.Shown = true
-							//TODO - Do only needed updates.
-							return tools.AppUpdate()
+							return tools.MarkDirty(m.Cover.MoveStatus)
 						}); err != nil {
 							return err
 						}
@@ -2832,8 +3322,7 @@ func (ch *ChessGameModel) UpdateModel(tools *shf.Tools, m *HtmlModel, execSuppor
 							return err
 						}
 						m.Cover.GameStatus.rebuild(tools)
-						//TODO - Do only needed updates.
-						return tools.AppUpdate()
+						return tools.MarkDirty(m.game)
 					}); err != nil {
 						return err
 					}
@@ -2852,61 +3341,74 @@ type Model struct {
 	ChessGame *ChessGameModel
 	Html      *HtmlModel
 
-	rotationSupported bool
-	execSupported     bool
+	Settings *Settings
+
+	drag   *boardDrag
+	sounds *soundPlayer
+
+	// Game hash shown after last update, to recognize a move to animate.
+	shownHash string
+	// Last move was dropped by dragging, the piece is at its destination already.
+	dropped bool
+
+	// Maps location hash to views, see routes.go.
+	router *shf.Router
+
+	// Recent errors reported to the app's error handler, see errors.go.
+	errors []recordedError
 }
 
 func (m *Model) showEndGameNotification(tools *shf.Tools) error {
 	newGameButton := tools.CreateElement("button")
-	newGameButton.Set("textContent", "new game")
+	newGameButton.Set("textContent", tr("new game"))
 	if err := tools.Click(newGameButton, func(_ shf.Event) error {
 		if err := m.ChessGame.UpdateToHash(""); err != nil {
 			return err
 		}
-		m.ChessGame.initialGame = m.ChessGame.game
+		m.ChessGame.initialGame = m.ChessGame.Game
 		m.ChessGame.initialPgn = m.ChessGame.pgn
+		m.ChessGame.initialHash = ""
 		m.Html.Notification.Shown = false
-		js.Global().Get("location").Set("hash", "")
-		m.RotateBoardForPlayer()
+		m.ChessGame.navigator.StartGame("")
+		m.AutoRotateBoard()
 		m.Html.Cover.GameStatus.rebuild(tools)
-		//TODO - Do only needed updates.
-		return tools.AppUpdate()
+		return tools.MarkDirty(m.Html.game)
 	}); err != nil {
 		// if there is an error creating event for button, simply do not show it
 		newGameButton = nil
 	}
 	exportButton := tools.CreateElement("button")
-	exportButton.Set("textContent", "export")
+	exportButton.Set("textContent", tr("export"))
 	if err := tools.Click(exportButton, func(_ shf.Event) error {
 		m.refreshExportOutputData()
 		m.Html.Notification.Shown = false
 		m.Html.Export.Shown = true
-		//TODO - Do only needed updates.
-		return tools.AppUpdate()
+		return tools.MarkDirty(m.Html.Export, m.Html.Notification)
 	}); err != nil {
 		// if there is an error creating event for button, simply do not show it
 		exportButton = nil
 	}
 	closeButton := tools.CreateElement("button")
-	closeButton.Set("textContent", "close")
+	closeButton.Set("textContent", tr("close"))
 	if err := tools.Click(closeButton, func(_ shf.Event) error {
 		m.Html.Notification.Shown = false
-		//TODO - Do only needed updates.
-		return tools.AppUpdate()
+		return tools.MarkDirty(m.Html.Notification)
 	}); err != nil {
 		// if there is an error creating event for button, simply do not show it
 		closeButton = nil
 	}
 	m.Html.Notification.Message(
-		m.ChessGame.game.Status().String(),
-		"tip: also click anywhere outside to close this notification",
+		tools,
+		tr(m.ChessGame.Game.Status().String()),
+		tr("tip: also click anywhere outside to close this notification"),
 		newGameButton, exportButton, closeButton,
 	)
+	m.Html.Notification.Own(newGameButton, exportButton, closeButton)
 	return nil
 }
 
 func (m *Model) refreshExportOutputData() {
-	gs := m.ChessGame.game.Status()
+	gs := m.ChessGame.Game.Status()
 	if gs == game.InProgress {
 		m.Html.Export.Input.Result.Selected = "*"
 		m.Html.Export.Input.Result.Disabled = false
@@ -2933,57 +3435,55 @@ func (m *Model) refreshExportOutputData() {
 	// [Date "1992.08.31"]
 	// [Date "1993.??.??"]
 	// [Date "2001.01.01"]
-	// TODO - Add hints to fields?
 	// Fill current date if empty.
-	if m.Html.Export.Input.Date.Input.Get("value").String() == "" {
-		m.Html.Export.Input.Date.Input.Set("value", time.Now().Format("2006.01.02"))
+	if date := m.Html.Export.Input.Tag("Date"); date != nil && date.Value() == "" {
+		date.Input.Set("value", time.Now().Format("2006.01.02"))
+	}
+	// Site is the game link, without conditional replies.
+	if site := m.Html.Export.Input.Tag("Site"); site != nil {
+		site.Input.Set("value", gameURL(m.ChessGame.Moves))
+	}
+	if termination := m.Html.Export.Input.Tag("Termination"); termination != nil {
+		termination.Input.Set("value", pgnTermination(gs))
 	}
-	//TODO - Add event tag? - http://www.saremba.de/chessgml/standards/pgn/pgn-complete.htm#c8.1.1
-	//TODO - Add termination tag? - http://www.saremba.de/chessgml/standards/pgn/pgn-complete.htm#c9.8.1
 	m.Html.Export.Output.PGN = m.ChessGame.pgn
+	m.Html.Export.Output.Game = m.ChessGame.Game
+	m.Html.Export.Output.CurrMoveNo = m.ChessGame.currMoveNo
+	m.Html.Export.Position.Position = m.ChessGame.Game.Positions[m.ChessGame.currMoveNo]
+	m.Html.Export.Position.Flipped = m.Html.Rotated180deg
+	m.Html.Export.GIF.Game = m.ChessGame.Game
+	m.Html.Export.GIF.Flipped = m.Html.Rotated180deg
 }
 
 func (m *Model) Init(tools *shf.Tools) error {
+	if m.Settings == nil {
+		m.Settings = NewSettings()
+	}
+	if m.router == nil {
+		m.router = m.newRouter(tools)
+	}
+	route, _ := m.router.Match(shf.LocationHash())
+	// Error of the opened link, reported after html model is initialized.
+	linkErr := unknownRouteError(route)
 	if m.ChessGame == nil {
-		m.ChessGame, _ = NewGame(js.Global().Get("location").Get("hash").String())
-	}
-
-	if err := tools.HashChange(func(e shf.HashChangeEvent) error {
-		//println("Mode.Init: HashChange")
-		// Get game hash.
-		gameHash := "#" + m.ChessGame.gameHash
-		// Get location hash.
-		locationHash := js.Global().Get("location").Get("hash").String()
-		//println("Mode.Init: HashChange: gameHash    :", gameHash)
-		//println("Mode.Init: HashChange: locationHash:", gameHash)
-		if gameHash == locationHash {
-			// Equal, do nothing.
-			return nil
-		}
-
-		// Not equal game & location hashes.
-		//js.Global().Call("alert", "not equal game & location hash: "+gameHash+" != "+locationHash)
-
-		// Update game to the location hash.
-		if err := m.ChessGame.UpdateToHash(locationHash); err != nil {
-			// Location hash is bad, revert document location hash to game hash.
-			//TODO - Proper error showing through notification.
-			js.Global().Call("alert", err.Error())
-			js.Global().Get("location").Set("hash", m.ChessGame.gameHash)
-			return nil
+		navigator := locationNavigator{m.Settings.History.Get}
+		chessGame, err := NewGame(routeGameHash(route), navigator)
+		if err != nil {
+			// Invalid game link, start with a new game.
+			linkErr = err
+			if chessGame, err = NewGame("", navigator); err != nil {
+				return err
+			}
 		}
+		m.ChessGame = chessGame
+	}
 
-		m.Html.Cover.GameStatus.rebuild(tools)
-		// Close move status after game is updated.
-		m.Html.Cover.MoveStatus.Shown = false
-
-		return tools.AppUpdate()
-	}); err != nil {
+	if err := tools.Route(m.router); err != nil {
 		return err
 	}
 
 	if m.Html == nil {
-		m.Html = &HtmlModel{}
+		m.Html = &HtmlModel{game: gameView{m}}
 
 		// Initialize the html model.
 		if err := tools.Initialize(m.Html); err != nil {
@@ -2992,49 +3492,74 @@ func (m *Model) Init(tools *shf.Tools) error {
 
 		// Add references between elements, where needed.
 		m.Html.Cover.GameStatus.Control.refGame = m.ChessGame
+		for _, cb := range []*ControlButton{m.Html.Cover.GameStatus.Control.Start, m.Html.Cover.GameStatus.Control.Previous, m.Html.Cover.GameStatus.Control.Next, m.Html.Cover.GameStatus.Control.Initial} {
+			cb.refNavigator = m.ChessGame.navigator
+		}
+		m.Html.Cover.GameStatus.Replay.refGame = m.ChessGame
 		m.Html.Cover.GameStatus.Moves.refGame = m.ChessGame
 		m.Html.Cover.GameStatus.Moves.refModel = m.Html
+		m.Html.Cover.MoveStatus.refSettings = m.Settings
+		m.Html.Settings.refSettings = m.Settings
+		m.Html.Settings.refModel = m.Html
+
+		if err := m.Html.Settings.rebuild(tools); err != nil {
+			return err
+		}
+		if route.Path == settingsPath {
+			m.openSettingsView()
+		}
+		if linkErr != nil {
+			if err := m.showInvalidLink(tools, linkErr); err != nil {
+				return err
+			}
+		}
 
-		if !m.rotationSupported {
+		if !m.Settings.RotationSupported {
 			m.Html.Rotated180deg = false
 			m.Html.Board.Edgings.BottomLeft.Disable()
 			m.Html.Board.Edgings.TopRight.Disable()
 		} else {
 			if err := tools.Click(m.Html.Cover.GameStatus.Header.Element, func(_ shf.Event) error {
 				// If game ended, notify the player.
-				if st := m.ChessGame.game.Status(); st != game.InProgress {
+				if st := m.ChessGame.Game.Status(); st != game.InProgress {
 					if err := m.showEndGameNotification(tools); err != nil {
 						return err
 					}
 				}
 
 				m.RotateBoardForPlayer()
-				//TODO - Do only needed updates.
-				return tools.AppUpdate()
+				return tools.MarkDirty(m.Html.game)
 			}); err != nil {
 				return err
 			}
+			if err := makeKeyboardClickable(tools, m.Html.Cover.GameStatus.Header.Element, tr("Rotate board for player on the move")); err != nil {
+				return err
+			}
 			if err := tools.Click(m.Html.Board.Edgings.BottomLeft.Element, func(_ shf.Event) error {
 				m.RotateBoard()
 
-				//TODO - Do only needed updates.
-				return tools.AppUpdate()
+				return tools.MarkDirty(m.Html.game)
 			}); err != nil {
 				return err
 			}
+			if err := makeKeyboardClickable(tools, m.Html.Board.Edgings.BottomLeft.Element, tr("Rotate board")); err != nil {
+				return err
+			}
 			m.Html.Board.Edgings.BottomLeft.Enable()
 			if err := tools.Click(m.Html.Board.Edgings.TopRight.Element, func(_ shf.Event) error {
 				m.RotateBoard()
 
-				//TODO - Do only needed updates.
-				return tools.AppUpdate()
+				return tools.MarkDirty(m.Html.game)
 			}); err != nil {
 				return err
 			}
+			if err := makeKeyboardClickable(tools, m.Html.Board.Edgings.TopRight.Element, tr("Rotate board")); err != nil {
+				return err
+			}
 			m.Html.Board.Edgings.TopRight.Enable()
 		}
 
-		if !m.execSupported {
+		if !m.Settings.ExecSupported {
 			m.Html.Cover.MoveStatus.Link.Copy.Shown = false
 			m.Html.Export.Output.Copy.Shown = false
 		} else {
@@ -3044,12 +3569,12 @@ func (m *Model) Init(tools *shf.Tools) error {
 					return err
 				}
 				m.Html.Notification.Message(
-					"game URL was copied to clipboard",
-					"tip: click on last move piece to copy",
+					tools,
+					tr("Game URL was copied to clipboard"),
+					tr("tip: click on last move piece to copy"),
 				)
 				m.Html.Cover.MoveStatus.Shown = false
-				//TODO - Do only needed updates.
-				return tools.AppUpdate()
+				return tools.MarkDirty(m.Html.Cover.MoveStatus, m.Html.Notification)
 			}); err != nil {
 				return err
 			}
@@ -3061,12 +3586,32 @@ func (m *Model) Init(tools *shf.Tools) error {
 				m.Html.Notification.TimedMessage(
 					tools,
 					5*time.Second,
-					"Game PGN was copied to clipboard",
+					tr("Game PGN was copied to clipboard"),
 					"",
 				)
 				//m.Html.Export.Shown = false
-				//TODO - Do only needed updates.
-				return tools.AppUpdate()
+				return tools.MarkDirty(m.Html.Notification)
+			}); err != nil {
+				return err
+			}
+
+			exportPosition := m.Html.Export.Position
+			exportPosition.CopySupported = true
+			if err := tools.Click(exportPosition.CopyFEN, func(_ shf.Event) error {
+				if err := m.Html.CopyExportPositionToClipboard(exportPosition.FEN); err != nil {
+					return err
+				}
+				m.Html.Notification.TimedMessage(tools, 5*time.Second, tr("Position FEN was copied to clipboard"), "")
+				return tools.MarkDirty(m.Html.Notification)
+			}); err != nil {
+				return err
+			}
+			if err := tools.Click(exportPosition.CopySVG, func(_ shf.Event) error {
+				if err := m.Html.CopyExportPositionToClipboard(exportPosition.Source); err != nil {
+					return err
+				}
+				m.Html.Notification.TimedMessage(tools, 5*time.Second, tr("Diagram SVG was copied to clipboard"), "")
+				return tools.MarkDirty(m.Html.Notification)
 			}); err != nil {
 				return err
 			}
@@ -3076,28 +3621,28 @@ func (m *Model) Init(tools *shf.Tools) error {
 	{ // add click events for html header & footer
 
 		newGameButton := tools.CreateElement("button")
-		newGameButton.Set("textContent", "new game")
+		newGameButton.Set("textContent", tr("new game"))
 		if err := tools.Click(newGameButton, func(_ shf.Event) error {
 			if err := m.ChessGame.UpdateToHash(""); err != nil {
 				return err
 			}
-			m.ChessGame.initialGame = m.ChessGame.game
+			m.ChessGame.initialGame = m.ChessGame.Game
 			m.ChessGame.initialPgn = m.ChessGame.pgn
+			m.ChessGame.initialHash = ""
 			m.Html.Notification.Shown = false
-			js.Global().Get("location").Set("hash", "")
-			m.RotateBoardForPlayer()
+			m.ChessGame.navigator.StartGame("")
+			m.AutoRotateBoard()
 			m.Html.Cover.GameStatus.rebuild(tools)
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			return tools.MarkDirty(m.Html.game)
 		}); err != nil {
 			// if there is an error creating event for button, simply do not show it
 			newGameButton = nil
 		}
 
 		copyLinkButton := shf.Element(nil)
-		if m.execSupported {
+		if m.Settings.ExecSupported {
 			copyLinkButton = tools.CreateElement("button")
-			copyLinkButton.Set("textContent", "copy link")
+			copyLinkButton.Set("textContent", tr("copy link"))
 			if err := tools.Click(copyLinkButton, func(e shf.Event) error {
 				e.Call("stopPropagation")
 
@@ -3108,39 +3653,83 @@ func (m *Model) Init(tools *shf.Tools) error {
 				m.Html.Notification.TimedMessage(
 					tools,
 					5*time.Second,
-					"game URL was copied to clipboard",
-					"tip: click on last move piece to copy",
+					tr("Game URL was copied to clipboard"),
+					tr("tip: click on last move piece to copy"),
 				)
-				//TODO - Do only needed updates.
-				return tools.AppUpdate()
+				return tools.MarkDirty(m.Html.Cover.MoveStatus, m.Html.Notification)
 			}); err != nil {
 				// if there is an error creating event for button, simply do not show it
 				copyLinkButton = nil
 			}
 		}
 
+		highContrastButton := tools.CreateElement("button")
+		highContrastButton.Set("textContent", tr("toggle high contrast"))
+		if err := tools.Click(highContrastButton, func(_ shf.Event) error {
+			m.Html.Notification.Shown = false
+			m.Settings.HighContrast.Set(!m.Settings.HighContrast.Get())
+			return tools.MarkDirty(m.Html.Notification, m.Html.Settings)
+		}); err != nil {
+			// if there is an error creating event for button, simply do not show it
+			highContrastButton = nil
+		}
+
+		soundsButton := tools.CreateElement("button")
+		soundsButton.Set("textContent", tr("toggle sounds"))
+		if err := tools.Click(soundsButton, func(_ shf.Event) error {
+			m.Html.Notification.Shown = false
+			m.Settings.Sounds.Set(!m.Settings.Sounds.Get())
+			return tools.MarkDirty(m.Html.Notification, m.Html.Settings)
+		}); err != nil {
+			// if there is an error creating event for button, simply do not show it
+			soundsButton = nil
+		}
+
 		zenModeButton := tools.CreateElement("button")
-		zenModeButton.Set("textContent", "toggle zen mode")
+		zenModeButton.Set("textContent", tr("toggle zen mode"))
 		if err := tools.Click(zenModeButton, func(_ shf.Event) error {
 			m.Html.Notification.Shown = false
-			js.Global().Get("document").Get("body").Get("classList").Call("toggle", "zen-mode")
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			m.Settings.ZenMode.Set(!m.Settings.ZenMode.Get())
+			return tools.MarkDirty(m.Html.Notification, m.Html.Settings)
 		}); err != nil {
 			// if there is an error creating event for button, simply do not show it
 			zenModeButton = nil
 		}
 
+		shortcutsButton := tools.CreateElement("button")
+		shortcutsButton.Set("textContent", tr("keyboard shortcuts"))
+		if err := tools.Click(shortcutsButton, func(e shf.Event) error {
+			e.Call("stopPropagation")
+
+			m.showKeyboardShortcuts(tools)
+			return tools.MarkDirty(m.Html.Notification)
+		}); err != nil {
+			// if there is an error creating event for button, simply do not show it
+			shortcutsButton = nil
+		}
+
+		settingsButton := tools.CreateElement("button")
+		settingsButton.Set("textContent", tr("settings"))
+		if err := tools.Click(settingsButton, func(e shf.Event) error {
+			e.Call("stopPropagation")
+
+			m.Html.Notification.Shown = false
+			m.Html.Settings.Shown = true
+			return tools.MarkDirty(m.Html.Notification, m.Html.Settings)
+		}); err != nil {
+			// if there is an error creating event for button, simply do not show it
+			settingsButton = nil
+		}
+
 		exportButton := tools.CreateElement("button")
-		exportButton.Set("textContent", "export game")
+		exportButton.Set("textContent", tr("export game"))
 		if err := tools.Click(exportButton, func(e shf.Event) error {
 			e.Call("stopPropagation")
 
 			m.refreshExportOutputData()
 			m.Html.Notification.Shown = false
 			m.Html.Export.Shown = true
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			return tools.MarkDirty(m.Html.Export, m.Html.Notification)
 		}); err != nil {
 			// if there is an error creating event for button, simply do not show it
 			exportButton = nil
@@ -3148,18 +3737,25 @@ func (m *Model) Init(tools *shf.Tools) error {
 
 		if err := tools.Click(m.Html.Header.Element, func(_ shf.Event) error {
 			m.Html.Notification.Message(
-				"Quick actions",
-				"tip: double click on empty square to toggle zen mode",
+				tools,
+				tr("Quick actions"),
+				tr("tip: double click on empty square to toggle zen mode"),
 				newGameButton,
 				copyLinkButton,
 				zenModeButton,
+				highContrastButton,
+				soundsButton,
 				exportButton,
+				shortcutsButton,
+				settingsButton,
 			)
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			return tools.MarkDirty(m.Html.Notification)
 		}); err != nil {
 			return err
 		}
+		if err := makeKeyboardClickable(tools, m.Html.Header.Element, tr("Quick actions")); err != nil {
+			return err
+		}
 	}
 
 	{ // add promotion events to promotion overlay
@@ -3167,8 +3763,7 @@ func (m *Model) Init(tools *shf.Tools) error {
 			m.ChessGame.nextMove.Promote = piece.None
 			m.ChessGame.nextMove.Destination = square.NoSquare
 			m.Html.Board.PromotionOverlay.Shown = false
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			return tools.MarkDirty(m.Html.game)
 		}); err != nil {
 			return err
 		}
@@ -3179,11 +3774,76 @@ func (m *Model) Init(tools *shf.Tools) error {
 				m.ChessGame.nextMove.Promote = promotionPiece.Piece.Type
 				m.Html.Board.PromotionOverlay.Shown = false
 				m.Html.Cover.MoveStatus.Shown = true
-				//TODO - Do only needed updates.
-				return tools.AppUpdate()
+				return tools.MarkDirty(m.Html.game)
 			}); err != nil {
 				return err
 			}
+			if err := makeKeyboardClickable(tools, p.Element, ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	{ // add drag & drop events to board grid
+		if err := tools.PointerDown(m.Html.Board.Grid.Element, func(e shf.PointerEvent) error {
+			return m.boardPointerDown(tools, e)
+		}); err != nil {
+			return err
+		}
+		if err := tools.PointerMove(m.Html.Board.Grid.Element, func(e shf.PointerEvent) error {
+			return m.boardPointerMove(tools, e)
+		}); err != nil {
+			return err
+		}
+		if err := tools.PointerUp(m.Html.Board.Grid.Element, func(e shf.PointerEvent) error {
+			return m.boardPointerUp(tools, e)
+		}); err != nil {
+			return err
+		}
+		if err := tools.PointerCancel(m.Html.Board.Grid.Element, func(e shf.PointerEvent) error {
+			return m.boardPointerCancel(tools, e)
+		}); err != nil {
+			return err
+		}
+	}
+
+	{ // add keyboard events
+		if err := tools.KeyDown(shf.Window, func(e shf.KeyboardEvent) error {
+			return m.keyDown(tools, e)
+		}); err != nil {
+			return err
+		}
+		if err := tools.KeyDown(m.Html.Board.Grid.Element, func(e shf.KeyboardEvent) error {
+			return m.gridKeyDown(tools, e)
+		}); err != nil {
+			return err
+		}
+
+		moveInput := m.Html.Cover.GameStatus.MoveInput
+		if err := tools.KeyDown(moveInput.Input, func(e shf.KeyboardEvent) error {
+			switch e.Key() {
+			case "Enter":
+				e.Call("preventDefault")
+				return m.submitTypedMove(tools, moveInput.Input.Get("value").String())
+			case "Escape", "Esc":
+				e.Call("stopPropagation")
+				moveInput.Input.Set("value", "")
+				moveInput.Input.Call("blur")
+				moveInput.Invalid = false
+				return tools.Update(moveInput)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := tools.Input(moveInput.Input, func(_ shf.Event) error {
+			if !moveInput.Invalid {
+				return nil
+			}
+			moveInput.Invalid = false
+			return tools.Update(moveInput)
+		}); err != nil {
+			return err
 		}
 	}
 
@@ -3195,8 +3855,55 @@ func (m *Model) Init(tools *shf.Tools) error {
 
 			m.Html.Cover.MoveStatus.Shown = false
 			m.Html.Cover.GameStatus.rebuild(tools)
-			//TODO - Do only needed updates.
-			return tools.AppUpdate()
+			return tools.MarkDirty(m.Html.game)
+		}); err != nil {
+			return err
+		}
+	}
+
+	{ // add replay events
+		replay := m.Html.Cover.GameStatus.Replay
+		if err := tools.Click(replay.Play, func(_ shf.Event) error {
+			if replay.Playing {
+				m.stopReplay()
+			} else if err := m.startReplay(tools); err != nil {
+				return err
+			}
+			return tools.MarkDirty(m.Html.game)
+		}); err != nil {
+			return err
+		}
+		if err := tools.Input(replay.Slider, func(_ shf.Event) error {
+			m.stopReplay()
+			n, err := strconv.Atoi(replay.Slider.Get("value").String())
+			if err != nil {
+				return err
+			}
+			if err := m.goToInitialHalfMove(tools, n); err != nil {
+				return err
+			}
+			return tools.MarkDirty(m.Html.game)
+		}); err != nil {
+			return err
+		}
+	}
+
+	{ // add save event for conditional replies in move-status
+		conditions := m.Html.Cover.MoveStatus.Conditions
+		if err := tools.Click(conditions.Save, func(_ shf.Event) error {
+			position := m.ChessGame.Game.Positions[m.ChessGame.currMoveNo]
+			lines, err := parseConditionLines(position, conditions.Input.Get("value").String())
+			if err != nil {
+				conditions.Info.Set("textContent", err.Error())
+				return nil
+			}
+			if err := m.ChessGame.SetConditions(lines); err != nil {
+				conditions.Info.Set("textContent", err.Error())
+				return nil
+			}
+			conditions.Info.Set("textContent", tr("Conditional reply lines attached to link: %s", strconv.Itoa(len(lines))))
+			m.ChessGame.navigator.Browse(m.ChessGame.Hash())
+			return tools.MarkDirty(m.Html.game)
 		}); err != nil {
 			return err
 		}
@@ -3210,21 +3917,77 @@ func (m *Model) Update(tools *shf.Tools) error {
 		return errors.New("Model is nil")
 	}
 
-	{ // Update html model from chess game.
-		err := m.ChessGame.UpdateModel(tools, m.Html, m.execSupported)
-		if err != nil {
-			return err
+	if err := m.updateHtmlModel(tools); err != nil {
+		return err
+	}
+	if err := tools.Update(m.Html); err != nil {
+		return err
+	}
+	m.animateShownMove(tools)
+	return nil
+}
+
+// Updates html model from chess game.
+func (m *Model) updateHtmlModel(tools *shf.Tools) error {
+	positions := len(m.ChessGame.Game.Positions)
+	if err := m.ChessGame.UpdateModel(tools, m.Html, m.Settings); err != nil {
+		return err
+	}
+	// Move was made, play its sound.
+	if len(m.ChessGame.Game.Positions) > positions {
+		m.playLastMoveSound()
+	}
+	return nil
+}
+
+// Animates the last move, if the game advanced by one move since last update.
+func (m *Model) animateShownMove(tools *shf.Tools) {
+	if ch := m.ChessGame; ch.Moves != m.shownHash {
+		if ch.currMoveNo > 0 && !m.dropped {
+			if previous, err := ch.HashForHalfMove(ch.currMoveNo - 1); err == nil && previous == m.shownHash {
+				m.animateLastMove(tools)
+			}
 		}
+		m.shownHash = ch.Moves
+		m.dropped = false
 	}
+}
+
+// Part of the app showing the game: html model is updated from chess game and the components, which it changes, are updated.
+// Header, footer, export & settings are left as they are.
+type gameView struct {
+	m *Model
+}
+
+func (v gameView) Update(tools *shf.Tools) error {
+	if v.m == nil {
+		return errors.New("gameView model is nil")
+	}
+	if err := v.m.updateHtmlModel(tools); err != nil {
+		return err
+	}
+	h := v.m.Html
+	h.updateRotation()
+	if err := tools.Update(h.Board, h.ThrownOuts, h.Cover, h.Notification, h.DragPiece, h.Announcer); err != nil {
+		return err
+	}
+	v.m.animateShownMove(tools)
+	return nil
+}
 
-	return tools.Update(m.Html)
+// Rotates board for player on the move, if auto rotation is on.
+func (m *Model) AutoRotateBoard() {
+	if !m.Settings.AutoRotate.Get() {
+		return
+	}
+	m.RotateBoardForPlayer()
 }
 func (app *Model) RotateBoardForPlayer() {
-	if !app.rotationSupported {
+	if !app.Settings.RotationSupported {
 		return
 	}
 	app.Html.Rotated180deg = false
-	if app.ChessGame.game.ActiveColor() == piece.Black {
+	if app.ChessGame.Game.ActiveColor() == piece.Black {
 		app.Html.Rotated180deg = true
 	}
 }
@@ -3233,17 +3996,56 @@ func isLegalMove(p *position.Position, m move.Move) bool {
 	_, ok := p.LegalMoves()[m]
 	return ok
 }
-func isLegalMoveFrom(p *position.Position, f square.Square) bool {
-	for move, _ := range p.LegalMoves() {
-		if move.Source == f {
-			return true
+
+// Legal moves of a position, indexed by source square.
+// Generating legal moves is expensive, so they are generated only once per position, see ChessGameModel.legalMoves.
+type legalMoves struct {
+	position *position.Position
+	moves    map[move.Move]struct{}
+	from     [64][]move.Move
+}
+
+func newLegalMoves(p *position.Position) *legalMoves {
+	lm := &legalMoves{
+		position: p,
+		moves:    p.LegalMoves(),
+	}
+	for m := range lm.moves {
+		if int(m.Source) >= len(lm.from) {
+			continue
 		}
+		lm.from[m.Source] = append(lm.from[m.Source], m)
 	}
-	return false
+	return lm
+}
+
+// Returns legal moves of position p. Moves of the last asked position are cached, so repeated calls for one board update generate them only once.
+func (ch *ChessGameModel) legalMoves(p *position.Position) *legalMoves {
+	if ch.legal == nil || ch.legal.position != p {
+		ch.legal = newLegalMoves(p)
+	}
+	return ch.legal
+}
+
+func (lm *legalMoves) isLegalMove(m move.Move) bool {
+	_, ok := lm.moves[m]
+	return ok
+}
+
+// Returns legal moves from square f, promotions to every piece are separate moves.
+func (lm *legalMoves) From(f square.Square) []move.Move {
+	if int(f) >= len(lm.from) {
+		return nil
+	}
+	return lm.from[f]
+}
+
+func (lm *legalMoves) isLegalMoveFrom(f square.Square) bool {
+	return len(lm.From(f)) > 0
 }
-func isLegalMoveFromTo(p *position.Position, f, t square.Square) bool {
-	for move, _ := range p.LegalMoves() {
-		if move.Source == f && move.Destination == t {
+func (lm *legalMoves) isLegalMoveFromTo(f, t square.Square) bool {
+	for _, move := range lm.From(f) {
+		if move.Destination == t {
 			return true
 		}
 	}
@@ -3258,14 +4060,15 @@ const (
 	NMWaitPromote
 )
 
-func getNextMoveState(p *position.Position, m move.Move) (int, error) {
+func (lm *legalMoves) nextMoveState(m move.Move) (int, error) {
+	p := lm.position
 	if m == move.Null {
 		// no next move
 		return NMWaitFrom, nil
 	}
 	// some move, legal or illegal or incomplete
 
-	if isLegalMove(p, m) {
+	if lm.isLegalMove(m) {
 		// legal move
 		return NMLegalMove, nil
 	}
@@ -3277,7 +4080,7 @@ func getNextMoveState(p *position.Position, m move.Move) (int, error) {
 	}
 	// from filled
 
-	if !isLegalMoveFrom(p, m.From()) {
+	if !lm.isLegalMoveFrom(m.From()) {
 		// from is illegal
 		if p.OnSquare(m.From()).Color == p.ActiveColor && m.To() == square.NoSquare && m.Promote == piece.None {
 			// but if only from is filled & piece on from square is an ctive piece, so let it be valid
@@ -3298,7 +4101,7 @@ func getNextMoveState(p *position.Position, m move.Move) (int, error) {
 	}
 	//to filled
 
-	if !isLegalMoveFromTo(p, m.From(), m.To()) {
+	if !lm.isLegalMoveFromTo(m.From(), m.To()) {
 		// from, to pair is illegal
 		return NMError, errors.New("next move to square is illegal! from: " + m.From().String() + ", to: " + m.To().String())
 	}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"URLchess/urlchess"
+	"sort"
+	"testing"
+
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/position/move"
+)
+
+// Returns moves hash of a long game played deterministically from the initial position.
+func longGameHash(tb testing.TB, halfMoves int) string {
+	tb.Helper()
+	r := urlchess.NewReplay()
+	for i := 0; i < halfMoves && r.Game.Status() == game.InProgress; i++ {
+		moves := []move.Move{}
+		for m := range r.Game.Position().LegalMoves() {
+			moves = append(moves, m)
+		}
+		sort.Slice(moves, func(a, b int) bool { return moves[a].String() < moves[b].String() })
+		if err := r.MakeMove(moves[(i*7)%len(moves)]); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return r.Moves
+}
+
+// Checks, that cached legal moves are the moves of the current position.
+func expectCurrentLegalMoves(t *testing.T, ch *ChessGameModel) {
+	t.Helper()
+	p := ch.Game.Positions[ch.currMoveNo]
+	lm := ch.legalMoves(p)
+	if lm.position != p {
+		t.Fatalf("legal moves of other position at half-move %d", ch.currMoveNo)
+	}
+	want := p.LegalMoves()
+	if len(lm.moves) != len(want) {
+		t.Errorf("half-move %d: %d legal moves, want %d", ch.currMoveNo, len(lm.moves), len(want))
+	}
+	for m := range want {
+		if !lm.isLegalMove(m) || !lm.isLegalMoveFromTo(m.From(), m.To()) {
+			t.Errorf("half-move %d: move %s is not legal", ch.currMoveNo, m)
+		}
+	}
+}
+
+func TestLegalMovesCache(t *testing.T) {
+	hash := longGameHash(t, 80)
+	ch, err := NewGame(hash, &testNavigator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectCurrentLegalMoves(t, ch)
+	if cached := ch.legal; ch.legalMoves(ch.Game.Position()) != cached {
+		t.Error("legal moves of the same position are generated again")
+	}
+
+	// Browsing back and forward through the game.
+	for _, n := range []int{79, 40, 0, 1, 41, 80} {
+		browsed, err := urlchess.HashForHalfMove(ch.initialGame, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ch.UpdateToHash(browsed); err != nil {
+			t.Fatal(err)
+		}
+		expectCurrentLegalMoves(t, ch)
+	}
+
+	// Other game.
+	if err := ch.UpdateToHash("LbzjBS"); err != nil {
+		t.Fatal(err)
+	}
+	expectCurrentLegalMoves(t, ch)
+
+	// Move made in the game.
+	m, err := ch.Game.Position().ParseMove("Nc6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.nextMove = m
+	if err := ch.MakeNextMove(); err != nil {
+		t.Fatal(err)
+	}
+	expectCurrentLegalMoves(t, ch)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andrewbackes/chess/game"
+)
+
+// Describes a PGN tag known to the export tag editor.
+type pgnTagSchema struct {
+	Name, Label string
+	// Example of a valid value, shown as placeholder.
+	Hint string
+	// Tag is shown in editor without adding it.
+	Shown bool
+	// Value is filled by the application and can not be edited.
+	Auto bool
+	// Returns error, if non empty value is not valid. Nil means any value is valid.
+	Validate func(value string) error
+}
+
+// PGN tags known by the export tag editor, in order of appearance.
+// The Seven Tag Roster first (http://www.saremba.de/chessgml/standards/pgn/pgn-complete.htm#c8.1.1),
+// then other tags known by lichess (https://github.com/lichess-org/lila/blob/master/modules/study/src/main/PgnTags.scala#L30).
+// Result tag is edited by a select and is not in the schema.
+var pgnTagSchemas = []pgnTagSchema{
+	{Name: "Event", Label: "Event", Hint: "Casual game", Shown: true},
+	{Name: "Site", Label: "Site", Shown: true, Auto: true},
+	{Name: "Date", Label: "Start date", Hint: "2024.05.??", Shown: true, Validate: validatePGNDate},
+	{Name: "Round", Label: "Round no.", Hint: "1", Shown: true, Validate: validatePGNRound},
+	{Name: "White", Label: "White name", Shown: true},
+	{Name: "Black", Label: "Black name", Shown: true},
+	{Name: "Termination", Label: "Termination", Shown: true, Auto: true},
+	{Name: "WhiteElo", Label: "White Elo", Hint: "1500", Validate: validatePGNNumber},
+	{Name: "BlackElo", Label: "Black Elo", Hint: "1500", Validate: validatePGNNumber},
+	{Name: "WhiteTitle", Label: "White title", Hint: "GM"},
+	{Name: "BlackTitle", Label: "Black title", Hint: "GM"},
+	{Name: "WhiteTeam", Label: "White team"},
+	{Name: "BlackTeam", Label: "Black team"},
+	{Name: "WhiteFideId", Label: "White FIDE ID", Validate: validatePGNNumber},
+	{Name: "BlackFideId", Label: "Black FIDE ID", Validate: validatePGNNumber},
+	{Name: "TimeControl", Label: "Time control", Hint: "300+3", Validate: validatePGNTimeControl},
+	{Name: "Board", Label: "Board no.", Hint: "1", Validate: validatePGNNumber},
+	{Name: "Annotator", Label: "Annotator"},
+}
+
+// Returns schema of known tag name, or nil.
+func pgnTagSchemaByName(name string) *pgnTagSchema {
+	for i := range pgnTagSchemas {
+		if pgnTagSchemas[i].Name == name {
+			return &pgnTagSchemas[i]
+		}
+	}
+	return nil
+}
+
+var (
+	regexpPGNDate        = regexp.MustCompile(`^(\d{4}|\?{4})\.(\d{2}|\?{2})\.(\d{2}|\?{2})$`)
+	regexpPGNRound       = regexp.MustCompile(`^(\?|-|\d+(\.\d+)*)$`)
+	regexpPGNNumber      = regexp.MustCompile(`^\d+$`)
+	regexpPGNTimeControl = regexp.MustCompile(`^(\?|-|\d+/\d+|\d+(\+\d+)?|\*\d+)$`)
+	regexpPGNTagName     = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_]*$`)
+)
+
+// Date tag uses "YYYY.MM.DD" format, unknown digits are replaced by question marks (e.g. "1993.??.??").
+func validatePGNDate(value string) error {
+	matches := regexpPGNDate.FindStringSubmatch(value)
+	if matches == nil {
+		return errors.New(tr("Date has to be in YYYY.MM.DD format, use ?? for unknown parts"))
+	}
+	if month, err := strconv.Atoi(matches[2]); err == nil && (month < 1 || month > 12) {
+		return errors.New(tr("Month has to be from 01 to 12"))
+	}
+	if day, err := strconv.Atoi(matches[3]); err == nil && (day < 1 || day > 31) {
+		return errors.New(tr("Day has to be from 01 to 31"))
+	}
+	return nil
+}
+
+// Round tag is a number, or numbers separated by periods for sub rounds. "?" is unknown, "-" is not appropriate.
+func validatePGNRound(value string) error {
+	if !regexpPGNRound.MatchString(value) {
+		return errors.New(tr("Round has to be a number, like 3 or 3.1"))
+	}
+	return nil
+}
+
+func validatePGNNumber(value string) error {
+	if !regexpPGNNumber.MatchString(value) {
+		return errors.New(tr("Value has to be a number"))
+	}
+	return nil
+}
+
+// TimeControl tag are colon separated periods, like "40/7200:3600", "300+2", or "*180" (sandclock).
+func validatePGNTimeControl(value string) error {
+	for _, period := range strings.Split(value, ":") {
+		if !regexpPGNTimeControl.MatchString(period) {
+			return errors.New(tr("Time control has to be in seconds, like 300+2 or 40/7200:3600"))
+		}
+	}
+	return nil
+}
+
+// Validates name of a custom tag, which is going to be added to tags already in the editor.
+func validatePGNTagName(name string, existing []string) error {
+	if !regexpPGNTagName.MatchString(name) {
+		return errors.New(tr("Tag name can contain only letters, digits and underscores"))
+	}
+	if name == "Result" {
+		return errors.New(tr("Tag is already in the list"))
+	}
+	for _, e := range existing {
+		if e == name {
+			return errors.New(tr("Tag is already in the list"))
+		}
+	}
+	return nil
+}
+
+// Returns Termination tag value for game status (http://www.saremba.de/chessgml/standards/pgn/pgn-complete.htm#c9.8.1).
+func pgnTermination(gs game.GameStatus) string {
+	switch {
+	case gs == game.InProgress:
+		return "unterminated"
+	case gs&(game.WhiteTimedOut|game.BlackTimedOut) != 0:
+		return "time forfeit"
+	case gs&(game.WhiteIllegalMove|game.BlackIllegalMove) != 0:
+		return "rules infraction"
+	}
+	return "normal"
+}
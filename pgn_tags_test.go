@@ -0,0 +1,126 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/andrewbackes/chess/game"
+)
+
+func TestValidatePGNTags(t *testing.T) {
+	for _, tc := range []struct {
+		validate func(string) error
+		name     string
+		value    string
+		valid    bool
+	}{
+		{validatePGNDate, "Date", "2024.05.17", true},
+		{validatePGNDate, "Date", "2024.05.??", true},
+		{validatePGNDate, "Date", "1993.??.??", true},
+		{validatePGNDate, "Date", "????.??.??", true},
+		{validatePGNDate, "Date", "2024.12.31", true},
+		{validatePGNDate, "Date", "2024.13.01", false},
+		{validatePGNDate, "Date", "2024.00.01", false},
+		{validatePGNDate, "Date", "2024.05.00", false},
+		{validatePGNDate, "Date", "2024.05.32", false},
+		{validatePGNDate, "Date", "2024.5.17", false},
+		{validatePGNDate, "Date", "2024-05-17", false},
+		{validatePGNDate, "Date", "2024.?5.17", false},
+		{validatePGNRound, "Round", "1", true},
+		{validatePGNRound, "Round", "3.1", true},
+		{validatePGNRound, "Round", "3.1.2", true},
+		{validatePGNRound, "Round", "?", true},
+		{validatePGNRound, "Round", "-", true},
+		{validatePGNRound, "Round", "3.", false},
+		{validatePGNRound, "Round", "first", false},
+		{validatePGNNumber, "WhiteElo", "1500", true},
+		{validatePGNNumber, "WhiteElo", "15OO", false},
+		{validatePGNNumber, "WhiteElo", "-1", false},
+		{validatePGNTimeControl, "TimeControl", "300", true},
+		{validatePGNTimeControl, "TimeControl", "300+2", true},
+		{validatePGNTimeControl, "TimeControl", "40/7200:3600", true},
+		{validatePGNTimeControl, "TimeControl", "40/7200:20/3600:900+30", true},
+		{validatePGNTimeControl, "TimeControl", "*180", true},
+		{validatePGNTimeControl, "TimeControl", "?", true},
+		{validatePGNTimeControl, "TimeControl", "-", true},
+		{validatePGNTimeControl, "TimeControl", "5 min", false},
+		{validatePGNTimeControl, "TimeControl", "40/7200:", false},
+		{validatePGNTimeControl, "TimeControl", "*180+2", false},
+		{validatePGNTimeControl, "TimeControl", "300+", false},
+	} {
+		if err := tc.validate(tc.value); (err == nil) != tc.valid {
+			t.Errorf("%s %q: error %v, want valid %t", tc.name, tc.value, err, tc.valid)
+		}
+	}
+}
+
+func TestPGNTagSchemas(t *testing.T) {
+	names := map[string]bool{}
+	for _, s := range pgnTagSchemas {
+		if names[s.Name] {
+			t.Errorf("tag %s is in schema more times", s.Name)
+		}
+		names[s.Name] = true
+		if err := validatePGNTagName(s.Name, nil); err != nil {
+			t.Errorf("tag %s: %s", s.Name, err)
+		}
+		if s.Validate != nil && s.Hint != "" {
+			if err := s.Validate(s.Hint); err != nil {
+				t.Errorf("tag %s hint %q is not valid: %s", s.Name, s.Hint, err)
+			}
+		}
+		if got := pgnTagSchemaByName(s.Name); got == nil || got.Name != s.Name {
+			t.Errorf("schema of tag %s not found by name", s.Name)
+		}
+	}
+	if names["Result"] {
+		t.Error("Result tag is edited by a select, it should not be in the schema")
+	}
+	if got := pgnTagSchemaByName("Opening"); got != nil {
+		t.Errorf("unknown tag Opening has schema %v", got.Name)
+	}
+}
+
+func TestValidatePGNTagName(t *testing.T) {
+	existing := []string{"Event", "Site", "Opening"}
+	for _, tc := range []struct {
+		name  string
+		valid bool
+	}{
+		{"ECO", true},
+		{"PlyCount", true},
+		{"Custom_Tag2", true},
+		{"2ndArbiter", true},
+		{"Result", false},
+		{"Event", false},
+		{"Opening", false},
+		{"", false},
+		{"_Tag", false},
+		{"My Tag", false},
+		{"Tag\"", false},
+	} {
+		if err := validatePGNTagName(tc.name, existing); (err == nil) != tc.valid {
+			t.Errorf("tag name %q: error %v, want valid %t", tc.name, err, tc.valid)
+		}
+	}
+}
+
+func TestPGNTermination(t *testing.T) {
+	for _, tc := range []struct {
+		status game.GameStatus
+		want   string
+	}{
+		{game.InProgress, "unterminated"},
+		{game.WhiteCheckmated, "normal"},
+		{game.BlackResigned, "normal"},
+		{game.Stalemate, "normal"},
+		{game.Threefold, "normal"},
+		{game.WhiteTimedOut, "time forfeit"},
+		{game.BlackTimedOut, "time forfeit"},
+		{game.WhiteIllegalMove, "rules infraction"},
+		{game.BlackIllegalMove, "rules infraction"},
+	} {
+		if got := pgnTermination(tc.status); got != tc.want {
+			t.Errorf("termination of status %v is %q, want %q", tc.status, got, tc.want)
+		}
+	}
+}
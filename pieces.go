@@ -2,7 +2,6 @@ package main
 
 import (
 	"URLchess/shf"
-	"strings"
 
 	"github.com/andrewbackes/chess/piece"
 )
@@ -33,14 +32,7 @@ var pieceNamesToType map[string]piece.Type = func() map[string]piece.Type {
 
 func pieceElement(tools *shf.Tools, p piece.Piece) shf.Element {
 	elm := tools.CreateElement("span")
-	elm.Get("classList").Call("add", "piece")
-	if p.Color != piece.NoColor {
-		elm.Get("classList").Call("add", strings.ToLower(p.Color.String()))
-	}
-	if p.Type != piece.None {
-		elm.Get("classList").Call("add", pieceTypesToName[p.Type])
-	}
-	elm.Set("textContent", p.Figurine())
+	renderPiece(elm.Object(), p)
 	return elm
 }
 
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"URLchess/shf"
+	"URLchess/shf/js"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Replay speeds, delay between half-moves in milliseconds & label.
+var replaySpeeds = [][2]string{
+	{"2000", "0.5×"},
+	{"1000", "1×"},
+	{"500", "2×"},
+	{"250", "4×"},
+}
+
+// Replay of the received game with play/pause button, scrub slider over half-moves and speed select.
+// Model sets events, replay is driven by Model's startReplay & stopReplay.
+type StatusReplay struct {
+	shf.Element
+	Play   shf.Element
+	Slider shf.Element
+	Speed  shf.Element
+
+	Playing bool
+	// Plays the next half-move.
+	timer *shf.Timer
+
+	refGame *ChessGameModel
+}
+
+func (sr *StatusReplay) Init(tools *shf.Tools) error {
+	if sr.Play == nil {
+		sr.Play = tools.CreateElement("button")
+		sr.Play.Get("classList").Call("add", "play")
+	}
+	if sr.Slider == nil {
+		sr.Slider = tools.CreateElement("input")
+		sr.Slider.Set("type", "range")
+		sr.Slider.Set("min", 0)
+		sr.Slider.Set("step", 1)
+		sr.Slider.Call("setAttribute", "aria-label", tr("Replay position"))
+	}
+	if sr.Speed == nil {
+		sr.Speed = tools.CreateElement("select")
+		sr.Speed.Call("setAttribute", "aria-label", tr("Replay speed"))
+		for _, speed := range replaySpeeds {
+			option := tools.CreateElement("option")
+			option.Set("value", speed[0])
+			option.Set("textContent", speed[1])
+			sr.Speed.Call("appendChild", option.Object())
+		}
+		sr.Speed.Set("value", replaySpeeds[1][0])
+	}
+
+	if sr.Element == nil {
+		sr.Element = tools.CreateElement("div")
+		sr.Set("id", "game-status-replay")
+		sr.Call("appendChild", sr.Play.Object())
+		sr.Call("appendChild", sr.Slider.Object())
+		sr.Call("appendChild", sr.Speed.Object())
+	}
+	return nil
+}
+
+func (sr *StatusReplay) Update(tools *shf.Tools) error {
+	if sr == nil {
+		return errors.New("StatusReplay is nil")
+	}
+	if sr.refGame == nil {
+		return errors.New("StatusReplay.Update: refGame is nil")
+	}
+
+	halfMoves := len(sr.refGame.initialGame.Positions) - 1
+	halfMove, _ := sr.refGame.initialHalfMove()
+
+	sr.Slider.Set("max", halfMoves)
+	sr.Slider.Set("value", halfMove)
+	sr.Slider.Call("setAttribute", "aria-valuetext", tr("half-move %s of %s", strconv.Itoa(halfMove), strconv.Itoa(halfMoves)))
+
+	if halfMoves == 0 {
+		sr.Play.Call("setAttribute", "disabled", "disabled")
+		sr.Slider.Call("setAttribute", "disabled", "disabled")
+	} else {
+		sr.Play.Call("removeAttribute", "disabled")
+		sr.Slider.Call("removeAttribute", "disabled")
+	}
+
+	label := tr("Play replay")
+	if sr.Playing {
+		label = tr("Pause replay")
+		sr.Play.Get("classList").Call("add", "playing")
+	} else {
+		sr.Play.Get("classList").Call("remove", "playing")
+	}
+	sr.Play.Call("setAttribute", "aria-label", label)
+	sr.Play.Call("setAttribute", "title", label)
+
+	return nil
+}
+
+// Returns delay between replayed half-moves, as chosen in speed select.
+func (sr *StatusReplay) delay() time.Duration {
+	ms, err := strconv.Atoi(sr.Speed.Get("value").String())
+	if err != nil || ms <= 0 {
+		ms, _ = strconv.Atoi(replaySpeeds[1][0])
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// Returns current half-move number in the initial (received) game.
+// Returns false, if the current game differs from the initial game.
+func (ch *ChessGameModel) initialHalfMove() (int, bool) {
+	n := ch.currMoveNo
+	if n >= len(ch.initialGame.Positions) {
+		n = len(ch.initialGame.Positions) - 1
+	}
+	for i := 1; i <= n; i++ {
+		if ch.Game.Positions[i].LastMove != ch.initialGame.Positions[i].LastMove {
+			return i - 1, false
+		}
+	}
+	return n, n == ch.currMoveNo
+}
+
+// Updates game to the half-move n of the initial game, without creating new browser history entry.
+func (m *Model) goToInitialHalfMove(tools *shf.Tools, n int) error {
+	if n < 0 || n >= len(m.ChessGame.initialGame.Positions) {
+		return errors.New("half-move " + strconv.Itoa(n) + " is out of initial game")
+	}
+	hash, err := m.ChessGame.browseHashForInitialHalfMove(n)
+	if err != nil {
+		return err
+	}
+	if err := m.ChessGame.UpdateToHash(hash); err != nil {
+		return err
+	}
+	// Location hash equals game hash with its conditional replies, so HashChange does nothing.
+	js.Global().Get("location").Call("replace", "#"+m.ChessGame.Hash())
+
+	m.Html.Cover.MoveStatus.Shown = false
+	return m.Html.Cover.GameStatus.rebuild(tools)
+}
+
+// Starts replay from the current half-move, or from the game start, if the current half-move is the last one or not in the initial game.
+func (m *Model) startReplay(tools *shf.Tools) error {
+	replay := m.Html.Cover.GameStatus.Replay
+	last := len(m.ChessGame.initialGame.Positions) - 1
+	if last == 0 {
+		return nil
+	}
+	if n, ok := m.ChessGame.initialHalfMove(); !ok || n >= last {
+		if err := m.goToInitialHalfMove(tools, 0); err != nil {
+			return err
+		}
+	}
+	replay.Playing = true
+	m.scheduleReplayStep(tools)
+	return nil
+}
+
+// Stops replay, if playing.
+func (m *Model) stopReplay() {
+	replay := m.Html.Cover.GameStatus.Replay
+	replay.Playing = false
+	replay.timer.Cancel()
+}
+
+func (m *Model) scheduleReplayStep(tools *shf.Tools) {
+	replay := m.Html.Cover.GameStatus.Replay
+	replay.timer.Cancel()
+	replay.timer = tools.After(replay.delay(), shf.TimerOptions{Owner: replay}, func() {
+		if !replay.Playing {
+			return
+		}
+		last := len(m.ChessGame.initialGame.Positions) - 1
+		n, ok := m.ChessGame.initialHalfMove()
+		if !ok || n >= last {
+			m.stopReplay()
+			return
+		}
+		if err := m.goToInitialHalfMove(tools, n+1); err != nil {
+			m.stopReplay()
+			m.Html.Notification.Message(tools, err.Error(), "")
+			return
+		}
+		m.playLastMoveSound()
+		if n+1 >= last {
+			m.stopReplay()
+			return
+		}
+		m.scheduleReplayStep(tools)
+	})
+}
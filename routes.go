@@ -0,0 +1,89 @@
+package main
+
+import (
+	"URLchess/shf"
+	"URLchess/shf/js"
+	"URLchess/urlchess"
+	"errors"
+	"strings"
+)
+
+// Location hash paths of views. A hash without path holds game moves, as do all links from before the paths were introduced.
+// Generated game links stay without path, so older versions of URLchess can open them too.
+//
+//	#<moves>          game
+//	#/game/<moves>    game, see urlchess.GamePath
+//	#/settings        settings over the current game
+const settingsPath = "/settings"
+
+func (m *Model) newRouter(tools *shf.Tools) *shf.Router {
+	return shf.NewRouter().
+		Handle(urlchess.GamePath, func(r shf.Route) error {
+			return m.showGameHash(tools, r.Param)
+		}).
+		Handle(settingsPath, func(_ shf.Route) error {
+			m.openSettingsView()
+			return tools.MarkDirty(m.Html.Notification, m.Html.Settings)
+		}).
+		Fallback(func(r shf.Route) error {
+			if err := unknownRouteError(r); err != nil {
+				return m.showInvalidLink(tools, err)
+			}
+			return m.showGameHash(tools, r.Param)
+		})
+}
+
+// Returns error, if route has a path, which is not known to the router.
+func unknownRouteError(r shf.Route) error {
+	if r.Path == "" && strings.HasPrefix(r.Hash, "/") {
+		return errors.New("unknown page: #" + r.Hash)
+	}
+	return nil
+}
+
+// Returns game hash (moves & conditional replies) of route, empty if the route is not a game.
+func routeGameHash(r shf.Route) string {
+	if r.Path == settingsPath || unknownRouteError(r) != nil {
+		return ""
+	}
+	return r.Param
+}
+
+// Shows settings over the current game. The location hash is replaced by the game hash, so the game link can be copied & closing settings keeps the game.
+func (m *Model) openSettingsView() {
+	m.Html.Notification.Shown = false
+	m.Html.Settings.Shown = true
+	js.Global().Get("location").Call("replace", "#"+m.ChessGame.Hash())
+}
+
+// Updates game to the hash, if it differs from the current game.
+func (m *Model) showGameHash(tools *shf.Tools, hash string) error {
+	hash = strings.TrimPrefix(hash, "#")
+	if hash == m.ChessGame.Hash() {
+		// Equal, do nothing.
+		return nil
+	}
+
+	// User navigated elsewhere, replay is over.
+	m.stopReplay()
+
+	// Update game to the hash.
+	if err := m.ChessGame.UpdateToHash(hash); err != nil {
+		return m.showInvalidLink(tools, err)
+	}
+
+	m.Html.Cover.GameStatus.rebuild(tools)
+	// Close move status after game is updated.
+	m.Html.Cover.MoveStatus.Shown = false
+	m.playLastMoveSound()
+	m.showConditionalReplyNotification(tools)
+
+	return tools.MarkDirty(m.Html.game)
+}
+
+// Replaces location hash with the game hash and tells the player, why the link could not be opened.
+func (m *Model) showInvalidLink(tools *shf.Tools, err error) error {
+	js.Global().Get("location").Call("replace", "#"+m.ChessGame.Hash())
+	m.Html.Notification.Message(tools, tr("Invalid game link"), err.Error())
+	return tools.MarkDirty(m.Html.Notification)
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"URLchess/shf"
+	"URLchess/shf/js"
+	"errors"
+	"strconv"
+)
+
+// Prefix of local storage keys for stored settings.
+const settingsStoragePrefix = "URLchess."
+
+// Setting is a user preference, which is stored in browser's local storage and can be changed in settings overlay.
+type Setting interface {
+	// Name is unique for every setting and is used as local storage key (with prefix) & element id.
+	Name() string
+	// Title is shown in settings overlay (translated).
+	Title() string
+	// If true, the setting can not be changed.
+	Disabled() bool
+	// If true, page is reloaded after the setting is changed.
+	NeedsReload() bool
+
+	// Loads value from local storage, or sets default, if nothing is stored.
+	load()
+	// Calls apply function with current value.
+	apply()
+	// Returns form control for the setting.
+	createControl(tools *shf.Tools) shf.Element
+	// Sets value in control to current value.
+	updateControl(control shf.Element)
+	// Sets & stores value from control.
+	controlChanged(control shf.Element)
+}
+
+// BoolSetting is an on/off setting, shown as a checkbox.
+type BoolSetting struct {
+	Key     string
+	Label   string
+	Default bool
+	Off     bool // setting can not be changed
+	Reload  bool // reload page after change
+	// Called on settings application at start & after every change.
+	Apply func(value bool)
+
+	value bool
+}
+
+func (s *BoolSetting) Name() string      { return s.Key }
+func (s *BoolSetting) Title() string     { return tr(s.Label) }
+func (s *BoolSetting) Disabled() bool    { return s.Off }
+func (s *BoolSetting) NeedsReload() bool { return s.Reload }
+
+func (s *BoolSetting) Get() bool {
+	return s.value
+}
+
+// Set changes, stores and applies the value.
+func (s *BoolSetting) Set(value bool) {
+	s.value = value
+	store(settingsStoragePrefix+s.Key, strconv.FormatBool(value))
+	s.apply()
+}
+
+func (s *BoolSetting) load() {
+	s.value = s.Default
+	if stored, err := strconv.ParseBool(loadStored(settingsStoragePrefix + s.Key)); err == nil {
+		s.value = stored
+	}
+}
+func (s *BoolSetting) apply() {
+	if s.Apply != nil {
+		s.Apply(s.value)
+	}
+}
+func (s *BoolSetting) createControl(tools *shf.Tools) shf.Element {
+	control := tools.CreateElement("input")
+	control.Set("type", "checkbox")
+	return control
+}
+func (s *BoolSetting) updateControl(control shf.Element) {
+	control.Set("checked", s.value)
+}
+func (s *BoolSetting) controlChanged(control shf.Element) {
+	s.Set(control.Get("checked").Bool())
+}
+
+// ChoiceSetting is a setting with one value from a list of choices, shown as a select.
+type ChoiceSetting struct {
+	Key     string
+	Label   string
+	Choices [][2]string // value & label (translated when shown)
+	Default string
+	Off     bool // setting can not be changed
+	Reload  bool // reload page after change
+	// Called on settings application at start & after every change.
+	Apply func(value string)
+
+	value string
+}
+
+func (s *ChoiceSetting) Name() string      { return s.Key }
+func (s *ChoiceSetting) Title() string     { return tr(s.Label) }
+func (s *ChoiceSetting) Disabled() bool    { return s.Off }
+func (s *ChoiceSetting) NeedsReload() bool { return s.Reload }
+
+func (s *ChoiceSetting) Get() string {
+	return s.value
+}
+
+// Set changes, stores and applies the value. Values not in choices are ignored.
+func (s *ChoiceSetting) Set(value string) {
+	if !s.valid(value) {
+		return
+	}
+	s.value = value
+	store(settingsStoragePrefix+s.Key, value)
+	s.apply()
+}
+
+func (s *ChoiceSetting) valid(value string) bool {
+	for _, c := range s.Choices {
+		if c[0] == value {
+			return true
+		}
+	}
+	return false
+}
+func (s *ChoiceSetting) load() {
+	s.value = s.Default
+	if stored := loadStored(settingsStoragePrefix + s.Key); stored != "" && s.valid(stored) {
+		s.value = stored
+	}
+}
+func (s *ChoiceSetting) apply() {
+	if s.Apply != nil {
+		s.Apply(s.value)
+	}
+}
+func (s *ChoiceSetting) createControl(tools *shf.Tools) shf.Element {
+	control := tools.CreateElement("select")
+	for _, c := range s.Choices {
+		option := tools.CreateElement("option")
+		option.Set("textContent", tr(c[1]))
+		option.Set("value", c[0])
+		control.Call("appendChild", option.Object())
+	}
+	return control
+}
+func (s *ChoiceSetting) updateControl(control shf.Element) {
+	control.Set("value", s.value)
+}
+func (s *ChoiceSetting) controlChanged(control shf.Element) {
+	s.Set(control.Get("value").String())
+}
+
+// Settings hold detected browser capabilities & user preferences.
+type Settings struct {
+	// Browser capabilities, detected on start, not stored.
+	RotationSupported bool
+	ExecSupported     bool
+
+	AutoRotate     *BoolSetting
+	ZenMode        *BoolSetting
+	HighContrast   *BoolSetting
+	AlwaysQueen    *BoolSetting
+	SkipMoveStatus *BoolSetting
+	History        *ChoiceSetting
+	ShowTips       *BoolSetting
+	Sounds         *BoolSetting
+	Language       *ChoiceSetting
+	LocalizedSAN   *BoolSetting
+	BoardTheme     *ChoiceSetting
+	PieceSet       *ChoiceSetting
+	ColorScheme    *ChoiceSetting
+
+	registered []Setting
+	tipNo      int
+}
+
+// Returns settings with all default settings registered & loaded from local storage.
+// New settings can be added with Register.
+func NewSettings() *Settings {
+	s := &Settings{}
+
+	s.AutoRotate = &BoolSetting{
+		Key:     "autoRotate",
+		Label:   "Rotate board for player on the move automatically",
+		Default: true,
+	}
+	s.ZenMode = &BoolSetting{
+		Key:   "zenMode",
+		Label: "Zen mode",
+		Apply: func(value bool) {
+			js.Global().Get("document").Get("body").Get("classList").Call("toggle", "zen-mode", value)
+		},
+	}
+	s.HighContrast = &BoolSetting{
+		Key:     "highContrast",
+		Label:   "High contrast markers",
+		Default: prefersHighContrast(),
+		Apply:   setHighContrast,
+	}
+	s.AlwaysQueen = &BoolSetting{
+		Key:   "alwaysQueen",
+		Label: "Always promote to queen",
+	}
+	s.SkipMoveStatus = &BoolSetting{
+		Key:   "skipMoveStatus",
+		Label: "Skip game link dialog after move",
+	}
+	s.History = &ChoiceSetting{
+		Key:     "history",
+		Label:   "Browser history",
+		Choices: historyModes,
+		Default: historyModes[0][0],
+	}
+	s.ShowTips = &BoolSetting{
+		Key:     "showTips",
+		Label:   "Show tips",
+		Default: true,
+	}
+	s.Sounds = &BoolSetting{
+		Key:     "sounds",
+		Label:   "Sound effects",
+		Default: true,
+	}
+
+	languageChoices := [][2]string{{"", "automatic"}}
+	s.Language = &ChoiceSetting{
+		Key:     "language",
+		Label:   "Language",
+		Choices: append(languageChoices, languages...),
+		Reload:  true,
+		Apply:   setLanguage,
+	}
+	s.LocalizedSAN = &BoolSetting{
+		Key:    "localizedSAN",
+		Label:  "Localized piece letters in moves",
+		Reload: true,
+		Apply: func(value bool) {
+			localizedSAN = value
+		},
+	}
+	s.BoardTheme = &ChoiceSetting{
+		Key:     "boardTheme",
+		Label:   "Board colours",
+		Choices: boardThemes,
+		Default: boardThemes[0][0],
+		Apply:   setBoardTheme,
+	}
+	pieceSetChoices := [][2]string{}
+	for _, ps := range pieceSets {
+		pieceSetChoices = append(pieceSetChoices, [2]string{ps.Id, ps.Label})
+	}
+	s.PieceSet = &ChoiceSetting{
+		Key:     "pieceSet",
+		Label:   "Pieces",
+		Choices: pieceSetChoices,
+		Default: pieceSets[0].Id,
+		Apply:   setPieceSet,
+	}
+	s.ColorScheme = &ChoiceSetting{
+		Key:     "colorScheme",
+		Label:   "Colour scheme",
+		Choices: colorSchemes,
+		Default: colorSchemes[0][0],
+		Apply:   setColorScheme,
+	}
+
+	for _, setting := range []Setting{
+		s.Language, s.LocalizedSAN,
+		s.AutoRotate, s.AlwaysQueen, s.SkipMoveStatus, s.History, s.ShowTips, s.Sounds,
+		s.ZenMode, s.HighContrast, s.BoardTheme, s.PieceSet, s.ColorScheme,
+	} {
+		s.Register(setting)
+	}
+
+	if tipNo, err := strconv.Atoi(loadStored(settingsStoragePrefix + "tipNo")); err == nil {
+		s.tipNo = tipNo
+	}
+	return s
+}
+
+// Register loads setting value and adds it to settings overlay.
+// Settings need to be registered before the settings overlay is built.
+func (s *Settings) Register(setting Setting) error {
+	for _, r := range s.registered {
+		if r.Name() == setting.Name() {
+			return errors.New("setting " + setting.Name() + " is already registered")
+		}
+	}
+	setting.load()
+	s.registered = append(s.registered, setting)
+	return nil
+}
+
+// Registered returns all registered settings in order of registration.
+func (s *Settings) Registered() []Setting {
+	return s.registered
+}
+
+// Apply applies all registered settings. Needs to be called before the model is created, cause some texts and pieces depend on settings.
+func (s *Settings) Apply() {
+	for _, setting := range s.registered {
+		setting.apply()
+	}
+}
+
+// NextTip returns index of next tip to show from count tips. The tip counter is stored, so tips continue after reload.
+func (s *Settings) NextTip(count int) int {
+	if count <= 0 {
+		return 0
+	}
+	no := s.tipNo % count
+	s.tipNo = (no + 1) % count
+	store(settingsStoragePrefix+"tipNo", strconv.Itoa(s.tipNo))
+	return no
+}
+
+// Returns value stored in local storage under key, or empty string if there is none.
+func loadStored(key string) string {
+	storage := js.Global().Get("localStorage")
+	if js.IsUndefined(storage) || js.IsNull(storage) {
+		return ""
+	}
+	value := storage.Call("getItem", key)
+	if js.IsUndefined(value) || js.IsNull(value) {
+		return ""
+	}
+	return value.String()
+}
+
+// Stores value in local storage under key. Empty value removes the key.
+func store(key, value string) {
+	storage := js.Global().Get("localStorage")
+	if js.IsUndefined(storage) || js.IsNull(storage) {
+		return
+	}
+	if value == "" {
+		storage.Call("removeItem", key)
+		return
+	}
+	storage.Call("setItem", key, value)
+}
+
+type settingRow struct {
+	setting Setting
+	control shf.Element
+}
+
+// Settings overlay, with a row for every registered setting.
+type ModelSettings struct {
+	shf.Element
+	Shown bool
+
+	Close shf.Element
+
+	refSettings *Settings
+	refModel    *HtmlModel
+	list        shf.Element
+	rows        []settingRow
+	focus       dialogFocus
+}
+
+func (this *ModelSettings) Init(tools *shf.Tools) error {
+	if this.Close == nil {
+		this.Close = tools.CreateElement("button")
+		this.Close.Set("textContent", tr("close"))
+		if err := tools.Click(this.Close, func(_ shf.Event) error {
+			this.Shown = false
+			return tools.MarkDirty(this)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if this.list == nil {
+		this.list = tools.CreateElement("div")
+		this.list.Get("classList").Call("add", "list")
+	}
+
+	if this.Element == nil {
+		this.Element = tools.CreateElement("div")
+		this.Set("id", "settings-overlay")
+		this.Get("classList").Call("add", "invisible")
+		this.Call("setAttribute", "role", "dialog")
+		this.Call("setAttribute", "aria-modal", "true")
+		this.Call("setAttribute", "aria-label", tr("Settings"))
+		if err := tools.Click(this.Element, func(e shf.Event) error {
+			// Clicks on settings controls bubble here too. Updating the overlay would reset a clicked checkbox before its input event.
+			if e.Get("target").Get("id").String() != "settings-overlay" {
+				return nil
+			}
+			this.Shown = false
+			return tools.MarkDirty(this)
+		}); err != nil {
+			return err
+		}
+
+		header := tools.CreateElement("p")
+		header.Get("classList").Call("add", "header")
+		header.Set("textContent", tr("Settings"))
+
+		buttons := tools.CreateElement("p")
+		buttons.Get("classList").Call("add", "buttons")
+		buttons.Call("appendChild", this.Close.Object())
+
+		panel := tools.CreateElement("div")
+		panel.Get("classList").Call("add", "settings")
+		panel.Call("appendChild", header.Object())
+		panel.Call("appendChild", this.list.Object())
+		panel.Call("appendChild", buttons.Object())
+
+		this.Call("appendChild", panel.Object())
+	}
+	return nil
+}
+
+// Creates rows for all registered settings.
+func (this *ModelSettings) rebuild(tools *shf.Tools) error {
+	if this.refSettings == nil {
+		return errors.New("ModelSettings.rebuild: refSettings is nil")
+	}
+
+	for _, row := range this.rows {
+		tools.Destroy(row.control)
+	}
+	this.rows = nil
+	tools.DestroyChildren(this.list)
+
+	for _, setting := range this.refSettings.Registered() {
+		row := settingRow{setting, setting.createControl(tools)}
+		id := "setting-" + setting.Name()
+		row.control.Set("id", id)
+		if setting.Disabled() {
+			row.control.Call("setAttribute", "disabled", "disabled")
+		}
+		if err := tools.Input(row.control, func(_ shf.Event) error {
+			row.setting.controlChanged(row.control)
+			if row.setting.NeedsReload() {
+				js.Global().Get("location").Call("reload")
+				return nil
+			}
+			// Settings can change anything shown, except header & footer.
+			return tools.MarkDirty(this, this.refModel.game, this.refModel.Export)
+		}); err != nil {
+			return err
+		}
+
+		label := tools.CreateElement("label")
+		label.Set("htmlFor", id)
+		label.Set("textContent", setting.Title())
+
+		p := tools.CreateElement("p")
+		p.Get("classList").Call("add", "setting")
+		p.Call("appendChild", label.Object())
+		p.Call("appendChild", row.control.Object())
+		this.list.Call("appendChild", p.Object())
+
+		this.rows = append(this.rows, row)
+	}
+	return nil
+}
+
+func (this *ModelSettings) Update(tools *shf.Tools) error {
+	if this == nil {
+		return errors.New("ModelSettings is nil")
+	}
+
+	for _, row := range this.rows {
+		row.setting.updateControl(row.control)
+	}
+
+	if this.Shown {
+		this.Get("classList").Call("remove", "invisible")
+		this.Call("setAttribute", "aria-hidden", "false")
+	} else {
+		this.Get("classList").Call("add", "invisible")
+		this.Call("setAttribute", "aria-hidden", "true")
+	}
+	var focus shf.Element
+	if len(this.rows) > 0 {
+		focus = this.rows[0].control
+	}
+	this.focus.update(this.Shown, focus)
+	return nil
+}
@@ -0,0 +1,164 @@
+package shf
+
+import (
+	"URLchess/shf/js"
+	"time"
+)
+
+// Easing maps linear animation progress <0, 1> to eased progress.
+type Easing func(t float64) float64
+
+func EaseLinear(t float64) float64 { return t }
+func EaseInOut(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return 1 - (-2*t+2)*(-2*t+2)/2
+}
+func EaseOut(t float64) float64 { return 1 - (1-t)*(1-t) }
+
+// Animation calls frame function on every browser animation frame with eased progress <0, 1>.
+// The last frame is always called with progress 1, after that the done function is called (if not nil).
+// Animations are not followed by app update, frame functions should change only presentation of elements.
+type Animation struct {
+	app      *App
+	target   Element
+	duration float64 // in milliseconds
+	easing   Easing
+	frame    func(progress float64)
+	done     func()
+	// Called, if the animation is stopped before its end.
+	cancelled func()
+
+	start   float64
+	next    *Timer
+	running bool
+}
+
+// Returns true, if user asked the browser to minimize non-essential motion.
+func PrefersReducedMotion() bool {
+	matchMedia := js.Global().Get("matchMedia")
+	if js.IsUndefined(matchMedia) || js.IsNull(matchMedia) {
+		return false
+	}
+	return js.Global().Call("matchMedia", "(prefers-reduced-motion: reduce)").Get("matches").Bool()
+}
+
+// Starts animation of target element. A running animation of the same target is finished first.
+// If reduced motion is preferred, or animation frames are not supported, the animation jumps to the end immediately.
+func (app *App) Animate(target Element, duration time.Duration, easing Easing, frame func(progress float64), done func()) *Animation {
+	if app.animations == nil {
+		app.animations = map[Element]*Animation{}
+	}
+	target = app.resolve(target)
+	if running, ok := app.animations[target]; ok {
+		running.Finish()
+	}
+	if easing == nil {
+		easing = EaseLinear
+	}
+	if frame == nil {
+		frame = func(float64) {}
+	}
+
+	a := &Animation{
+		app:      app,
+		target:   target,
+		duration: float64(duration / time.Millisecond),
+		easing:   easing,
+		frame:    frame,
+		done:     done,
+		start:    -1,
+	}
+
+	if a.duration <= 0 || PrefersReducedMotion() || js.IsUndefined(js.Global().Get("requestAnimationFrame")) {
+		a.end()
+		return a
+	}
+
+	a.running = true
+	app.animations[target] = a
+	a.frame(a.easing(0))
+	a.requestFrame()
+	return a
+}
+
+func (a *Animation) requestFrame() {
+	// Frames change only presentation, no app update is needed.
+	a.next = a.app.AnimationFrame(TimerOptions{Owner: a.target, NoUpdate: true}, a.step)
+}
+
+func (a *Animation) step(timestamp float64) {
+	if !a.running {
+		return
+	}
+	if a.start < 0 {
+		a.start = timestamp
+	}
+	t := (timestamp - a.start) / a.duration
+	if t >= 1 {
+		a.Finish()
+		return
+	}
+	done := false
+	defer func() {
+		if !done {
+			// Frame function panicked, do not request more frames from a broken animation.
+			a.Cancel()
+		}
+	}()
+	a.frame(a.easing(t))
+	done = true
+	a.requestFrame()
+}
+
+// Jumps to the end of the animation, if it is running.
+func (a *Animation) Finish() {
+	if a == nil || !a.running {
+		return
+	}
+	a.release()
+	a.end()
+}
+
+// Stops the animation where it is, without calling the last frame & done functions.
+// Animation is cancelled also when its target element is destroyed, or when its frame function fails.
+func (a *Animation) Cancel() {
+	if a == nil || !a.running {
+		return
+	}
+	a.release()
+	if a.cancelled != nil {
+		a.cancelled()
+	}
+}
+
+// OnCancel sets function called when the animation is cancelled, e.g. to clean up what the done function would.
+func (a *Animation) OnCancel(cancelled func()) *Animation {
+	if a != nil {
+		a.cancelled = cancelled
+	}
+	return a
+}
+
+func (a *Animation) release() {
+	a.running = false
+	a.next.Cancel()
+	if a.app.animations[a.target] == a {
+		delete(a.app.animations, a.target)
+	}
+}
+
+func (a *Animation) end() {
+	a.frame(a.easing(1))
+	if a.done != nil {
+		a.done()
+	}
+}
+
+// Finishes all running animations.
+func (app *App) FinishAnimations() {
+	for _, a := range app.animations {
+		a.Finish()
+	}
+}
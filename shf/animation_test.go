@@ -0,0 +1,43 @@
+//go:build !js || (!ecmascript && !wasm)
+
+package shf_test
+
+import (
+	"URLchess/shf"
+	"URLchess/shf/js"
+	"testing"
+	"time"
+)
+
+func TestAnimationEnds(t *testing.T) {
+	app := newApp(t)
+	target := app.Tools().CreateElement("div")
+
+	frames, done, cancelled := 0, 0, 0
+	app.Animate(target, 100*time.Millisecond, shf.EaseLinear, func(float64) { frames++ }, func() { done++ }).OnCancel(func() { cancelled++ })
+	js.AdvanceTime(time.Second)
+	if frames < 2 || done != 1 || cancelled != 0 {
+		t.Errorf("frames %d, done %d, cancelled %d, want some frames, done once and no cancel", frames, done, cancelled)
+	}
+}
+
+func TestAnimationCancelledWithTarget(t *testing.T) {
+	app := newApp(t)
+	target := app.Tools().CreateElement("div")
+
+	done, cancelled := 0, 0
+	app.Animate(target, time.Second, shf.EaseLinear, func(float64) {}, func() { done++ }).OnCancel(func() {
+		cancelled++
+		// Destroying the target again from the cancel function is fine.
+		app.DestroyElement(target)
+	})
+	js.AdvanceTime(100 * time.Millisecond)
+	app.DestroyElement(target)
+	js.AdvanceTime(2 * time.Second)
+	if done != 0 || cancelled != 1 {
+		t.Errorf("done %d, cancelled %d, want only cancelled once", done, cancelled)
+	}
+	if got := app.DebugCounters(); got.Elements != 0 || got.Timers != 0 || got.Animations != 0 {
+		t.Errorf("counters after destroying animated element: %+v", got)
+	}
+}
@@ -4,7 +4,6 @@ package shf
 import (
 	"URLchess/shf/js"
 	"errors"
-	"strconv"
 	"time"
 )
 
@@ -35,9 +34,32 @@ func (t *Tools) Initialize(updaters ...Updater) error {
 	}
 	return nil
 }
+
+// AppUpdate updates the whole app. In event handlers and timer callbacks, the update is done after the handler returns.
 func (t *Tools) AppUpdate() error {
+	if t.app.handling > 0 {
+		t.app.dirtyApp = true
+		return nil
+	}
 	return t.app.Update()
 }
+
+// MarkDirty marks components, which state has changed, for update. In event handlers and timer callbacks,
+// only marked components are updated after the handler returns, in order of marking. Elsewhere they are updated immediately.
+func (t *Tools) MarkDirty(updaters ...Updater) error {
+	if t.app.handling > 0 {
+		t.app.markDirty(updaters...)
+		return nil
+	}
+	return t.Update(updaters...)
+}
+
+// BeforeUpdate registers callback, which is called once after every event handler and timer callback, that marked something for update,
+// before the update is done. The callback can mark more components dirty, e.g. components showing something new on every interaction.
+func (t *Tools) BeforeUpdate(callback func()) {
+	t.app.beforeUpdate = append(t.app.beforeUpdate, callback)
+}
+
 func (t *Tools) Update(updaters ...Updater) error {
 	for _, updater := range updaters {
 		if updater == nil {
@@ -79,28 +101,107 @@ func (t *Tools) DblClickRemove(target Element) error {
 func (t *Tools) HashChange(function func(HashChangeEvent) error) error {
 	return t.app.HashChange(function)
 }
+func (t *Tools) Route(router *Router) error {
+	return t.app.Route(router)
+}
+func (t *Tools) KeyDown(target Element, function func(e KeyboardEvent) error) error {
+	return t.app.KeyDown(target, function)
+}
+func (t *Tools) KeyUp(target Element, function func(e KeyboardEvent) error) error {
+	return t.app.KeyUp(target, function)
+}
+func (t *Tools) PointerDown(target Element, function func(e PointerEvent) error) error {
+	return t.app.PointerDown(target, function)
+}
+func (t *Tools) PointerMove(target Element, function func(e PointerEvent) error) error {
+	return t.app.PointerMove(target, function)
+}
+func (t *Tools) PointerUp(target Element, function func(e PointerEvent) error) error {
+	return t.app.PointerUp(target, function)
+}
+func (t *Tools) PointerCancel(target Element, function func(e PointerEvent) error) error {
+	return t.app.PointerCancel(target, function)
+}
+func (t *Tools) PointerDownRemove(target Element) error {
+	return t.app.PointerDown(target, nil)
+}
+func (t *Tools) PointerMoveRemove(target Element) error {
+	return t.app.PointerMove(target, nil)
+}
+func (t *Tools) PointerUpRemove(target Element) error {
+	return t.app.PointerUp(target, nil)
+}
+func (t *Tools) PointerCancelRemove(target Element) error {
+	return t.app.PointerCancel(target, nil)
+}
+func (t *Tools) KeyDownRemove(target Element) error {
+	return t.app.KeyDown(target, nil)
+}
+func (t *Tools) KeyUpRemove(target Element) error {
+	return t.app.KeyUp(target, nil)
+}
+
+// Listen registers function as an additional listener of event on target, see App.Listen.
+func (t *Tools) Listen(target Element, eventName string, options ListenerOptions, function func(e Event) error) (*Listener, error) {
+	return t.app.Listen(target, eventName, options, function)
+}
+func (t *Tools) RemoveListeners(target Element, eventName string) {
+	t.app.RemoveListeners(target, eventName)
+}
 func (t *Tools) CreateElement(etype string) Element {
 	return t.app.CreateElement(etype)
 }
 func (t *Tools) CreateTextNode(text string) js.Object {
 	return js.Global().Get("document").Call("createTextNode", text)
 }
+
+// DestroyChildren destroys all elements created inside elm and removes its content.
+func (t *Tools) DestroyChildren(elm Element) {
+	t.app.DestroyChildren(elm)
+}
 func (t *Tools) Destroylement(elm Element) {
 	t.app.DestroyElement(elm)
 }
 func (t *Tools) Created(elm Element) bool {
 	return t.app.ElementCreated(elm)
 }
-func (t *Tools) Timer(duration time.Duration, callback func()) int {
+func (t *Tools) DebugCounters() DebugCounters {
+	return t.app.DebugCounters()
+}
+func (t *Tools) Timer(duration time.Duration, callback func()) *Timer {
 	return t.app.Timer(duration, callback)
 }
+func (t *Tools) After(duration time.Duration, options TimerOptions, callback func()) *Timer {
+	return t.app.After(duration, options, callback)
+}
+func (t *Tools) Every(interval time.Duration, options TimerOptions, callback func()) *Timer {
+	return t.app.Every(interval, options, callback)
+}
+func (t *Tools) AnimationFrame(options TimerOptions, callback func(timestamp float64)) *Timer {
+	return t.app.AnimationFrame(options, callback)
+}
+func (t *Tools) Animate(target Element, duration time.Duration, easing Easing, frame func(progress float64), done func()) *Animation {
+	return t.app.Animate(target, duration, easing, frame, done)
+}
+func (t *Tools) FinishAnimations() {
+	t.app.FinishAnimations()
+}
 
 func Create(model Updater) (*App, error) {
 	app := &App{
 		model,
 		nil,
 		nil,
-		map[Element]struct{}{},
+		nil,
+		map[int]*element{},
+		0,
+		map[*Timer]struct{}{},
+		nil,
+		nil,
+		nil,
+		false,
+		nil,
+		0,
 	}
 	app.tools = &Tools{app}
 
@@ -114,10 +215,29 @@ func Create(model Updater) (*App, error) {
 }
 
 type App struct {
-	model   Updater
-	tools   *Tools
-	events  map[string]map[Element]js.Func
-	created map[Element]struct{}
+	model Updater
+	tools *Tools
+
+	// Listeners set by Click, KeyDown, ..., one per event and target.
+	events map[string]map[Element]*Listener
+	// All registered listeners.
+	listeners map[*Listener]struct{}
+
+	// Elements created by CreateElement by their id.
+	created       map[int]*element
+	lastCreatedId int
+	timers        map[*Timer]struct{}
+	animations    map[Element]*Animation
+
+	errorHandler func(err *Error)
+
+	// Components marked for update during handling of events and timers.
+	dirty    []Updater
+	dirtyApp bool
+	// Callbacks called before every update of handled events and timers, see Tools.BeforeUpdate.
+	beforeUpdate []func()
+	// Depth of running event handlers and timer callbacks.
+	handling int
 }
 
 func (app *App) Tools() *Tools { return app.tools }
@@ -133,26 +253,148 @@ func (app *App) Update() error {
 	}
 	return nil
 }
-func (app *App) CreateElement(etype string) Element {
-	elm := &element{CreateElementObject(etype)}
-	if _, ok := app.created[elm]; ok {
-		js.Global().Call("alert", "*App.CreateElement: an element can not be created twice the same. Why is this happening?")
-		return nil
+func (app *App) markDirty(updaters ...Updater) {
+	for _, updater := range updaters {
+		if updater == nil {
+			continue
+		}
+		marked := false
+		for _, d := range app.dirty {
+			if d == updater {
+				marked = true
+				break
+			}
+		}
+		if !marked {
+			app.dirty = append(app.dirty, updater)
+		}
+	}
+}
+
+// Runs handler of event on target and updates components marked dirty by it. If the whole app is marked, only app is updated, which updates all components.
+// If anything is marked, callbacks registered by Tools.BeforeUpdate are called before the update.
+// Errors and panics of the handler or update are passed to the error handler.
+func (app *App) handle(event string, target Element, handler func() error) {
+	app.handling++
+	err := recoverPanic(event, target, handler)
+	if err == nil && (app.dirtyApp || len(app.dirty) > 0) {
+		err = recoverPanic(event, target, func() error {
+			for _, callback := range app.beforeUpdate {
+				callback()
+			}
+			return nil
+		})
 	}
-	app.created[elm] = struct{}{}
+	app.handling--
+
+	dirty, dirtyApp := app.dirty, app.dirtyApp
+	app.dirty, app.dirtyApp = nil, false
+	if err == nil {
+		err = recoverPanic(event, target, func() error {
+			if dirtyApp {
+				return app.Update()
+			}
+			return app.tools.Update(dirty...)
+		})
+	}
+	app.ReportError(event, target, err)
+}
+
+// Property of element objects created by App, with the element's key in App.created.
+const createdIdProperty = "shfCreatedId"
+
+func (app *App) CreateElement(etype string) Element {
+	app.lastCreatedId++
+	elm := &element{CreateElementObject(etype), app.lastCreatedId}
+	elm.Set(createdIdProperty, elm.id)
+	app.created[elm.id] = elm
 	return elm
 }
 func (app *App) ElementCreated(elm Element) bool {
-	_, ok := app.created[elm]
-	return ok
+	return app.createdElement(elm) != nil
 }
+
+// Returns element created by app, which elm is or embeds, nil if there is none.
+func (app *App) createdElement(elm Element) *element {
+	if isNilElement(elm) {
+		return nil
+	}
+	return app.createdObject(elm.Object())
+}
+
+// Returns element created by app with object o, nil if o was not created by app.
+func (app *App) createdObject(o js.Object) *element {
+	id := o.Get(createdIdProperty)
+	if js.IsUndefined(id) || js.IsNull(id) {
+		return nil
+	}
+	return app.created[id.Int()]
+}
+
+// Returns the element created by app, which elm is or embeds, so a component and its element are the same to the App.
+// Elements not created by app (e.g. Window) are returned as they are, nil if elm is nil or a component without element.
+func (app *App) resolve(elm Element) Element {
+	if isNilElement(elm) {
+		return nil
+	}
+	if created := app.createdElement(elm); created != nil {
+		return created
+	}
+	return elm
+}
+
+// DestroyElement removes created element from document and releases its listeners, timers & animation.
+// Elements created inside it are destroyed too.
 func (app *App) DestroyElement(elm Element) {
-	if !app.ElementCreated(elm) {
+	created := app.createdElement(elm)
+	if created == nil {
 		return
 	}
-	delete(app.created, elm)
+	app.destroyDescendants(created)
+	app.destroy(created)
+}
+
+// DestroyChildren destroys all elements created inside elm and removes its content.
+func (app *App) DestroyChildren(elm Element) {
+	if isNilElement(elm) {
+		return
+	}
+	app.destroyDescendants(elm)
+	elm.Set("textContent", "")
+}
+
+// Destroys created elements inside elm. They are found by going through elm's descendants in document, not through all created elements.
+func (app *App) destroyDescendants(elm Element) {
+	// Find all descendants first, removing one from document removes its descendants from elm too.
+	nodes := elm.Call("querySelectorAll", "*")
+	descendants := []*element{}
+	for i, n := 0, nodes.Get("length").Int(); i < n; i++ {
+		if c := app.createdObject(nodes.Call("item", i)); c != nil {
+			descendants = append(descendants, c)
+		}
+	}
+	for _, c := range descendants {
+		app.destroy(c)
+	}
+}
+func (app *App) destroy(elm *element) {
+	// Forget the element first, so functions called on release (e.g. animation's cancel) see it destroyed already.
+	delete(app.created, elm.id)
+	app.release(elm)
+	elm.Delete(createdIdProperty)
 	DestroyElementObject(elm.Object())
 }
+
+// Releases listeners, timers and animation of element.
+func (app *App) release(elm Element) {
+	app.RemoveListeners(elm, "")
+	for t := range app.timers {
+		if t.owner == elm {
+			t.Cancel()
+		}
+	}
+	app.animations[elm].Cancel()
+}
 func (app *App) HashChange(function func(HashChangeEvent) error) error {
 	return app.elventListener("hashchange", Window, func(e Event) error {
 		hce := &hashChangeEvent{e}
@@ -162,6 +404,40 @@ func (app *App) HashChange(function func(HashChangeEvent) error) error {
 		return nil
 	})
 }
+func (app *App) KeyDown(target Element, function func(KeyboardEvent) error) error {
+	return app.elventListener("keydown", target, keyboardEventFunction(function))
+}
+func (app *App) KeyUp(target Element, function func(KeyboardEvent) error) error {
+	return app.elventListener("keyup", target, keyboardEventFunction(function))
+}
+func keyboardEventFunction(function func(KeyboardEvent) error) func(Event) error {
+	if function == nil {
+		return nil
+	}
+	return func(e Event) error {
+		return function(&keyboardEvent{e})
+	}
+}
+func (app *App) PointerDown(target Element, function func(PointerEvent) error) error {
+	return app.elventListener("pointerdown", target, pointerEventFunction(function))
+}
+func (app *App) PointerMove(target Element, function func(PointerEvent) error) error {
+	return app.elventListener("pointermove", target, pointerEventFunction(function))
+}
+func (app *App) PointerUp(target Element, function func(PointerEvent) error) error {
+	return app.elventListener("pointerup", target, pointerEventFunction(function))
+}
+func (app *App) PointerCancel(target Element, function func(PointerEvent) error) error {
+	return app.elventListener("pointercancel", target, pointerEventFunction(function))
+}
+func pointerEventFunction(function func(PointerEvent) error) func(Event) error {
+	if function == nil {
+		return nil
+	}
+	return func(e Event) error {
+		return function(&pointerEvent{e})
+	}
+}
 func (app *App) Input(target Element, function func(e Event) error) error {
 	return app.elventListener("input", target, function)
 }
@@ -178,23 +454,23 @@ func (app *App) elventListener(eventName string, target Element, function func(e
 	if eventName == "" {
 		return errors.New("no event name")
 	}
+	target = app.resolve(target)
 	if target == nil {
 		return errors.New("no target")
 	}
 
 	if app.events == nil {
-		app.events = map[string]map[Element]js.Func{}
+		app.events = map[string]map[Element]*Listener{}
 	}
 
 	_, ok := app.events[eventName]
 	if !ok {
-		app.events[eventName] = map[Element]js.Func{}
+		app.events[eventName] = map[Element]*Listener{}
 	}
 
-	if registeredFunc, ok := app.events[eventName][target]; ok {
-		target.Call("removeEventListener", eventName, registeredFunc, false)
+	if registered, ok := app.events[eventName][target]; ok {
+		registered.Remove()
 		delete(app.events[eventName], target)
-		registeredFunc.Release()
 		//js.Global().Call("alert", "unregistered event: "+e.target.String()+":"+e.target.Get("id").String())
 	}
 
@@ -202,21 +478,11 @@ func (app *App) elventListener(eventName string, target Element, function func(e
 		return nil
 	}
 
-	jsEventCallback := js.FuncOf(func(_ js.Object, args []js.Object) any {
-		//TODO handle errors via app.ErrorCallback
-		if len(args) < 1 {
-			js.Global().Call("alert", eventName+" event function called with no arguments")
-			return errors.New(eventName + " event function called with no arguments")
-		}
-		if err := function(args[0]); err != nil {
-			js.Global().Call("alert", eventName+" event function returned error: "+err.Error())
-			return err
-		}
-		return nil
-	})
-
-	target.Call("addEventListener", eventName, jsEventCallback, false)
-	app.events[eventName][target] = jsEventCallback
+	listener, err := app.Listen(target, eventName, ListenerOptions{}, function)
+	if err != nil {
+		return err
+	}
+	app.events[eventName][target] = listener
 	//js.Global().Call("alert", "registered event: "+target.String()+":"+target.Get("id").String())
 	return nil
 }
@@ -227,18 +493,29 @@ func CreateElementObject(etype string) js.Object {
 func DestroyElementObject(o js.Object) {
 	o.Call("remove")
 }
-func (app *App) Timer(duration time.Duration, callback func()) int {
-	ms := int(duration / time.Millisecond) // in milliseconds
-	timeoutId := 0
-	timeoutId = js.Global().Call("setTimeout", js.FuncOf(func(this js.Object, args []js.Object) any {
-		callback()
-		//js.Global().Call("alert", "timer "+strconv.Itoa(timeoutId)+" gone off after: "+strconv.Itoa(ms))
-		if err := app.Update(); err != nil {
-			js.Global().Call("alert", "after timer "+strconv.Itoa(timeoutId)+" app dom update error: "+err.Error())
-			return err
-		}
-		return nil
-	}), ms).Int()
-	//js.Global().Call("alert", "timer "+strconv.Itoa(timeoutId)+" set to: "+strconv.Itoa(ms))
-	return timeoutId
+
+// DebugCounters are numbers of live objects of the app. Numbers growing during a long session point to a leak.
+type DebugCounters struct {
+	// Elements created by CreateElement and not destroyed.
+	Elements int
+	// Registered event listeners.
+	Listeners int
+	// Scheduled timeouts, intervals and animation frames.
+	Timers int
+	// Running animations, their frames are counted in Timers.
+	Animations int
+}
+
+// Callbacks returns number of live Go functions callable from JavaScript.
+func (dc DebugCounters) Callbacks() int {
+	return dc.Listeners + dc.Timers
+}
+
+func (app *App) DebugCounters() DebugCounters {
+	return DebugCounters{
+		Elements:   len(app.created),
+		Listeners:  len(app.listeners),
+		Timers:     len(app.timers),
+		Animations: len(app.animations),
+	}
 }
@@ -0,0 +1,60 @@
+//go:build !js || (!ecmascript && !wasm)
+
+package shf_test
+
+import (
+	"URLchess/shf"
+	"URLchess/shf/js"
+	"testing"
+)
+
+// Counts its updates.
+type counter struct {
+	shf.Element
+	updates int
+}
+
+func (c *counter) Update(*shf.Tools) error {
+	c.updates++
+	return nil
+}
+
+func TestMarkDirtyAndBeforeUpdate(t *testing.T) {
+	app := newApp(t)
+	tools := app.Tools()
+	button := &counter{Element: tools.CreateElement("button")}
+	js.Global().Get("document").Get("body").Call("appendChild", button.Object())
+	marked, always := &counter{}, &counter{}
+
+	callbacks := 0
+	tools.BeforeUpdate(func() {
+		callbacks++
+		tools.MarkDirty(always)
+	})
+
+	mark := []shf.Updater{}
+	if err := tools.Click(button, func(shf.Event) error {
+		return tools.MarkDirty(mark...)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing marked, nothing updated.
+	js.Click(button.Object())
+	if callbacks != 0 || always.updates != 0 {
+		t.Errorf("nothing marked: %d callbacks, %d updates, want none", callbacks, always.updates)
+	}
+
+	// Marked twice, updated once, callback registered components with it.
+	mark = []shf.Updater{marked, marked}
+	js.Click(button.Object())
+	if marked.updates != 1 || callbacks != 1 || always.updates != 1 {
+		t.Errorf("marked: %d updates, %d callbacks, %d callback updates, want 1 each", marked.updates, callbacks, always.updates)
+	}
+
+	// Outside handlers update is immediate and does not call the callbacks.
+	tools.MarkDirty(marked)
+	if marked.updates != 2 || callbacks != 1 {
+		t.Errorf("outside handler: %d updates, %d callbacks, want 2 and 1", marked.updates, callbacks)
+	}
+}
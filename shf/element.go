@@ -1,16 +1,69 @@
 package shf
 
-import "URLchess/shf/js"
+import (
+	"URLchess/shf/js"
+	"reflect"
+)
 
 type Element interface {
 	Event
 	Object() js.Object
 }
 
-var Window Element = &element{js.Global()}
+var elementType = reflect.TypeOf((*Element)(nil)).Elem()
+
+// Returns true, if elm is nil, or a component (struct embedding an Element) with nil embedded Element, so elm's methods can not be called.
+func isNilElement(elm Element) bool {
+	return elm == nil || isNilElementValue(reflect.ValueOf(elm))
+}
+func isNilElementValue(v reflect.Value) bool {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && (f.Type.Implements(elementType) || reflect.PtrTo(f.Type).Implements(elementType)) {
+			return isNilElementValue(v.Field(i))
+		}
+	}
+	return false
+}
+
+var Window Element = &element{object: js.Global()}
+
+// Document is the html document, target of document events (e.g. "visibilitychange").
+var Document Element = &documentElement{}
+
+// The document is looked up on every use, so it is always the current window's document.
+type documentElement struct{}
+
+func (d *documentElement) Object() js.Object {
+	return js.Global().Get("document")
+}
+func (d *documentElement) Get(key string) js.Object {
+	return d.Object().Get(key)
+}
+func (d *documentElement) Set(key string, value interface{}) {
+	d.Object().Set(key, value)
+}
+func (d *documentElement) Delete(key string) {
+	d.Object().Delete(key)
+}
+func (d *documentElement) Call(name string, args ...any) js.Object {
+	return d.Object().Call(name, args...)
+}
 
 type element struct {
 	object interface{}
+	// Key in App.created, if the element was created by App.
+	id int
 }
 
 func (e *element) Object() js.Object {
@@ -0,0 +1,135 @@
+//go:build !js || (!ecmascript && !wasm)
+
+package shf_test
+
+import (
+	"URLchess/shf"
+	"URLchess/shf/js"
+	"testing"
+	"time"
+)
+
+type testModel struct{}
+
+func (testModel) Update(*shf.Tools) error { return nil }
+
+type component struct {
+	shf.Element
+}
+
+// Element implemented outside shf.
+type foreignElement struct {
+	object js.Object
+}
+
+func (e foreignElement) Object() js.Object                 { return e.object }
+func (e foreignElement) Get(key string) js.Object          { return e.object.Get(key) }
+func (e foreignElement) Set(key string, value interface{}) { e.object.Set(key, value) }
+func (e foreignElement) Delete(key string)                 { e.object.Delete(key) }
+func (e foreignElement) Call(name string, args ...any) js.Object {
+	return e.object.Call(name, args...)
+}
+
+func newApp(t *testing.T) *shf.App {
+	t.Helper()
+	js.ResetDOM()
+	app, err := shf.Create(testModel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func TestComponentWithoutElement(t *testing.T) {
+	app := newApp(t)
+	for _, elm := range []shf.Element{nil, &component{}, &component{&component{}}} {
+		if app.ElementCreated(elm) {
+			t.Errorf("%#v is created", elm)
+		}
+		if err := app.Click(elm, func(shf.Event) error { return nil }); err == nil {
+			t.Errorf("click listener registered on %#v", elm)
+		}
+		app.DestroyElement(elm)
+		app.DestroyChildren(elm)
+	}
+}
+
+func TestForeignElement(t *testing.T) {
+	app := newApp(t)
+	elm := foreignElement{js.Global().Get("document").Call("createElement", "div")}
+	js.Global().Get("document").Get("body").Call("appendChild", elm.Object())
+	if app.ElementCreated(elm) {
+		t.Error("foreign element is created by app")
+	}
+
+	clicks := 0
+	if err := app.Click(elm, func(shf.Event) error {
+		clicks++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	js.Click(elm.Object())
+	app.RemoveListeners(elm, "")
+	js.Click(elm.Object())
+	if clicks != 1 {
+		t.Errorf("clicked %d times, want 1", clicks)
+	}
+}
+
+func TestDestroyElement(t *testing.T) {
+	app := newApp(t)
+	tools := app.Tools()
+
+	// Component and its element are the same to the app.
+	parent := &component{tools.CreateElement("div")}
+	if !app.ElementCreated(parent) || !app.ElementCreated(parent.Element) {
+		t.Fatal("created element is not created")
+	}
+	js.Global().Get("document").Get("body").Call("appendChild", parent.Object())
+
+	// Nested created elements, with a foreign element between them.
+	child := tools.CreateElement("p")
+	foreign := js.Global().Get("document").Call("createElement", "span")
+	grandchild := &component{tools.CreateElement("b")}
+	parent.Call("appendChild", child.Object())
+	child.Call("appendChild", foreign)
+	foreign.Call("appendChild", grandchild.Object())
+	// Not attached to parent, it survives.
+	other := tools.CreateElement("i")
+
+	for _, elm := range []shf.Element{parent, child, grandchild} {
+		if err := tools.Click(elm, func(shf.Event) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	app.After(time.Second, shf.TimerOptions{Owner: grandchild.Element}, func() {
+		t.Error("timer of destroyed element was called")
+	})
+	if got := app.DebugCounters(); got.Elements != 4 || got.Listeners != 3 || got.Timers != 1 {
+		t.Fatalf("counters before destroy: %+v", got)
+	}
+
+	app.DestroyChildren(parent.Element)
+	if got := app.DebugCounters(); got.Elements != 2 || got.Listeners != 1 || got.Timers != 0 {
+		t.Errorf("counters after destroying children: %+v", got)
+	}
+	if app.ElementCreated(child) || app.ElementCreated(grandchild) {
+		t.Error("destroyed children are still created")
+	}
+	if n := parent.Get("childElementCount").Int(); n != 0 {
+		t.Errorf("parent has %d children after destroying them", n)
+	}
+
+	app.DestroyElement(parent)
+	if got := app.DebugCounters(); got.Elements != 1 || got.Listeners != 0 {
+		t.Errorf("counters after destroying parent: %+v", got)
+	}
+	if !app.ElementCreated(other) {
+		t.Error("element outside of destroyed one was destroyed")
+	}
+	if !js.IsNull(js.QuerySelector("div")) {
+		t.Error("destroyed element is still in document")
+	}
+	js.AdvanceTime(2 * time.Second)
+}
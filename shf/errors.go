@@ -0,0 +1,106 @@
+package shf
+
+import (
+	"URLchess/shf/js"
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// Error is a failure of an event handler, timer or animation frame callback, or of the update after it.
+type Error struct {
+	// Name of the handled event (e.g. "click"), "timer", "interval" or "animation" for timer, interval and animation frame callbacks.
+	Event string
+	// Element the handler is bound to, or owner of the timer. Nil if there is none.
+	Target Element
+	// Error returned by the handler or the update. If the handler panicked, it describes the panic.
+	Err error
+	// Value recovered from the handler's panic, nil if it did not panic.
+	Panic interface{}
+	// Stack trace of the panic.
+	Stack string
+}
+
+func (e *Error) Error() string {
+	context := e.Event + " event handler"
+	switch e.Event {
+	case "":
+		context = "app"
+	case "timer":
+		context = "timer callback"
+	case "interval":
+		context = "interval callback"
+	case "animation":
+		context = "animation frame"
+	}
+	if e.Target != nil {
+		context += " on " + describeElement(e.Target)
+	}
+	if e.Panic != nil {
+		return context + " panicked: " + e.Err.Error()
+	}
+	return context + " returned error: " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Returns element as "tag#id.class", "window" or "document".
+func describeElement(elm Element) string {
+	switch elm {
+	case Window:
+		return "window"
+	case Document:
+		return "document"
+	}
+	o := elm.Object()
+	if js.IsUndefined(o) || js.IsNull(o) {
+		return "undefined element"
+	}
+	s := strings.ToLower(o.Get("tagName").String())
+	if id := o.Get("id").String(); id != "" {
+		s += "#" + id
+	}
+	if class := o.Get("className").String(); class != "" {
+		s += "." + strings.Join(strings.Fields(class), ".")
+	}
+	return s
+}
+
+// SetErrorHandler sets function, which receives errors of event handlers, timers, intervals and animation frames.
+// The app keeps running after an error. Without a handler, errors are shown by alert.
+func (app *App) SetErrorHandler(handler func(err *Error)) {
+	app.errorHandler = handler
+}
+
+// ReportError passes error, which happened while handling event on target, to the error handler.
+// Errors outside of handlers (e.g. app update after start) can be reported with an empty event name.
+func (app *App) ReportError(event string, target Element, err error) {
+	if err == nil {
+		return
+	}
+	e, ok := err.(*Error)
+	if !ok {
+		e = &Error{Event: event, Target: target, Err: err}
+	}
+	if app.errorHandler == nil {
+		js.Global().Call("alert", e.Error())
+		return
+	}
+	app.errorHandler(e)
+}
+
+// Calls function and returns error describing its panic, if it panicked.
+func recoverPanic(event string, target Element, function func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &Error{
+				Event:  event,
+				Target: target,
+				Err:    fmt.Errorf("%v", r),
+				Panic:  r,
+				Stack:  string(debug.Stack()),
+			}
+		}
+	}()
+	return function()
+}
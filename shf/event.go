@@ -25,3 +25,113 @@ func (hce *hashChangeEvent) NewURL() string {
 func (hce *hashChangeEvent) OldURL() string {
 	return hce.Get("oldURL").String()
 }
+
+type KeyboardEvent interface {
+	Event
+	Key() string
+	Code() string
+	Repeat() bool
+	AltKey() bool
+	CtrlKey() bool
+	MetaKey() bool
+	ShiftKey() bool
+}
+
+type keyboardEvent struct {
+	Event
+}
+
+func (ke *keyboardEvent) Key() string {
+	return ke.Get("key").String()
+}
+func (ke *keyboardEvent) Code() string {
+	return ke.Get("code").String()
+}
+func (ke *keyboardEvent) Repeat() bool {
+	return ke.Get("repeat").Bool()
+}
+func (ke *keyboardEvent) AltKey() bool {
+	return ke.Get("altKey").Bool()
+}
+func (ke *keyboardEvent) CtrlKey() bool {
+	return ke.Get("ctrlKey").Bool()
+}
+func (ke *keyboardEvent) MetaKey() bool {
+	return ke.Get("metaKey").Bool()
+}
+func (ke *keyboardEvent) ShiftKey() bool {
+	return ke.Get("shiftKey").Bool()
+}
+
+type PointerEvent interface {
+	Event
+	ClientX() float64
+	ClientY() float64
+	PointerId() int
+	PointerType() string
+	Button() int
+	IsPrimary() bool
+}
+
+type pointerEvent struct {
+	Event
+}
+
+func (pe *pointerEvent) ClientX() float64 {
+	return pe.Get("clientX").Float()
+}
+func (pe *pointerEvent) ClientY() float64 {
+	return pe.Get("clientY").Float()
+}
+func (pe *pointerEvent) PointerId() int {
+	return pe.Get("pointerId").Int()
+}
+func (pe *pointerEvent) PointerType() string {
+	return pe.Get("pointerType").String()
+}
+func (pe *pointerEvent) Button() int {
+	return pe.Get("button").Int()
+}
+func (pe *pointerEvent) IsPrimary() bool {
+	return pe.Get("isPrimary").Bool()
+}
+
+// StorageEvent is fired on window, when local storage is changed in another document.
+type StorageEvent interface {
+	Event
+	// Key of the changed item, empty if the storage was cleared.
+	Key() string
+	OldValue() string
+	NewValue() string
+	URL() string
+}
+
+type storageEvent struct {
+	Event
+}
+
+func (se *storageEvent) Key() string {
+	return nullableString(se.Get("key"))
+}
+func (se *storageEvent) OldValue() string {
+	return nullableString(se.Get("oldValue"))
+}
+func (se *storageEvent) NewValue() string {
+	return nullableString(se.Get("newValue"))
+}
+func (se *storageEvent) URL() string {
+	return se.Get("url").String()
+}
+
+func nullableString(o js.Object) string {
+	if js.IsNull(o) || js.IsUndefined(o) {
+		return ""
+	}
+	return o.String()
+}
+
+// Conversions of events received by Listen to typed events.
+func AsHashChangeEvent(e Event) HashChangeEvent { return &hashChangeEvent{e} }
+func AsKeyboardEvent(e Event) KeyboardEvent     { return &keyboardEvent{e} }
+func AsPointerEvent(e Event) PointerEvent       { return &pointerEvent{e} }
+func AsStorageEvent(e Event) StorageEvent       { return &storageEvent{e} }
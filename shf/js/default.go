@@ -2,57 +2,409 @@
 
 package js
 
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
 const WRAPS = ""
 
-type Object struct{}
+// Object is a value in the in-memory JavaScript environment, used when the code is built natively (e.g. for go test).
+// The environment has a window with a DOM document, location, history, timers and storage, see dom.go & window.go.
+// The zero Object is undefined.
+type Object struct {
+	v *value
+}
+
+type kind int
+
+const (
+	kindUndefined kind = iota
+	kindNull
+	kindBool
+	kindNumber
+	kindString
+	kindObject
+	kindFunction
+)
+
+type value struct {
+	kind   kind
+	bool   bool
+	number float64
+	str    string
+
+	// Object properties in order of creation.
+	props map[string]Object
+	keys  []string
+	// Computed properties of host objects (DOM nodes, location, ...). Return false, if the key is not handled.
+	get func(key string) (Object, bool)
+	set func(key string, v Object) bool
+
+	// Function body, or constructor, if the function can be called with new.
+	fn   func(this Object, args []Object) Object
+	ctor func(args []Object) Object
+	// Released Go function, must not be called anymore.
+	released bool
+
+	// Host object payloads.
+	node  *node
+	event *eventState
+	elems []Object
+	bytes []byte
+}
+
+var (
+	undefinedValue = &value{kind: kindUndefined}
+	nullValue      = &value{kind: kindNull}
+)
+
+func (o Object) val() *value {
+	if o.v == nil {
+		return undefinedValue
+	}
+	return o.v
+}
+
+func newObject() Object {
+	return Object{&value{kind: kindObject}}
+}
+
+func newFunction(fn func(this Object, args []Object) Object) Object {
+	return Object{&value{kind: kindFunction, fn: fn}}
+}
+
+func newArray(elems []Object) Object {
+	o := newObject()
+	o.v.elems = elems
+	o.v.get = func(key string) (Object, bool) {
+		if key == "length" {
+			return valueOf(len(o.v.elems)), true
+		}
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(o.v.elems) {
+			return o.v.elems[i], true
+		}
+		return Object{}, false
+	}
+	o.setMethod("item", func(_ Object, args []Object) Object {
+		if i := arg(args, 0).Int(); i >= 0 && i < len(o.v.elems) {
+			return o.v.elems[i]
+		}
+		return Null()
+	})
+	return o
+}
+
+// Sets plain property, bypassing computed properties.
+func (o Object) setProp(key string, v Object) {
+	if o.v.props == nil {
+		o.v.props = map[string]Object{}
+	}
+	if _, ok := o.v.props[key]; !ok {
+		o.v.keys = append(o.v.keys, key)
+	}
+	o.v.props[key] = v
+}
+
+func (o Object) setMethod(name string, fn func(this Object, args []Object) Object) {
+	o.setProp(name, newFunction(fn))
+}
+
+// Returns i-th argument, or undefined.
+func arg(args []Object, i int) Object {
+	if i < len(args) {
+		return args[i]
+	}
+	return Object{}
+}
+
+// Converts Go value to Object, like js.ValueOf in wasm.
+func valueOf(x interface{}) Object {
+	switch x := x.(type) {
+	case Object:
+		return x
+	case Func:
+		return x.Object
+	case nil:
+		return Null()
+	case bool:
+		return Object{&value{kind: kindBool, bool: x}}
+	case string:
+		return Object{&value{kind: kindString, str: x}}
+	case int:
+		return valueOf(float64(x))
+	case int8:
+		return valueOf(float64(x))
+	case int16:
+		return valueOf(float64(x))
+	case int32:
+		return valueOf(float64(x))
+	case int64:
+		return valueOf(float64(x))
+	case uint:
+		return valueOf(float64(x))
+	case uint8:
+		return valueOf(float64(x))
+	case uint16:
+		return valueOf(float64(x))
+	case uint32:
+		return valueOf(float64(x))
+	case uint64:
+		return valueOf(float64(x))
+	case float32:
+		return valueOf(float64(x))
+	case float64:
+		return Object{&value{kind: kindNumber, number: x}}
+	case []interface{}:
+		elems := make([]Object, len(x))
+		for i, e := range x {
+			elems[i] = valueOf(e)
+		}
+		return newArray(elems)
+	case map[string]interface{}:
+		o := newObject()
+		for k, e := range x {
+			o.setProp(k, valueOf(e))
+		}
+		return o
+	case interface{ Object() Object }:
+		return x.Object()
+	}
+	panic("js: ValueOf: invalid value")
+}
 
 // gopherjs: Get returns the object's property with the given key.
 // wasm: Get returns the JavaScript property key of object o. It panics if o is not a JavaScript object.
-func (o Object) Get(key string) Object { return Object{} }
+func (o Object) Get(key string) Object {
+	v := o.val()
+	switch v.kind {
+	case kindUndefined, kindNull:
+		panic("js: cannot read property " + key + " of " + o.String())
+	case kindString:
+		if key == "length" {
+			return valueOf(len(v.str))
+		}
+		return Object{}
+	}
+	if v.get != nil {
+		if res, ok := v.get(key); ok {
+			return res
+		}
+	}
+	return v.props[key]
+}
 
 // gopherjs: Set assigns the value to the object's property with the given key.
 // wasm: Set sets the JavaScript property key of value value to ValueOf(key). It panics if o is not a JavaScript object. (Note: Wasm uses generics: func (o Object) Set(key string, value any))
-func (o Object) Set(key string, value interface{}) {}
+func (o Object) Set(key string, value interface{}) {
+	v := o.val()
+	if v.kind != kindObject && v.kind != kindFunction {
+		panic("js: cannot set property " + key + " of " + o.String())
+	}
+	x := valueOf(value)
+	if v.set != nil && v.set(key, x) {
+		return
+	}
+	o.setProp(key, x)
+}
 
 // gopherjs: Delete removes the object's property with the given key.
 // wasm: Delete deletes the JavaScript property key of object o. It panics if o is not a JavaScript object.
-func (o Object) Delete(key string) {}
+func (o Object) Delete(key string) {
+	v := o.val()
+	if v.kind != kindObject && v.kind != kindFunction {
+		panic("js: cannot delete property " + key + " of " + o.String())
+	}
+	if _, ok := v.props[key]; !ok {
+		return
+	}
+	delete(v.props, key)
+	for i, k := range v.keys {
+		if k == key {
+			v.keys = append(v.keys[:i], v.keys[i+1:]...)
+			break
+		}
+	}
+}
 
 // gopherjs: Call calls the object's method with the given name.
 // wasm: Call does a JavaScript call to the method m of value v with the given arguments. It panics if v has no method m. The arguments get mapped to JavaScript values according to the ValueOf function. (Note: Wasm uses generics func (o Object) Call(m string, args ...any) Object)
-func (o Object) Call(name string, args ...interface{}) Object { return Object{} }
+func (o Object) Call(name string, args ...interface{}) Object {
+	method := o.Get(name)
+	if method.val().kind != kindFunction {
+		panic("js: " + name + " is not a function")
+	}
+	return method.invoke(o, args)
+}
+
+// gopherjs: Invoke calls the object with the given arguments.
+// wasm: Invoke does a JavaScript call of the value v with the given arguments. It panics if v is not a JavaScript function.
+func (o Object) Invoke(args ...interface{}) Object {
+	if o.val().kind != kindFunction {
+		panic("js: " + o.String() + " is not a function")
+	}
+	return o.invoke(Object{}, args)
+}
+
+func (o Object) invoke(this Object, args []interface{}) Object {
+	if o.v.released {
+		panic("js: call to released function")
+	}
+	values := make([]Object, len(args))
+	for i, a := range args {
+		values[i] = valueOf(a)
+	}
+	if o.v.fn == nil {
+		return Object{}
+	}
+	return o.v.fn(this, values)
+}
+
+// gopherjs: New creates a new instance of this type object. This will fail if it not a function (constructor).
+// wasm: New uses JavaScript's "new" operator with value v as constructor and the given arguments. It panics if v is not a JavaScript function. (Note: Wasm uses generics func (v Value) New(args ...any) Value)
+func (o Object) New(args ...interface{}) Object {
+	if o.val().ctor == nil {
+		panic("js: " + o.String() + " is not a constructor")
+	}
+	values := make([]Object, len(args))
+	for i, a := range args {
+		values[i] = valueOf(a)
+	}
+	return o.v.ctor(values)
+}
 
 // gopherjs: Bool returns the object converted to bool according to JavaScript type conversions.
 // wasm: Bool returns the object o as a bool. It panics if o is not a JavaScript boolean.
-func (o Object) Bool() bool { return false }
+func (o Object) Bool() bool {
+	v := o.val()
+	switch v.kind {
+	case kindUndefined, kindNull:
+		return false
+	case kindBool:
+		return v.bool
+	case kindNumber:
+		return v.number != 0 && !math.IsNaN(v.number)
+	case kindString:
+		return v.str != ""
+	}
+	return true
+}
 
 // gopherjs: String returns the object converted to string according to JavaScript type conversions.
 // wasm: String returns the object o as a string. String is a special case because of Go's String method convention. Unlike the other getters, it does not panic if o's Type is not TypeString. Instead, it returns a string of the form "<T>" or "<T: V>" where T is o's type and V is a string representation of o's value.
-func (o Object) String() string { return "" }
+func (o Object) String() string {
+	v := o.val()
+	switch v.kind {
+	case kindUndefined:
+		return "undefined"
+	case kindNull:
+		return "null"
+	case kindBool:
+		return strconv.FormatBool(v.bool)
+	case kindNumber:
+		switch {
+		case math.IsNaN(v.number):
+			return "NaN"
+		case math.IsInf(v.number, 1):
+			return "Infinity"
+		case math.IsInf(v.number, -1):
+			return "-Infinity"
+		}
+		return strconv.FormatFloat(v.number, 'f', -1, 64)
+	case kindString:
+		return v.str
+	case kindFunction:
+		return "function"
+	}
+	if v.node != nil {
+		return "[object " + v.node.className() + "]"
+	}
+	if v.elems != nil {
+		parts := make([]string, len(v.elems))
+		for i, e := range v.elems {
+			parts[i] = e.String()
+		}
+		return strings.Join(parts, ",")
+	}
+	return "[object Object]"
+}
 
 // gopherjs: Int returns the object converted to int according to JavaScript type conversions (parseInt).
 // wasm: Int returns the object o truncated to an int. It panics if o is not a JavaScript number.
-func (o Object) Int() int { return 0 }
+func (o Object) Int() int {
+	f := o.Float()
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	return int(f)
+}
 
 // gopherjs: Float returns the object converted to float64 according to JavaScript type conversions (parseFloat).
 // wasm: Float returns the object o as a float64. It panics if o is not a JavaScript number.
-func (o Object) Float() float64 { return 0 }
+func (o Object) Float() float64 {
+	v := o.val()
+	switch v.kind {
+	case kindNumber:
+		return v.number
+	case kindBool:
+		if v.bool {
+			return 1
+		}
+		return 0
+	case kindNull:
+		return 0
+	case kindString:
+		s := strings.TrimSpace(v.str)
+		if s == "" {
+			return 0
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return math.NaN()
+}
 
 // gopherjs: No such thing exists in gopherjs.
 // wasm: Func is a wrapped Go function to be called by JavaScript.
-type Func func(e Object)
+type Func struct {
+	Object
+}
 
 // gopherjs: No such thing exists in gopherjs.
 // wasm: Release frees up resources allocated for the function. The function must not be invoked after calling Release. It is allowed to call Release while the function is still running
-func (c Func) Release() {}
+func (c Func) Release() {
+	if c.v == nil || c.v.released {
+		return
+	}
+	c.v.released = true
+}
 
 // gopherjs: No such thing exists in gopherjs.
 // wasm: FuncOf returns a function to be used by JavaScript. ...
-var FuncOf = func(fn func(_ Object, _ []Object) any) Func {
-	return nil
+var FuncOf = func(fn func(this Object, args []Object) any) Func {
+	return Func{newFunction(func(this Object, args []Object) Object {
+		res := fn(this, args)
+		if _, ok := res.(error); ok {
+			// Errors are not JavaScript values, the returned value is ignored, as is usual for callbacks.
+			return Object{}
+		}
+		return valueOf(res)
+	})}
 }
 
-var Global = func() Object { return Object{} }
+var Global = func() Object { return window() }
 var Undefined = func() Object { return Object{} }
 
-func IsUndefined(o Object) bool { return true }
+// Null returns the JavaScript null value.
+func Null() Object { return Object{nullValue} }
+
+func IsUndefined(o Object) bool { return o.val().kind == kindUndefined }
+
+func IsNull(o Object) bool { return o.val().kind == kindNull }
+
+// Uint8Array returns a new JavaScript Uint8Array with a copy of bytes b.
+func Uint8Array(b []byte) Object {
+	return newUint8Array(append([]byte{}, b...))
+}
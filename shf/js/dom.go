@@ -0,0 +1,1590 @@
+//go:build !js || (!ecmascript && !wasm)
+
+package js
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// DOM node types, as in Node.nodeType. The window is not a node, but it is an event target and uses node to hold listeners.
+const (
+	windowNode   = 0
+	elementNode  = 1
+	textNode     = 3
+	documentNode = 9
+)
+
+type attribute struct {
+	name, value string
+}
+
+type listener struct {
+	fn                     Object
+	capture, once, passive bool
+	removed                bool
+}
+
+type node struct {
+	obj      Object
+	nodeType int
+	// Lower case tag name of element.
+	tag string
+	// Content of text node.
+	data      string
+	parent    *node
+	children  []*node
+	attrs     []attribute
+	listeners map[string][]*listener
+
+	style, classList Object
+
+	// Form control state, which is not reflected to attributes.
+	value            *string
+	checked          *bool
+	selected         *bool
+	selStart, selEnd int
+}
+
+// Elements, which can not have children.
+var voidElements = map[string]bool{"area": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true}
+
+func newNode(nodeType int, tag string) *node {
+	n := &node{nodeType: nodeType, tag: strings.ToLower(tag)}
+	n.obj = Object{&value{kind: kindObject, node: n}}
+	n.obj.v.get = n.get
+	n.obj.v.set = n.set
+	return n
+}
+
+func (n *node) className() string {
+	switch n.nodeType {
+	case windowNode:
+		return "Window"
+	case textNode:
+		return "Text"
+	case documentNode:
+		return "HTMLDocument"
+	}
+	return "HTMLElement"
+}
+
+func (n *node) attr(name string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.name == name {
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+func (n *node) setAttr(name, value string) {
+	name = strings.ToLower(name)
+	for i := range n.attrs {
+		if n.attrs[i].name == name {
+			n.attrs[i].value = value
+			return
+		}
+	}
+	n.attrs = append(n.attrs, attribute{name, value})
+}
+
+func (n *node) removeAttr(name string) {
+	name = strings.ToLower(name)
+	for i := range n.attrs {
+		if n.attrs[i].name == name {
+			n.attrs = append(n.attrs[:i], n.attrs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (n *node) classes() []string {
+	class, _ := n.attr("class")
+	return strings.Fields(class)
+}
+
+func (n *node) hasClass(class string) bool {
+	for _, c := range n.classes() {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true, if n is other or its ancestor.
+func (n *node) contains(other *node) bool {
+	for ; other != nil; other = other.parent {
+		if other == n {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *node) connected() bool {
+	root := n
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root.nodeType == documentNode
+}
+
+func (n *node) detach() {
+	p := n.parent
+	if p == nil {
+		return
+	}
+	for i, c := range p.children {
+		if c == n {
+			p.children = append(p.children[:i], p.children[i+1:]...)
+			break
+		}
+	}
+	n.parent = nil
+	if env != nil && env.focused != nil && n.contains(env.focused) {
+		env.focused = nil
+	}
+}
+
+// Inserts child before ref, or appends it if ref is nil. Child is removed from its old parent first.
+func (n *node) insert(child, ref *node) {
+	if child.contains(n) {
+		panic("js: HierarchyRequestError: the new child contains the parent")
+	}
+	child.detach()
+	child.parent = n
+	for i, c := range n.children {
+		if c == ref {
+			n.children = append(n.children[:i], append([]*node{child}, n.children[i:]...)...)
+			return
+		}
+	}
+	n.children = append(n.children, child)
+}
+
+func (n *node) removeChildren() {
+	for len(n.children) > 0 {
+		n.children[0].detach()
+	}
+}
+
+// Returns element descendants of n in document order.
+func (n *node) descendants() []*node {
+	res := []*node{}
+	for _, c := range n.children {
+		if c.nodeType == elementNode {
+			res = append(res, c)
+			res = append(res, c.descendants()...)
+		}
+	}
+	return res
+}
+
+func (n *node) textContent() string {
+	if n.nodeType == textNode {
+		return n.data
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		b.WriteString(c.textContent())
+	}
+	return b.String()
+}
+
+func (n *node) setTextContent(text string) {
+	n.removeChildren()
+	if text != "" {
+		t := newNode(textNode, "")
+		t.data = text
+		n.insert(t, nil)
+	}
+}
+
+// Writes node as HTML.
+func (n *node) writeHTML(b *strings.Builder) {
+	switch n.nodeType {
+	case textNode:
+		if n.parent != nil && (n.parent.tag == "style" || n.parent.tag == "script") {
+			b.WriteString(n.data)
+			return
+		}
+		b.WriteString(html.EscapeString(n.data))
+		return
+	case elementNode:
+		b.WriteString("<" + n.tag)
+		for _, a := range n.attrs {
+			b.WriteString(" " + a.name + "=\"" + html.EscapeString(a.value) + "\"")
+		}
+		b.WriteString(">")
+		if voidElements[n.tag] {
+			return
+		}
+	}
+	for _, c := range n.children {
+		c.writeHTML(b)
+	}
+	if n.nodeType == elementNode {
+		b.WriteString("</" + n.tag + ">")
+	}
+}
+
+func (n *node) innerHTML() string {
+	var b strings.Builder
+	for _, c := range n.children {
+		c.writeHTML(&b)
+	}
+	return b.String()
+}
+
+func (n *node) outerHTML() string {
+	var b strings.Builder
+	n.writeHTML(&b)
+	return b.String()
+}
+
+// Replaces children of n with nodes parsed from HTML fragment.
+func (n *node) setInnerHTML(fragment string) {
+	n.removeChildren()
+	for _, c := range parseHTML(fragment) {
+		n.insert(c, nil)
+	}
+}
+
+// Parses simple HTML fragment: elements with quoted or unquoted attributes, text and character references.
+// Comments, doctype and implied end tags are not supported, unclosed elements are closed at the end of the fragment.
+func parseHTML(fragment string) []*node {
+	root := newNode(elementNode, "template")
+	current := root
+	text := func(s string) {
+		if s == "" {
+			return
+		}
+		t := newNode(textNode, "")
+		t.data = html.UnescapeString(s)
+		current.insert(t, nil)
+	}
+
+	for fragment != "" {
+		lt := strings.IndexByte(fragment, '<')
+		if lt < 0 || lt+1 >= len(fragment) {
+			text(fragment)
+			break
+		}
+		text(fragment[:lt])
+		fragment = fragment[lt:]
+		gt := strings.IndexByte(fragment, '>')
+		if gt < 0 {
+			text(fragment)
+			break
+		}
+		tag := fragment[1:gt]
+		fragment = fragment[gt+1:]
+
+		if strings.HasPrefix(tag, "/") {
+			name := strings.ToLower(strings.TrimSpace(tag[1:]))
+			for e := current; e != root; e = e.parent {
+				if e.tag == name {
+					current = e.parent
+					break
+				}
+			}
+			continue
+		}
+
+		selfClosing := strings.HasSuffix(tag, "/")
+		tag = strings.TrimSuffix(tag, "/")
+		name, rest := tag, ""
+		if i := strings.IndexAny(tag, " \t\n"); i >= 0 {
+			name, rest = tag[:i], tag[i+1:]
+		}
+		e := newNode(elementNode, name)
+		for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+			end := strings.IndexAny(rest, "= \t\n")
+			if end < 0 {
+				e.setAttr(rest, "")
+				break
+			}
+			attrName := rest[:end]
+			rest = strings.TrimLeft(rest[end:], " \t\n")
+			if !strings.HasPrefix(rest, "=") {
+				e.setAttr(attrName, "")
+				continue
+			}
+			rest = strings.TrimLeft(rest[1:], " \t\n")
+			attrValue := ""
+			if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+				if q := strings.IndexByte(rest[1:], rest[0]); q >= 0 {
+					attrValue, rest = rest[1:q+1], rest[q+2:]
+				} else {
+					attrValue, rest = rest[1:], ""
+				}
+			} else if sp := strings.IndexAny(rest, " \t\n"); sp >= 0 {
+				attrValue, rest = rest[:sp], rest[sp:]
+			} else {
+				attrValue, rest = rest, ""
+			}
+			e.setAttr(attrName, html.UnescapeString(attrValue))
+		}
+		current.insert(e, nil)
+		if !selfClosing && !voidElements[e.tag] {
+			current = e
+		}
+	}
+
+	res := append([]*node{}, root.children...)
+	for _, c := range res {
+		c.detach()
+	}
+	return res
+}
+
+// Properties reflecting string attributes.
+var reflectedAttributes = map[string]string{
+	"id":           "id",
+	"className":    "class",
+	"htmlFor":      "for",
+	"title":        "title",
+	"href":         "href",
+	"src":          "src",
+	"alt":          "alt",
+	"placeholder":  "placeholder",
+	"download":     "download",
+	"min":          "min",
+	"max":          "max",
+	"step":         "step",
+	"name":         "name",
+	"lang":         "lang",
+	"dir":          "dir",
+	"role":         "role",
+	"rel":          "rel",
+	"target":       "target",
+	"list":         "list",
+	"autocomplete": "autocomplete",
+}
+
+// Properties reflecting boolean attributes.
+var reflectedBoolAttributes = map[string]string{
+	"disabled": "disabled",
+	"readOnly": "readonly",
+	"hidden":   "hidden",
+	"required": "required",
+	"multiple": "multiple",
+	"open":     "open",
+}
+
+// Properties of integer attributes with default value.
+var reflectedIntAttributes = map[string]struct {
+	attr string
+	def  int
+}{
+	"rows":     {"rows", 2},
+	"cols":     {"cols", 20},
+	"tabIndex": {"tabindex", -1},
+	"width":    {"width", 0},
+	"height":   {"height", 0},
+}
+
+// Layout properties. There is no layout, so they are always zero.
+var layoutProperties = map[string]bool{
+	"offsetLeft": true, "offsetTop": true, "offsetWidth": true, "offsetHeight": true,
+	"clientLeft": true, "clientTop": true, "clientWidth": true, "clientHeight": true,
+	"scrollLeft": true, "scrollTop": true, "scrollWidth": true, "scrollHeight": true,
+}
+
+func (n *node) get(key string) (Object, bool) {
+	switch n.nodeType {
+	case windowNode:
+		if m, ok := eventTargetMethods[key]; ok {
+			return m, true
+		}
+		return Object{}, false
+	case documentNode:
+		if m, ok := documentMethods[key]; ok {
+			return m, true
+		}
+	}
+	if m, ok := nodeMethods[key]; ok {
+		return m, true
+	}
+	if n.nodeType == documentNode {
+		return documentGet(key)
+	}
+
+	switch key {
+	case "nodeType":
+		return valueOf(n.nodeType), true
+	case "parentNode":
+		return nodeObject(n.parent), true
+	case "parentElement":
+		if n.parent == nil || n.parent.nodeType != elementNode {
+			return Null(), true
+		}
+		return n.parent.obj, true
+	case "isConnected":
+		return valueOf(n.connected()), true
+	case "ownerDocument":
+		return env.document.obj, true
+	case "textContent":
+		return valueOf(n.textContent()), true
+	case "childNodes":
+		return nodeList(n.children), true
+	case "firstChild":
+		if len(n.children) == 0 {
+			return Null(), true
+		}
+		return n.children[0].obj, true
+	case "lastChild":
+		if len(n.children) == 0 {
+			return Null(), true
+		}
+		return n.children[len(n.children)-1].obj, true
+	case "nextSibling", "previousSibling":
+		if n.parent == nil {
+			return Null(), true
+		}
+		for i, c := range n.parent.children {
+			if c != n {
+				continue
+			}
+			if key == "nextSibling" && i+1 < len(n.parent.children) {
+				return n.parent.children[i+1].obj, true
+			}
+			if key == "previousSibling" && i > 0 {
+				return n.parent.children[i-1].obj, true
+			}
+		}
+		return Null(), true
+	}
+
+	if n.nodeType == textNode {
+		switch key {
+		case "data", "nodeValue", "wholeText":
+			return valueOf(n.data), true
+		case "nodeName":
+			return valueOf("#text"), true
+		}
+		return Object{}, false
+	}
+
+	if attr, ok := reflectedAttributes[key]; ok {
+		v, _ := n.attr(attr)
+		return valueOf(v), true
+	}
+	if attr, ok := reflectedBoolAttributes[key]; ok {
+		_, has := n.attr(attr)
+		return valueOf(has), true
+	}
+	if a, ok := reflectedIntAttributes[key]; ok {
+		if v, has := n.attr(a.attr); has {
+			if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				return valueOf(i), true
+			}
+		}
+		def := a.def
+		if key == "tabIndex" && n.focusable() {
+			def = 0
+		}
+		return valueOf(def), true
+	}
+	if layoutProperties[key] {
+		return valueOf(0), true
+	}
+
+	switch key {
+	case "tagName", "nodeName":
+		return valueOf(strings.ToUpper(n.tag)), true
+	case "innerHTML":
+		return valueOf(n.innerHTML()), true
+	case "outerHTML":
+		return valueOf(n.outerHTML()), true
+	case "innerText", "text":
+		return valueOf(n.textContent()), true
+	case "children":
+		elems := []*node{}
+		for _, c := range n.children {
+			if c.nodeType == elementNode {
+				elems = append(elems, c)
+			}
+		}
+		return nodeList(elems), true
+	case "childElementCount":
+		count := 0
+		for _, c := range n.children {
+			if c.nodeType == elementNode {
+				count++
+			}
+		}
+		return valueOf(count), true
+	case "style":
+		if IsUndefined(n.style) {
+			n.style = newStyle()
+		}
+		return n.style, true
+	case "classList":
+		if IsUndefined(n.classList) {
+			n.classList = newClassList(n)
+		}
+		return n.classList, true
+	case "dataset":
+		return newDataset(n), true
+	case "type":
+		if t, ok := n.attr("type"); ok {
+			return valueOf(strings.ToLower(t)), true
+		}
+		switch n.tag {
+		case "input":
+			return valueOf("text"), true
+		case "button":
+			return valueOf("submit"), true
+		case "select":
+			if _, multiple := n.attr("multiple"); multiple {
+				return valueOf("select-multiple"), true
+			}
+			return valueOf("select-one"), true
+		case "textarea":
+			return valueOf("textarea"), true
+		}
+		return valueOf(""), true
+	case "value":
+		return valueOf(n.formValue()), true
+	case "defaultValue":
+		v, _ := n.attr("value")
+		if n.tag == "textarea" {
+			v = n.textContent()
+		}
+		return valueOf(v), true
+	case "checked":
+		return valueOf(n.isChecked()), true
+	case "defaultChecked":
+		_, has := n.attr("checked")
+		return valueOf(has), true
+	case "selected":
+		return valueOf(n.isSelected()), true
+	case "label":
+		if l, ok := n.attr("label"); ok {
+			return valueOf(l), true
+		}
+		return valueOf(n.textContent()), true
+	case "options":
+		return nodeList(n.options()), true
+	case "selectedIndex":
+		return valueOf(n.selectedIndex()), true
+	case "selectionStart":
+		return valueOf(n.selStart), true
+	case "selectionEnd":
+		return valueOf(n.selEnd), true
+	}
+	return Object{}, false
+}
+
+func (n *node) set(key string, v Object) bool {
+	if n.nodeType == windowNode || n.nodeType == documentNode {
+		return false
+	}
+	if key == "textContent" || (n.nodeType == textNode && (key == "data" || key == "nodeValue")) {
+		if n.nodeType == textNode {
+			n.data = v.String()
+			return true
+		}
+		n.setTextContent(v.String())
+		return true
+	}
+	if n.nodeType == textNode {
+		return false
+	}
+
+	if attr, ok := reflectedAttributes[key]; ok {
+		n.setAttr(attr, v.String())
+		return true
+	}
+	if attr, ok := reflectedBoolAttributes[key]; ok {
+		if v.Bool() {
+			n.setAttr(attr, "")
+		} else {
+			n.removeAttr(attr)
+		}
+		return true
+	}
+	if a, ok := reflectedIntAttributes[key]; ok {
+		n.setAttr(a.attr, strconv.Itoa(v.Int()))
+		return true
+	}
+
+	switch key {
+	case "innerHTML":
+		n.setInnerHTML(v.String())
+		return true
+	case "innerText", "text":
+		n.setTextContent(v.String())
+		return true
+	case "type", "label":
+		n.setAttr(key, v.String())
+		return true
+	case "value":
+		n.setFormValue(v.String())
+		return true
+	case "defaultValue":
+		n.setAttr("value", v.String())
+		return true
+	case "checked":
+		checked := v.Bool()
+		n.setChecked(checked)
+		return true
+	case "defaultChecked":
+		if v.Bool() {
+			n.setAttr("checked", "")
+		} else {
+			n.removeAttr("checked")
+		}
+		return true
+	case "selected":
+		n.setSelected(v.Bool())
+		return true
+	case "selectedIndex":
+		opts := n.options()
+		for i, o := range opts {
+			selected := i == v.Int()
+			o.selected = &selected
+		}
+		return true
+	case "style":
+		n.style = newStyle()
+		return true
+	case "classList", "children", "childNodes", "parentNode", "tagName", "nodeType", "outerHTML":
+		// Read only.
+		return true
+	}
+	if layoutProperties[key] {
+		return true
+	}
+	return false
+}
+
+func (n *node) focusable() bool {
+	switch n.tag {
+	case "a":
+		_, has := n.attr("href")
+		return has
+	case "button", "input", "select", "textarea":
+		_, disabled := n.attr("disabled")
+		return !disabled
+	}
+	_, has := n.attr("tabindex")
+	return has
+}
+
+func (n *node) options() []*node {
+	opts := []*node{}
+	for _, d := range n.descendants() {
+		if d.tag == "option" {
+			opts = append(opts, d)
+		}
+	}
+	return opts
+}
+
+func (n *node) isSelected() bool {
+	if n.selected != nil {
+		return *n.selected
+	}
+	_, has := n.attr("selected")
+	return has
+}
+
+func (n *node) setSelected(selected bool) {
+	if selected {
+		// Selecting an option deselects other options in single select.
+		for p := n.parent; p != nil; p = p.parent {
+			if p.tag != "select" {
+				continue
+			}
+			if _, multiple := p.attr("multiple"); !multiple {
+				for _, o := range p.options() {
+					unselected := false
+					o.selected = &unselected
+				}
+			}
+			break
+		}
+	}
+	n.selected = &selected
+}
+
+// Index of selected option in select element. If no option is selected, the first one is.
+func (n *node) selectedIndex() int {
+	opts := n.options()
+	for i, o := range opts {
+		if o.isSelected() {
+			return i
+		}
+	}
+	if len(opts) == 0 {
+		return -1
+	}
+	if _, multiple := n.attr("multiple"); multiple {
+		return -1
+	}
+	return 0
+}
+
+func (n *node) isChecked() bool {
+	if n.checked != nil {
+		return *n.checked
+	}
+	_, has := n.attr("checked")
+	return has
+}
+
+func (n *node) setChecked(checked bool) {
+	n.checked = &checked
+	if !checked || n.tag != "input" {
+		return
+	}
+	// Only one radio button in group can be checked.
+	if t, _ := n.attr("type"); strings.ToLower(t) == "radio" {
+		name, _ := n.attr("name")
+		root := n
+		for root.parent != nil {
+			root = root.parent
+		}
+		for _, d := range root.descendants() {
+			if d == n || d.tag != "input" {
+				continue
+			}
+			if dt, _ := d.attr("type"); strings.ToLower(dt) != "radio" {
+				continue
+			}
+			if dn, _ := d.attr("name"); dn == name && name != "" {
+				unchecked := false
+				d.checked = &unchecked
+			}
+		}
+	}
+}
+
+func (n *node) formValue() string {
+	switch n.tag {
+	case "input":
+		if n.value != nil {
+			return *n.value
+		}
+		v, has := n.attr("value")
+		if t, _ := n.attr("type"); !has && (strings.ToLower(t) == "checkbox" || strings.ToLower(t) == "radio") {
+			return "on"
+		}
+		return v
+	case "textarea":
+		if n.value != nil {
+			return *n.value
+		}
+		return n.textContent()
+	case "select":
+		opts := n.options()
+		if i := n.selectedIndex(); i >= 0 {
+			return opts[i].formValue()
+		}
+		return ""
+	case "option":
+		if v, has := n.attr("value"); has {
+			return v
+		}
+		return strings.Join(strings.Fields(n.textContent()), " ")
+	}
+	v, _ := n.attr("value")
+	return v
+}
+
+func (n *node) setFormValue(v string) {
+	switch n.tag {
+	case "input", "textarea":
+		n.value = &v
+		n.selStart, n.selEnd = len(v), len(v)
+	case "select":
+		found := false
+		for _, o := range n.options() {
+			selected := !found && o.formValue() == v
+			found = found || selected
+			o.selected = &selected
+		}
+	default:
+		n.setAttr("value", v)
+	}
+}
+
+// Returns Object of node, or null if node is nil.
+func nodeObject(n *node) Object {
+	if n == nil {
+		return Null()
+	}
+	return n.obj
+}
+
+func nodeList(nodes []*node) Object {
+	elems := make([]Object, len(nodes))
+	for i, c := range nodes {
+		elems[i] = c.obj
+	}
+	return newArray(elems)
+}
+
+// Returns node of object, or nil if object is not a node.
+func objectNode(o Object) *node {
+	if o.v == nil {
+		return nil
+	}
+	return o.v.node
+}
+
+// CSS properties read as empty string, when not set. Other unset properties are undefined, like unsupported properties in browsers.
+var styleProperties = map[string]bool{
+	"display": true, "position": true, "left": true, "top": true, "right": true, "bottom": true,
+	"width": true, "height": true, "margin": true, "padding": true, "opacity": true, "zIndex": true,
+	"transform": true, "transition": true, "transformOrigin": true, "visibility": true, "cursor": true,
+	"color": true, "background": true, "backgroundColor": true, "backgroundImage": true,
+	"fontSize": true, "fontFamily": true, "cssText": true,
+}
+
+func newStyle() Object {
+	o := newObject()
+	o.v.get = func(key string) (Object, bool) {
+		if _, set := o.v.props[key]; !set && styleProperties[key] {
+			return valueOf(""), true
+		}
+		return Object{}, false
+	}
+	o.setMethod("setProperty", func(_ Object, args []Object) Object {
+		o.setProp(arg(args, 0).String(), valueOf(arg(args, 1).String()))
+		return Object{}
+	})
+	o.setMethod("removeProperty", func(_ Object, args []Object) Object {
+		o.Delete(arg(args, 0).String())
+		return Object{}
+	})
+	o.setMethod("getPropertyValue", func(_ Object, args []Object) Object {
+		if v, ok := o.v.props[arg(args, 0).String()]; ok {
+			return valueOf(v.String())
+		}
+		return valueOf("")
+	})
+	return o
+}
+
+func newClassList(n *node) Object {
+	o := newObject()
+	setClasses := func(classes []string) {
+		n.setAttr("class", strings.Join(classes, " "))
+	}
+	o.v.get = func(key string) (Object, bool) {
+		switch key {
+		case "length":
+			return valueOf(len(n.classes())), true
+		case "value":
+			class, _ := n.attr("class")
+			return valueOf(class), true
+		}
+		if i, err := strconv.Atoi(key); err == nil {
+			if classes := n.classes(); i >= 0 && i < len(classes) {
+				return valueOf(classes[i]), true
+			}
+		}
+		return Object{}, false
+	}
+	add := func(class string) {
+		if !n.hasClass(class) {
+			setClasses(append(n.classes(), class))
+		}
+	}
+	remove := func(class string) {
+		classes := []string{}
+		for _, c := range n.classes() {
+			if c != class {
+				classes = append(classes, c)
+			}
+		}
+		setClasses(classes)
+	}
+	o.setMethod("add", func(_ Object, args []Object) Object {
+		for _, a := range args {
+			add(a.String())
+		}
+		return Object{}
+	})
+	o.setMethod("remove", func(_ Object, args []Object) Object {
+		for _, a := range args {
+			remove(a.String())
+		}
+		return Object{}
+	})
+	o.setMethod("contains", func(_ Object, args []Object) Object {
+		return valueOf(n.hasClass(arg(args, 0).String()))
+	})
+	o.setMethod("toggle", func(_ Object, args []Object) Object {
+		class := arg(args, 0).String()
+		on := !n.hasClass(class)
+		if len(args) > 1 {
+			on = args[1].Bool()
+		}
+		if on {
+			add(class)
+		} else {
+			remove(class)
+		}
+		return valueOf(on)
+	})
+	o.setMethod("replace", func(_ Object, args []Object) Object {
+		old, new := arg(args, 0).String(), arg(args, 1).String()
+		if !n.hasClass(old) {
+			return valueOf(false)
+		}
+		classes := n.classes()
+		for i, c := range classes {
+			if c == old {
+				classes[i] = new
+			}
+		}
+		setClasses(classes)
+		return valueOf(true)
+	})
+	o.setMethod("item", func(_ Object, args []Object) Object {
+		if classes, i := n.classes(), arg(args, 0).Int(); i >= 0 && i < len(classes) {
+			return valueOf(classes[i])
+		}
+		return Null()
+	})
+	return o
+}
+
+// Returns dataset of element, it reflects data-* attributes.
+func newDataset(n *node) Object {
+	o := newObject()
+	attrName := func(key string) string {
+		var b strings.Builder
+		b.WriteString("data-")
+		for _, r := range key {
+			if r >= 'A' && r <= 'Z' {
+				b.WriteString("-" + string(r+'a'-'A'))
+				continue
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+	o.v.get = func(key string) (Object, bool) {
+		if v, ok := n.attr(attrName(key)); ok {
+			return valueOf(v), true
+		}
+		return Object{}, true
+	}
+	o.v.set = func(key string, v Object) bool {
+		n.setAttr(attrName(key), v.String())
+		return true
+	}
+	return o
+}
+
+// Methods of nodes. This is the node the method is called on.
+// Window is not a node, it has only the event target methods.
+var nodeMethods, eventTargetMethods map[string]Object
+
+func init() {
+	method := func(fn func(n *node, args []Object) Object) Object {
+		return newFunction(func(this Object, args []Object) Object {
+			n := objectNode(this)
+			if n == nil {
+				panic("js: TypeError: illegal invocation")
+			}
+			return fn(n, args)
+		})
+	}
+	argNode := func(args []Object, i int) *node {
+		n := objectNode(arg(args, i))
+		if n == nil || n.nodeType == windowNode {
+			panic("js: TypeError: parameter " + strconv.Itoa(i+1) + " is not of type 'Node'")
+		}
+		return n
+	}
+
+	nodeMethods = map[string]Object{
+		"addEventListener": method(func(n *node, args []Object) Object {
+			n.addEventListener(arg(args, 0).String(), arg(args, 1), arg(args, 2))
+			return Object{}
+		}),
+		"removeEventListener": method(func(n *node, args []Object) Object {
+			n.removeEventListener(arg(args, 0).String(), arg(args, 1), arg(args, 2))
+			return Object{}
+		}),
+		"dispatchEvent": method(func(n *node, args []Object) Object {
+			return valueOf(n.dispatch(arg(args, 0)))
+		}),
+		"appendChild": method(func(n *node, args []Object) Object {
+			child := argNode(args, 0)
+			n.insert(child, nil)
+			return child.obj
+		}),
+		"insertBefore": method(func(n *node, args []Object) Object {
+			child := argNode(args, 0)
+			ref := objectNode(arg(args, 1))
+			if ref != nil && ref.parent != n {
+				panic("js: NotFoundError: the node before which the new node is to be inserted is not a child of this node")
+			}
+			n.insert(child, ref)
+			return child.obj
+		}),
+		"removeChild": method(func(n *node, args []Object) Object {
+			child := argNode(args, 0)
+			if child.parent != n {
+				panic("js: NotFoundError: the node to be removed is not a child of this node")
+			}
+			child.detach()
+			return child.obj
+		}),
+		"replaceChild": method(func(n *node, args []Object) Object {
+			child, old := argNode(args, 0), argNode(args, 1)
+			if old.parent != n {
+				panic("js: NotFoundError: the node to be replaced is not a child of this node")
+			}
+			n.insert(child, old)
+			old.detach()
+			return old.obj
+		}),
+		"remove": method(func(n *node, args []Object) Object {
+			n.detach()
+			return Object{}
+		}),
+		"contains": method(func(n *node, args []Object) Object {
+			return valueOf(n.contains(objectNode(arg(args, 0))))
+		}),
+		"hasChildNodes": method(func(n *node, args []Object) Object {
+			return valueOf(len(n.children) > 0)
+		}),
+		"getAttribute": method(func(n *node, args []Object) Object {
+			if v, ok := n.attr(strings.ToLower(arg(args, 0).String())); ok {
+				return valueOf(v)
+			}
+			return Null()
+		}),
+		"setAttribute": method(func(n *node, args []Object) Object {
+			n.setAttr(arg(args, 0).String(), arg(args, 1).String())
+			return Object{}
+		}),
+		"removeAttribute": method(func(n *node, args []Object) Object {
+			n.removeAttr(arg(args, 0).String())
+			return Object{}
+		}),
+		"hasAttribute": method(func(n *node, args []Object) Object {
+			_, ok := n.attr(strings.ToLower(arg(args, 0).String()))
+			return valueOf(ok)
+		}),
+		"toggleAttribute": method(func(n *node, args []Object) Object {
+			name := strings.ToLower(arg(args, 0).String())
+			_, on := n.attr(name)
+			on = !on
+			if len(args) > 1 {
+				on = args[1].Bool()
+			}
+			if on {
+				if _, has := n.attr(name); !has {
+					n.setAttr(name, "")
+				}
+			} else {
+				n.removeAttr(name)
+			}
+			return valueOf(on)
+		}),
+		"querySelector": method(func(n *node, args []Object) Object {
+			if found := n.querySelectorAll(arg(args, 0).String()); len(found) > 0 {
+				return found[0].obj
+			}
+			return Null()
+		}),
+		"querySelectorAll": method(func(n *node, args []Object) Object {
+			return nodeList(n.querySelectorAll(arg(args, 0).String()))
+		}),
+		"getElementsByTagName": method(func(n *node, args []Object) Object {
+			return nodeList(n.querySelectorAll(arg(args, 0).String()))
+		}),
+		"getElementsByClassName": method(func(n *node, args []Object) Object {
+			return nodeList(n.querySelectorAll("." + strings.Join(strings.Fields(arg(args, 0).String()), ".")))
+		}),
+		"matches": method(func(n *node, args []Object) Object {
+			return valueOf(matchesSelector(n, arg(args, 0).String()))
+		}),
+		"closest": method(func(n *node, args []Object) Object {
+			for e := n; e != nil && e.nodeType == elementNode; e = e.parent {
+				if matchesSelector(e, arg(args, 0).String()) {
+					return e.obj
+				}
+			}
+			return Null()
+		}),
+		"cloneNode": method(func(n *node, args []Object) Object {
+			return n.clone(arg(args, 0).Bool()).obj
+		}),
+		"click": method(func(n *node, args []Object) Object {
+			if _, disabled := n.attr("disabled"); disabled {
+				return Object{}
+			}
+			n.dispatch(newEvent("click", map[string]interface{}{"bubbles": true, "cancelable": true, "button": 0, "detail": 1}))
+			return Object{}
+		}),
+		"focus": method(func(n *node, args []Object) Object {
+			env.focus(n)
+			return Object{}
+		}),
+		"blur": method(func(n *node, args []Object) Object {
+			if env.focused == n {
+				env.focus(nil)
+			}
+			return Object{}
+		}),
+		"select": method(func(n *node, args []Object) Object {
+			n.selStart, n.selEnd = 0, len(n.formValue())
+			return Object{}
+		}),
+		"setSelectionRange": method(func(n *node, args []Object) Object {
+			n.selStart, n.selEnd = arg(args, 0).Int(), arg(args, 1).Int()
+			return Object{}
+		}),
+		"getBoundingClientRect": method(func(n *node, args []Object) Object {
+			return valueOf(map[string]interface{}{"x": 0, "y": 0, "left": 0, "top": 0, "right": 0, "bottom": 0, "width": 0, "height": 0})
+		}),
+		"scrollIntoView":        method(func(n *node, args []Object) Object { return Object{} }),
+		"setPointerCapture":     method(func(n *node, args []Object) Object { return Object{} }),
+		"releasePointerCapture": method(func(n *node, args []Object) Object { return Object{} }),
+		"hasPointerCapture":     method(func(n *node, args []Object) Object { return valueOf(false) }),
+	}
+
+	eventTargetMethods = map[string]Object{}
+	for _, name := range []string{"addEventListener", "removeEventListener", "dispatchEvent"} {
+		eventTargetMethods[name] = nodeMethods[name]
+	}
+}
+
+func (n *node) clone(deep bool) *node {
+	c := newNode(n.nodeType, n.tag)
+	c.data = n.data
+	c.attrs = append([]attribute{}, n.attrs...)
+	if deep {
+		for _, child := range n.children {
+			c.insert(child.clone(true), nil)
+		}
+	}
+	return c
+}
+
+// Event listeners.
+
+// Returns capture, once and passive listener options. Options are either a boolean (capture) or an object.
+func listenerOptions(options Object) (capture, once, passive bool) {
+	switch options.val().kind {
+	case kindObject:
+		return options.Get("capture").Bool(), options.Get("once").Bool(), options.Get("passive").Bool()
+	}
+	return options.Bool(), false, false
+}
+
+func (n *node) addEventListener(eventType string, fn, options Object) {
+	if fn.val().kind != kindFunction {
+		return
+	}
+	capture, once, passive := listenerOptions(options)
+	for _, l := range n.listeners[eventType] {
+		if l.fn.v == fn.v && l.capture == capture {
+			return
+		}
+	}
+	if n.listeners == nil {
+		n.listeners = map[string][]*listener{}
+	}
+	n.listeners[eventType] = append(n.listeners[eventType], &listener{fn: fn, capture: capture, once: once, passive: passive})
+}
+
+func (n *node) removeEventListener(eventType string, fn, options Object) {
+	capture, _, _ := listenerOptions(options)
+	listeners := n.listeners[eventType]
+	for i, l := range listeners {
+		if l.fn.v == fn.v && l.capture == capture {
+			l.removed = true
+			n.listeners[eventType] = append(listeners[:i:i], listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// Returns number of event listeners registered on node and its descendants.
+func (n *node) listenerCount() int {
+	count := 0
+	for _, ls := range n.listeners {
+		count += len(ls)
+	}
+	for _, c := range n.children {
+		count += c.listenerCount()
+	}
+	return count
+}
+
+// State of dispatched event.
+type eventState struct {
+	stopped, stoppedImmediately, canceled, passive bool
+	target, currentTarget                          *node
+	phase                                          int
+}
+
+// Returns new event object of type with properties.
+// Properties bubbles and cancelable are false, if not given, like in new Event(type).
+func newEvent(eventType string, props map[string]interface{}) Object {
+	e := newObject()
+	state := &eventState{}
+	e.v.get = func(key string) (Object, bool) {
+		switch key {
+		case "defaultPrevented":
+			return valueOf(state.canceled), true
+		case "target", "srcElement":
+			if state.target == nil {
+				return Null(), true
+			}
+			return state.target.obj, true
+		case "currentTarget":
+			if state.currentTarget == nil {
+				return Null(), true
+			}
+			return state.currentTarget.obj, true
+		case "eventPhase":
+			return valueOf(state.phase), true
+		case "cancelBubble":
+			return valueOf(state.stopped), true
+		}
+		return Object{}, false
+	}
+	e.setProp("type", valueOf(eventType))
+	e.setProp("bubbles", valueOf(false))
+	e.setProp("cancelable", valueOf(false))
+	e.setProp("isTrusted", valueOf(false))
+	e.setProp("timeStamp", valueOf(float64(env.now)/1e6))
+	for k, v := range props {
+		e.setProp(k, valueOf(v))
+	}
+	e.setMethod("preventDefault", func(_ Object, _ []Object) Object {
+		if e.Get("cancelable").Bool() && !state.passive {
+			state.canceled = true
+		}
+		return Object{}
+	})
+	e.setMethod("stopPropagation", func(_ Object, _ []Object) Object {
+		state.stopped = true
+		return Object{}
+	})
+	e.setMethod("stopImmediatePropagation", func(_ Object, _ []Object) Object {
+		state.stopped, state.stoppedImmediately = true, true
+		return Object{}
+	})
+	e.setMethod("composedPath", func(_ Object, _ []Object) Object {
+		if state.target == nil {
+			return newArray(nil)
+		}
+		return nodeList(state.target.eventPath())
+	})
+	e.v.event = state
+	return e
+}
+
+// Returns target and its ancestors up to document and window, if the target is in document.
+func (n *node) eventPath() []*node {
+	path := []*node{}
+	for e := n; e != nil; e = e.parent {
+		path = append(path, e)
+	}
+	if last := path[len(path)-1]; last.nodeType == documentNode {
+		path = append(path, env.window.v.node)
+	}
+	return path
+}
+
+// Event phases, as in Event.eventPhase.
+const (
+	phaseNone      = 0
+	phaseCapturing = 1
+	phaseAtTarget  = 2
+	phaseBubbling  = 3
+)
+
+// Dispatches event to node n and returns false, if the default action was prevented.
+// Activation behavior of click events (checking checkbox, following link, clicking label's control) is done too.
+func (n *node) dispatch(e Object) bool {
+	state := e.val().event
+	if state == nil {
+		panic("js: TypeError: parameter 1 is not of type 'Event'")
+	}
+	*state = eventState{}
+	defer func() {
+		state.phase, state.currentTarget = phaseNone, nil
+	}()
+	state.target = n
+	eventType := e.Get("type").String()
+	path := n.eventPath()
+
+	// Activation behavior of click.
+	var activation *node
+	var legacyChecked *bool
+	if eventType == "click" {
+		for _, p := range path {
+			if p.hasActivationBehavior() {
+				activation = p
+				break
+			}
+		}
+		if activation != nil && activation.tag == "input" {
+			checked := activation.isChecked()
+			legacyChecked = &checked
+			switch activation.inputType() {
+			case "checkbox":
+				activation.setChecked(!checked)
+			case "radio":
+				activation.setChecked(true)
+			}
+		}
+	}
+
+	invoke := func(p *node, capturing bool) {
+		state.currentTarget = p
+		for _, l := range append([]*listener{}, p.listeners[eventType]...) {
+			if state.stoppedImmediately {
+				return
+			}
+			if l.removed || (state.phase == phaseCapturing && !l.capture) || (state.phase == phaseBubbling && l.capture) {
+				continue
+			}
+			if l.once {
+				p.removeEventListener(eventType, l.fn, valueOf(l.capture))
+			}
+			state.passive = l.passive
+			l.fn.invoke(p.obj, []interface{}{e})
+			state.passive = false
+		}
+	}
+
+	state.phase = phaseCapturing
+	for i := len(path) - 1; i > 0 && !state.stopped; i-- {
+		invoke(path[i], true)
+	}
+	if !state.stopped {
+		state.phase = phaseAtTarget
+		invoke(n, false)
+	}
+	if e.Get("bubbles").Bool() {
+		state.phase = phaseBubbling
+		for i := 1; i < len(path) && !state.stopped; i++ {
+			invoke(path[i], false)
+		}
+	}
+
+	if activation != nil {
+		if state.canceled {
+			if legacyChecked != nil {
+				activation.checked = legacyChecked
+			}
+		} else {
+			activation.activate(legacyChecked)
+		}
+	}
+	return !state.canceled
+}
+
+func (n *node) inputType() string {
+	t, _ := n.attr("type")
+	return strings.ToLower(t)
+}
+
+func (n *node) hasActivationBehavior() bool {
+	switch n.tag {
+	case "a":
+		_, has := n.attr("href")
+		return has
+	case "input":
+		t := n.inputType()
+		return t == "checkbox" || t == "radio"
+	case "label":
+		return n.labeledControl() != nil
+	}
+	return false
+}
+
+func (n *node) labeledControl() *node {
+	if id, ok := n.attr("for"); ok {
+		return env.getElementById(id)
+	}
+	for _, d := range n.descendants() {
+		switch d.tag {
+		case "input", "select", "textarea", "button":
+			return d
+		}
+	}
+	return nil
+}
+
+// Runs activation behavior after not canceled click event was dispatched.
+func (n *node) activate(legacyChecked *bool) {
+	switch n.tag {
+	case "input":
+		if legacyChecked != nil && *legacyChecked == n.isChecked() {
+			return
+		}
+		n.dispatch(newEvent("input", map[string]interface{}{"bubbles": true}))
+		n.dispatch(newEvent("change", map[string]interface{}{"bubbles": true}))
+	case "a":
+		href, _ := n.attr("href")
+		if filename, download := n.attr("download"); download {
+			env.download(href, filename)
+			return
+		}
+		env.navigate(href, false)
+	case "label":
+		if control := n.labeledControl(); control != nil {
+			control.obj.Call("click")
+		}
+	}
+}
+
+// Selectors.
+
+// Compound selector, like "div#id.class[attr=value]".
+type compoundSelector struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attributeSelector
+}
+
+type attributeSelector struct {
+	name, value string
+	hasValue    bool
+}
+
+// Complex selector is a list of compound selectors with combinators between them (' ' for descendant, '>' for child).
+type complexSelector struct {
+	compounds   []compoundSelector
+	combinators []byte
+}
+
+func parseCompound(s string) compoundSelector {
+	c := compoundSelector{}
+	for s != "" {
+		end := strings.IndexAny(s[1:], "#.[")
+		if end < 0 {
+			end = len(s)
+		} else {
+			end++
+		}
+		part := s[:end]
+		s = s[end:]
+		switch part[0] {
+		case '#':
+			c.id = part[1:]
+		case '.':
+			c.classes = append(c.classes, part[1:])
+		case '[':
+			inner := strings.TrimSuffix(part[1:], "]")
+			if i := strings.IndexByte(inner, '='); i >= 0 {
+				c.attrs = append(c.attrs, attributeSelector{strings.ToLower(inner[:i]), strings.Trim(inner[i+1:], `"'`), true})
+			} else {
+				c.attrs = append(c.attrs, attributeSelector{name: strings.ToLower(inner)})
+			}
+		default:
+			if part != "*" {
+				c.tag = strings.ToLower(part)
+			}
+		}
+	}
+	return c
+}
+
+// Parses comma separated list of selectors. Supported are type, id, class and attribute selectors, descendant and child combinators.
+func parseSelectors(selectors string) []complexSelector {
+	res := []complexSelector{}
+	for _, s := range strings.Split(selectors, ",") {
+		s = strings.ReplaceAll(s, ">", " > ")
+		cs := complexSelector{}
+		combinator := byte(' ')
+		for _, token := range strings.Fields(s) {
+			if token == ">" {
+				combinator = '>'
+				continue
+			}
+			if len(cs.compounds) > 0 {
+				cs.combinators = append(cs.combinators, combinator)
+			}
+			cs.compounds = append(cs.compounds, parseCompound(token))
+			combinator = ' '
+		}
+		if len(cs.compounds) > 0 {
+			res = append(res, cs)
+		}
+	}
+	return res
+}
+
+func (c compoundSelector) matches(n *node) bool {
+	if n.nodeType != elementNode || (c.tag != "" && c.tag != n.tag) {
+		return false
+	}
+	if id, _ := n.attr("id"); c.id != "" && c.id != id {
+		return false
+	}
+	for _, class := range c.classes {
+		if !n.hasClass(class) {
+			return false
+		}
+	}
+	for _, a := range c.attrs {
+		v, has := n.attr(a.name)
+		if !has || (a.hasValue && v != a.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches compound selectors up to index i against node n and its ancestors.
+func (cs complexSelector) matches(n *node, i int) bool {
+	if !cs.compounds[i].matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	if cs.combinators[i-1] == '>' {
+		return n.parent != nil && cs.matches(n.parent, i-1)
+	}
+	for a := n.parent; a != nil; a = a.parent {
+		if cs.matches(a, i-1) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesSelector(n *node, selectors string) bool {
+	for _, cs := range parseSelectors(selectors) {
+		if cs.matches(n, len(cs.compounds)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *node) querySelectorAll(selectors string) []*node {
+	parsed := parseSelectors(selectors)
+	res := []*node{}
+	for _, d := range n.descendants() {
+		for _, cs := range parsed {
+			if cs.matches(d, len(cs.compounds)-1) {
+				res = append(res, d)
+				break
+			}
+		}
+	}
+	return res
+}
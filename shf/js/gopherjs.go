@@ -21,3 +21,11 @@ var FuncOf = func(fn func(_ Object, _ []Object) any) Func {
 }
 
 func IsUndefined(o Object) bool { return o == js.Undefined }
+
+func IsNull(o Object) bool { return o == nil }
+
+// Uint8Array returns a new JavaScript Uint8Array with a copy of bytes b.
+// Byte slices are passed to JavaScript as Uint8Array in gopherjs, so it is only copied.
+func Uint8Array(b []byte) Object {
+	return js.Global.Get("Uint8Array").New(b)
+}
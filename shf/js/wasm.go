@@ -16,3 +16,12 @@ var Undefined func() Object = js.Undefined
 var FuncOf func(fn func(this Object, args []Object) any) Func = js.FuncOf
 
 func IsUndefined(o Object) bool { return o.IsUndefined() }
+
+func IsNull(o Object) bool { return o.IsNull() }
+
+// Uint8Array returns a new JavaScript Uint8Array with a copy of bytes b.
+func Uint8Array(b []byte) Object {
+	a := js.Global().Get("Uint8Array").New(len(b))
+	js.CopyBytesToJS(a, b)
+	return a
+}
@@ -0,0 +1,904 @@
+//go:build !js || (!ecmascript && !wasm)
+
+package js
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Origin of the in-memory page.
+const origin = "http://localhost"
+
+// Animation frames are run at 60 frames per second of the virtual clock.
+const frameDuration = time.Second / 60
+
+// Download is a file saved by clicking a link with download attribute.
+type Download struct {
+	Filename, Type string
+	Data           []byte
+}
+
+type task struct {
+	id, seq  int
+	at       time.Duration
+	interval time.Duration
+	fn       func()
+}
+
+type historyEntry struct {
+	href  string
+	state Object
+}
+
+// State of the in-memory page.
+type environment struct {
+	window   Object
+	document *node
+	focused  *node
+
+	// Virtual clock and scheduled timers, animation frames and events.
+	now             time.Duration
+	tasks           []*task
+	lastID, lastSeq int
+
+	history      []historyEntry
+	historyIndex int
+
+	storage     map[string]string
+	storageKeys []string
+
+	blobs     map[string]Object
+	lastBlob  int
+	alerts    []string
+	downloads []Download
+	clipboard string
+}
+
+var env *environment
+
+// Window value is the same for the whole run, because packages keep it (e.g. shf.Window). ResetDOM only resets its content.
+var windowValue = &value{kind: kindObject}
+
+func window() Object {
+	if env == nil {
+		ResetDOM()
+	}
+	return Object{windowValue}
+}
+
+// ResetDOM replaces the page with a new empty one, at "http://localhost/", with empty storage and no timers.
+// Objects from the old page stay usable, but are not connected to the new page.
+func ResetDOM() {
+	*windowValue = value{kind: kindObject}
+	w := Object{windowValue}
+	wn := &node{obj: w, nodeType: windowNode}
+	windowValue.node, windowValue.get, windowValue.set = wn, wn.get, wn.set
+
+	document := newNode(documentNode, "")
+	htmlElement := newNode(elementNode, "html")
+	document.insert(htmlElement, nil)
+	htmlElement.insert(newNode(elementNode, "head"), nil)
+	htmlElement.insert(newNode(elementNode, "body"), nil)
+
+	env = &environment{
+		window:   w,
+		document: document,
+		history:  []historyEntry{{href: origin + "/", state: Null()}},
+		storage:  map[string]string{},
+		blobs:    map[string]Object{},
+	}
+
+	w.setProp("window", w)
+	w.setProp("self", w)
+	w.setProp("globalThis", w)
+	w.setProp("document", document.obj)
+	w.setProp("location", newLocation())
+	w.setProp("history", newHistory())
+	w.setProp("localStorage", newStorage())
+	w.setProp("navigator", valueOf(map[string]interface{}{
+		"language":  "en",
+		"languages": []interface{}{"en"},
+		"userAgent": "Go in-memory DOM",
+	}))
+	w.setProp("URL", newURLObject())
+	w.setProp("devicePixelRatio", valueOf(1))
+	w.setProp("innerWidth", valueOf(1024))
+	w.setProp("innerHeight", valueOf(768))
+	for _, name := range []string{"pageXOffset", "pageYOffset", "scrollX", "scrollY"} {
+		w.setProp(name, valueOf(0))
+	}
+
+	w.setMethod("setTimeout", func(_ Object, args []Object) Object {
+		return valueOf(env.schedule(callback(args), time.Duration(arg(args, 1).Int())*time.Millisecond, false))
+	})
+	w.setMethod("setInterval", func(_ Object, args []Object) Object {
+		return valueOf(env.schedule(callback(args), time.Duration(arg(args, 1).Int())*time.Millisecond, true))
+	})
+	w.setMethod("clearTimeout", func(_ Object, args []Object) Object {
+		env.cancel(arg(args, 0).Int())
+		return Object{}
+	})
+	w.setProp("clearInterval", w.Get("clearTimeout"))
+	w.setMethod("requestAnimationFrame", func(_ Object, args []Object) Object {
+		fn := arg(args, 0)
+		// Callbacks requested before a frame are run together in the frame.
+		frame := (env.now/frameDuration + 1) * frameDuration
+		return valueOf(env.scheduleAt(frame, 0, func() {
+			fn.invoke(Object{}, []interface{}{float64(env.now) / float64(time.Millisecond)})
+		}))
+	})
+	w.setProp("cancelAnimationFrame", w.Get("clearTimeout"))
+
+	w.setMethod("alert", func(_ Object, args []Object) Object {
+		env.alerts = append(env.alerts, arg(args, 0).String())
+		return Object{}
+	})
+	w.setMethod("confirm", func(_ Object, args []Object) Object {
+		env.alerts = append(env.alerts, arg(args, 0).String())
+		return valueOf(false)
+	})
+	w.setMethod("matchMedia", func(_ Object, args []Object) Object {
+		mql := valueOf(map[string]interface{}{"matches": false, "media": arg(args, 0).String()})
+		for _, name := range []string{"addEventListener", "removeEventListener", "addListener", "removeListener"} {
+			mql.setMethod(name, func(_ Object, _ []Object) Object { return Object{} })
+		}
+		return mql
+	})
+	for _, name := range []string{"scrollTo", "scroll", "scrollBy", "focus", "blur"} {
+		w.setMethod(name, func(_ Object, _ []Object) Object { return Object{} })
+	}
+	w.setMethod("encodeURIComponent", func(_ Object, args []Object) Object {
+		return valueOf(encodeURIComponent(arg(args, 0).String()))
+	})
+	w.setMethod("decodeURIComponent", func(_ Object, args []Object) Object {
+		s, err := url.PathUnescape(arg(args, 0).String())
+		if err != nil {
+			panic("js: URIError: malformed URI sequence")
+		}
+		return valueOf(s)
+	})
+
+	w.setProp("Uint8Array", newConstructor(func(args []Object) Object {
+		a := arg(args, 0)
+		if b := a.val().bytes; b != nil {
+			return newUint8Array(append([]byte{}, b...))
+		}
+		if elems := a.val().elems; elems != nil {
+			b := make([]byte, len(elems))
+			for i, e := range elems {
+				b[i] = byte(e.Int())
+			}
+			return newUint8Array(b)
+		}
+		return newUint8Array(make([]byte, a.Int()))
+	}))
+	w.setProp("Blob", newConstructor(func(args []Object) Object {
+		data := []byte{}
+		for _, part := range arg(args, 0).val().elems {
+			if b := part.val().bytes; b != nil {
+				data = append(data, b...)
+				continue
+			}
+			data = append(data, part.String()...)
+		}
+		mimeType := ""
+		if options := arg(args, 1); options.val().kind == kindObject {
+			mimeType = options.Get("type").String()
+			if IsUndefined(options.Get("type")) {
+				mimeType = ""
+			}
+		}
+		blob := newObject()
+		blob.v.bytes = data
+		blob.setProp("size", valueOf(len(data)))
+		blob.setProp("type", valueOf(strings.ToLower(mimeType)))
+		return blob
+	}))
+	for _, name := range []string{"Event", "CustomEvent", "UIEvent", "MouseEvent", "KeyboardEvent", "PointerEvent", "FocusEvent", "InputEvent", "HashChangeEvent", "PopStateEvent"} {
+		w.setProp(name, newConstructor(func(args []Object) Object {
+			props := map[string]interface{}{}
+			if init := arg(args, 1); init.val().kind == kindObject {
+				for _, k := range init.v.keys {
+					props[k] = init.v.props[k]
+				}
+			}
+			return newEvent(arg(args, 0).String(), props)
+		}))
+	}
+}
+
+func newConstructor(ctor func(args []Object) Object) Object {
+	c := newFunction(nil)
+	c.v.ctor = ctor
+	return c
+}
+
+func newUint8Array(b []byte) Object {
+	o := newObject()
+	o.v.bytes = b
+	o.v.get = func(key string) (Object, bool) {
+		switch key {
+		case "length", "byteLength":
+			return valueOf(len(o.v.bytes)), true
+		}
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(o.v.bytes) {
+			return valueOf(o.v.bytes[i]), true
+		}
+		return Object{}, false
+	}
+	o.v.set = func(key string, v Object) bool {
+		if i, err := strconv.Atoi(key); err == nil {
+			if i >= 0 && i < len(o.v.bytes) {
+				o.v.bytes[i] = byte(v.Int())
+			}
+			return true
+		}
+		return false
+	}
+	return o
+}
+
+// Returns function from timer arguments, which calls callback with additional arguments.
+func callback(args []Object) func() {
+	fn := arg(args, 0)
+	if fn.val().kind != kindFunction {
+		panic("js: TypeError: timer callback is not a function")
+	}
+	rest := []interface{}{}
+	for _, a := range args[min(2, len(args)):] {
+		rest = append(rest, a)
+	}
+	return func() { fn.invoke(Object{}, rest) }
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func encodeURIComponent(s string) string {
+	const unreserved = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_.!~*'()"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(unreserved, s[i]) >= 0 {
+			b.WriteByte(s[i])
+			continue
+		}
+		b.WriteString("%" + strings.ToUpper(strconv.FormatUint(uint64(s[i])>>4, 16)+strconv.FormatUint(uint64(s[i])&15, 16)))
+	}
+	return b.String()
+}
+
+// Timers.
+
+func (e *environment) schedule(fn func(), delay time.Duration, repeat bool) int {
+	if delay < 0 {
+		delay = 0
+	}
+	interval := time.Duration(0)
+	if repeat {
+		// Intervals are clamped, so they can not run forever in one instant.
+		interval = delay
+		if interval < time.Millisecond {
+			interval = time.Millisecond
+		}
+	}
+	return e.scheduleAt(e.now+delay, interval, fn)
+}
+
+func (e *environment) scheduleAt(at, interval time.Duration, fn func()) int {
+	e.lastID++
+	e.lastSeq++
+	e.tasks = append(e.tasks, &task{id: e.lastID, seq: e.lastSeq, at: at, interval: interval, fn: fn})
+	return e.lastID
+}
+
+func (e *environment) cancel(id int) {
+	for i, t := range e.tasks {
+		if t.id == id {
+			e.tasks = append(e.tasks[:i], e.tasks[i+1:]...)
+			return
+		}
+	}
+}
+
+// Returns the earliest task due up to time, or nil.
+func (e *environment) nextTask(until time.Duration) *task {
+	var next *task
+	for _, t := range e.tasks {
+		if t.at <= until && (next == nil || t.at < next.at || (t.at == next.at && t.seq < next.seq)) {
+			next = t
+		}
+	}
+	return next
+}
+
+// Queues function to run as soon as possible, after already due tasks (e.g. event fired asynchronously).
+func (e *environment) queue(fn func()) {
+	e.scheduleAt(e.now, 0, fn)
+}
+
+// Page focus.
+
+func (e *environment) focus(n *node) {
+	if n != nil && (n.nodeType != elementNode || !n.connected() || !n.focusable()) {
+		return
+	}
+	old := e.focused
+	if old == n {
+		return
+	}
+	e.focused = n
+	if old != nil {
+		old.dispatch(newEvent("blur", nil))
+		old.dispatch(newEvent("focusout", map[string]interface{}{"bubbles": true}))
+	}
+	if n != nil {
+		n.dispatch(newEvent("focus", nil))
+		n.dispatch(newEvent("focusin", map[string]interface{}{"bubbles": true}))
+	}
+}
+
+// Document.
+
+func (e *environment) body() *node {
+	return e.document.children[0].children[1]
+}
+
+func (e *environment) getElementById(id string) *node {
+	for _, d := range e.document.descendants() {
+		if v, _ := d.attr("id"); v == id {
+			return d
+		}
+	}
+	return nil
+}
+
+func documentGet(key string) (Object, bool) {
+	switch key {
+	case "nodeType":
+		return valueOf(documentNode), true
+	case "documentElement":
+		return env.document.children[0].obj, true
+	case "head":
+		return env.document.children[0].children[0].obj, true
+	case "body":
+		return env.body().obj, true
+	case "activeElement":
+		if env.focused != nil {
+			return env.focused.obj, true
+		}
+		return env.body().obj, true
+	case "location":
+		return env.window.Get("location"), true
+	case "defaultView":
+		return env.window, true
+	case "readyState":
+		return valueOf("complete"), true
+	case "URL":
+		return valueOf(env.href()), true
+	case "children", "childNodes":
+		return nodeList(env.document.children), true
+	case "textContent":
+		return Null(), true
+	}
+	return Object{}, false
+}
+
+// Methods of document, in addition to node methods.
+var documentMethods map[string]Object
+
+func init() {
+	documentMethods = map[string]Object{
+		"createElement": newFunction(func(_ Object, args []Object) Object {
+			return newNode(elementNode, arg(args, 0).String()).obj
+		}),
+		"createElementNS": newFunction(func(_ Object, args []Object) Object {
+			return newNode(elementNode, arg(args, 1).String()).obj
+		}),
+		"createTextNode": newFunction(func(_ Object, args []Object) Object {
+			t := newNode(textNode, "")
+			t.data = arg(args, 0).String()
+			return t.obj
+		}),
+		"getElementById": newFunction(func(_ Object, args []Object) Object {
+			return nodeObject(env.getElementById(arg(args, 0).String()))
+		}),
+		"hasFocus": newFunction(func(_ Object, _ []Object) Object {
+			return valueOf(true)
+		}),
+		// Appends parsed HTML to body.
+		"write": newFunction(func(_ Object, args []Object) Object {
+			for _, a := range args {
+				for _, c := range parseHTML(a.String()) {
+					env.body().insert(c, nil)
+				}
+			}
+			return Object{}
+		}),
+		// Only copy command is supported. It copies selected text of focused input or textarea to clipboard, see Clipboard.
+		"execCommand": newFunction(func(_ Object, args []Object) Object {
+			if strings.ToLower(arg(args, 0).String()) != "copy" {
+				return valueOf(false)
+			}
+			env.clipboard = ""
+			if f := env.focused; f != nil && (f.tag == "input" || f.tag == "textarea") {
+				v := f.formValue()
+				start, end := f.selStart, f.selEnd
+				if end > len(v) {
+					end = len(v)
+				}
+				if start >= 0 && start < end {
+					env.clipboard = v[start:end]
+				}
+			}
+			return valueOf(true)
+		}),
+	}
+}
+
+// Location and history.
+
+func (e *environment) href() string {
+	return e.history[e.historyIndex].href
+}
+
+// Splits URL to part without fragment and fragment. Fragment is "#" and what follows, or empty.
+func splitFragment(href string) (string, string) {
+	if i := strings.IndexByte(href, '#'); i >= 0 {
+		return href[:i], href[i:]
+	}
+	return href, ""
+}
+
+// Percent-encodes characters of fragment like browsers do, other characters are kept as they are.
+func encodeFragment(fragment string) string {
+	var b strings.Builder
+	for i := 0; i < len(fragment); i++ {
+		c := fragment[i]
+		if c <= ' ' || c == '"' || c == '<' || c == '>' || c == '`' || c >= 0x7f {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// Resolves URL relative to the current page.
+// Fragment-only URL (starting with "#") replaces the fragment of the current page, "#" alone leaves an empty fragment.
+func (e *environment) resolve(ref string) string {
+	if strings.HasPrefix(ref, "#") {
+		page, _ := splitFragment(e.href())
+		return page + encodeFragment(ref)
+	}
+	base, err := url.Parse(e.href())
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	res := base.ResolveReference(r).String()
+	if strings.HasSuffix(ref, "#") && !strings.HasSuffix(res, "#") {
+		res += "#"
+	}
+	return res
+}
+
+// Navigates to URL. Only navigation within the page (fragment change) is supported, other URLs are ignored.
+// Navigation pushes a new history entry, or replaces the current one, and fires hashchange event, if the fragment changed.
+func (e *environment) navigate(ref string, replace bool) {
+	if strings.HasPrefix(ref, "blob:") || strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "javascript:") {
+		return
+	}
+	newURL := e.resolve(ref)
+	oldURL := e.href()
+	oldBase, oldFragment := splitFragment(oldURL)
+	newBase, newFragment := splitFragment(newURL)
+	if newBase != oldBase {
+		return
+	}
+	if replace {
+		e.history[e.historyIndex] = historyEntry{href: newURL, state: Null()}
+	} else {
+		e.history = append(e.history[:e.historyIndex+1], historyEntry{href: newURL, state: Null()})
+		e.historyIndex++
+	}
+	if newFragment != oldFragment {
+		e.fireHashChange(oldURL, newURL)
+	}
+}
+
+func (e *environment) fireHashChange(oldURL, newURL string) {
+	e.queue(func() {
+		env.window.v.node.dispatch(newEvent("hashchange", map[string]interface{}{"oldURL": oldURL, "newURL": newURL}))
+	})
+}
+
+// Traverses history by delta entries asynchronously, like history.go(delta).
+func (e *environment) traverse(delta int) {
+	e.queue(func() {
+		index := e.historyIndex + delta
+		if delta == 0 || index < 0 || index >= len(e.history) {
+			return
+		}
+		oldURL := e.href()
+		e.historyIndex = index
+		newURL := e.href()
+		e.window.v.node.dispatch(newEvent("popstate", map[string]interface{}{"state": e.history[index].state}))
+		if _, oldFragment := splitFragment(oldURL); true {
+			if _, newFragment := splitFragment(newURL); newFragment != oldFragment {
+				e.window.v.node.dispatch(newEvent("hashchange", map[string]interface{}{"oldURL": oldURL, "newURL": newURL}))
+			}
+		}
+	})
+}
+
+func newLocation() Object {
+	l := newObject()
+	l.v.get = func(key string) (Object, bool) {
+		u, err := url.Parse(env.href())
+		if err != nil {
+			return Object{}, false
+		}
+		switch key {
+		case "href":
+			return valueOf(env.href()), true
+		case "origin":
+			return valueOf(u.Scheme + "://" + u.Host), true
+		case "protocol":
+			return valueOf(u.Scheme + ":"), true
+		case "host", "hostname":
+			return valueOf(u.Host), true
+		case "port":
+			return valueOf(u.Port()), true
+		case "pathname":
+			return valueOf(u.EscapedPath()), true
+		case "search":
+			if u.RawQuery == "" {
+				return valueOf(""), true
+			}
+			return valueOf("?" + u.RawQuery), true
+		case "hash":
+			if _, fragment := splitFragment(env.href()); fragment != "#" {
+				return valueOf(fragment), true
+			}
+			return valueOf(""), true
+		}
+		return Object{}, false
+	}
+	l.v.set = func(key string, v Object) bool {
+		switch key {
+		case "href":
+			env.navigate(v.String(), false)
+			return true
+		case "hash":
+			_, fragment := splitFragment(env.href())
+			hash := "#" + strings.TrimPrefix(v.String(), "#")
+			if encodeFragment(hash) != fragment {
+				env.navigate(hash, false)
+			}
+			return true
+		}
+		return false
+	}
+	l.setMethod("assign", func(_ Object, args []Object) Object {
+		env.navigate(arg(args, 0).String(), false)
+		return Object{}
+	})
+	l.setMethod("replace", func(_ Object, args []Object) Object {
+		env.navigate(arg(args, 0).String(), true)
+		return Object{}
+	})
+	// Page is not reloaded, there is nothing to load it from.
+	l.setMethod("reload", func(_ Object, _ []Object) Object { return Object{} })
+	l.setMethod("toString", func(_ Object, _ []Object) Object { return valueOf(env.href()) })
+	return l
+}
+
+func newHistory() Object {
+	h := newObject()
+	h.v.get = func(key string) (Object, bool) {
+		switch key {
+		case "length":
+			return valueOf(len(env.history)), true
+		case "state":
+			return env.history[env.historyIndex].state, true
+		}
+		return Object{}, false
+	}
+	setState := func(args []Object, replace bool) {
+		href := env.href()
+		if ref := arg(args, 2); !IsUndefined(ref) && !IsNull(ref) {
+			href = env.resolve(ref.String())
+			if base, _ := splitFragment(href); base != func() string { b, _ := splitFragment(env.href()); return b }() {
+				panic("js: SecurityError: history state URL must be in the same document")
+			}
+		}
+		entry := historyEntry{href: href, state: arg(args, 0)}
+		if IsUndefined(entry.state) {
+			entry.state = Null()
+		}
+		if replace {
+			env.history[env.historyIndex] = entry
+			return
+		}
+		env.history = append(env.history[:env.historyIndex+1], entry)
+		env.historyIndex++
+	}
+	h.setMethod("pushState", func(_ Object, args []Object) Object {
+		setState(args, false)
+		return Object{}
+	})
+	h.setMethod("replaceState", func(_ Object, args []Object) Object {
+		setState(args, true)
+		return Object{}
+	})
+	h.setMethod("back", func(_ Object, _ []Object) Object {
+		env.traverse(-1)
+		return Object{}
+	})
+	h.setMethod("forward", func(_ Object, _ []Object) Object {
+		env.traverse(1)
+		return Object{}
+	})
+	h.setMethod("go", func(_ Object, args []Object) Object {
+		env.traverse(arg(args, 0).Int())
+		return Object{}
+	})
+	return h
+}
+
+// Storage.
+
+func newStorage() Object {
+	s := newObject()
+	s.v.get = func(key string) (Object, bool) {
+		if key == "length" {
+			return valueOf(len(env.storageKeys)), true
+		}
+		return Object{}, false
+	}
+	removeItem := func(key string) {
+		if _, ok := env.storage[key]; !ok {
+			return
+		}
+		delete(env.storage, key)
+		for i, k := range env.storageKeys {
+			if k == key {
+				env.storageKeys = append(env.storageKeys[:i], env.storageKeys[i+1:]...)
+				break
+			}
+		}
+	}
+	s.setMethod("getItem", func(_ Object, args []Object) Object {
+		if v, ok := env.storage[arg(args, 0).String()]; ok {
+			return valueOf(v)
+		}
+		return Null()
+	})
+	s.setMethod("setItem", func(_ Object, args []Object) Object {
+		key := arg(args, 0).String()
+		if _, ok := env.storage[key]; !ok {
+			env.storageKeys = append(env.storageKeys, key)
+		}
+		env.storage[key] = arg(args, 1).String()
+		return Object{}
+	})
+	s.setMethod("removeItem", func(_ Object, args []Object) Object {
+		removeItem(arg(args, 0).String())
+		return Object{}
+	})
+	s.setMethod("clear", func(_ Object, _ []Object) Object {
+		env.storage, env.storageKeys = map[string]string{}, nil
+		return Object{}
+	})
+	s.setMethod("key", func(_ Object, args []Object) Object {
+		if i := arg(args, 0).Int(); i >= 0 && i < len(env.storageKeys) {
+			return valueOf(env.storageKeys[i])
+		}
+		return Null()
+	})
+	return s
+}
+
+// Blobs and downloads.
+
+func newURLObject() Object {
+	u := newObject()
+	u.setMethod("createObjectURL", func(_ Object, args []Object) Object {
+		env.lastBlob++
+		href := "blob:" + origin + "/" + strconv.Itoa(env.lastBlob)
+		env.blobs[href] = arg(args, 0)
+		return valueOf(href)
+	})
+	u.setMethod("revokeObjectURL", func(_ Object, args []Object) Object {
+		delete(env.blobs, arg(args, 0).String())
+		return Object{}
+	})
+	return u
+}
+
+// Records download of blob or data URL.
+func (e *environment) download(href, filename string) {
+	d := Download{Filename: filename}
+	switch {
+	case strings.HasPrefix(href, "blob:"):
+		blob, ok := e.blobs[href]
+		if !ok {
+			return
+		}
+		d.Type, d.Data = blob.Get("type").String(), append([]byte{}, blob.val().bytes...)
+	case strings.HasPrefix(href, "data:"):
+		comma := strings.IndexByte(href, ',')
+		if comma < 0 {
+			return
+		}
+		meta, data := href[len("data:"):comma], href[comma+1:]
+		d.Type = strings.Split(meta, ";")[0]
+		if strings.HasSuffix(meta, ";base64") {
+			b, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				return
+			}
+			d.Data = b
+		} else {
+			s, err := url.PathUnescape(data)
+			if err != nil {
+				return
+			}
+			d.Data = []byte(s)
+		}
+	default:
+		return
+	}
+	if d.Filename == "" {
+		d.Filename = "download"
+	}
+	e.downloads = append(e.downloads, d)
+}
+
+// Driving the page from Go.
+
+// Document returns the document of the in-memory page.
+func Document() Object {
+	window()
+	return env.document.obj
+}
+
+// QuerySelector returns the first element in document matching CSS selector, or null.
+// Supported are type, id, class and attribute selectors, descendant and child combinators and selector lists.
+func QuerySelector(selector string) Object {
+	return Document().Call("querySelector", selector)
+}
+
+// QuerySelectorAll returns all elements in document matching CSS selector, in document order.
+func QuerySelectorAll(selector string) []Object {
+	window()
+	res := []Object{}
+	for _, n := range env.document.querySelectorAll(selector) {
+		res = append(res, n.obj)
+	}
+	return res
+}
+
+// Events of these types do not bubble, when dispatched by Dispatch.
+var nonBubblingEvents = map[string]bool{
+	"focus": true, "blur": true, "load": true, "unload": true, "scroll": true,
+	"mouseenter": true, "mouseleave": true, "pointerenter": true, "pointerleave": true,
+}
+
+// Dispatch fires event of type on target (element, document or window), like a user would, and runs due timers.
+// Event properties (e.g. "key", "clientX") are set from props. Events bubble and are cancelable,
+// unless set otherwise by props "bubbles" and "cancelable". Clicks do their default action, like checking a checkbox.
+// Returns false, if a listener prevented the default action.
+func Dispatch(target Object, eventType string, props map[string]interface{}) bool {
+	window()
+	n := objectNode(target)
+	if n == nil {
+		panic("js: Dispatch: target is not an event target")
+	}
+	init := map[string]interface{}{"bubbles": !nonBubblingEvents[eventType], "cancelable": true, "isTrusted": true}
+	for k, v := range props {
+		init[k] = v
+	}
+	res := n.dispatch(newEvent(eventType, init))
+	RunPending()
+	return res
+}
+
+// Click focuses element and clicks it, like a user would.
+func Click(element Object) bool {
+	window()
+	env.focus(objectNode(element))
+	return Dispatch(element, "click", map[string]interface{}{"button": 0, "detail": 1})
+}
+
+// ChangeValue sets value of input, textarea or select and fires input and change events, like a user would.
+func ChangeValue(element Object, value string) {
+	element.Set("value", value)
+	Dispatch(element, "input", map[string]interface{}{"cancelable": false})
+	Dispatch(element, "change", map[string]interface{}{"cancelable": false})
+}
+
+// SetHash navigates to fragment of the page, like a user would by editing address bar, and fires hashchange event.
+func SetHash(hash string) {
+	window()
+	env.navigate("#"+strings.TrimPrefix(hash, "#"), false)
+	RunPending()
+}
+
+// Back goes back in history, like the browser's back button.
+func Back() {
+	window()
+	env.traverse(-1)
+	RunPending()
+}
+
+// Forward goes forward in history, like the browser's forward button.
+func Forward() {
+	window()
+	env.traverse(1)
+	RunPending()
+}
+
+// Now returns time of the virtual clock, since the page was reset.
+func Now() time.Duration {
+	window()
+	return env.now
+}
+
+// AdvanceTime moves the virtual clock by d and runs timers, animation frames and asynchronous events due until then, in order.
+func AdvanceTime(d time.Duration) {
+	window()
+	until := env.now + d
+	for t := env.nextTask(until); t != nil; t = env.nextTask(until) {
+		env.now = t.at
+		if t.interval > 0 {
+			env.lastSeq++
+			t.at, t.seq = t.at+t.interval, env.lastSeq
+		} else {
+			env.cancel(t.id)
+		}
+		t.fn()
+	}
+	env.now = until
+}
+
+// RunPending runs timers and asynchronous events, which are due now, e.g. zero delay timeouts or hashchange events.
+func RunPending() {
+	AdvanceTime(0)
+}
+
+// PendingTimers returns number of scheduled timeouts, intervals, animation frames and asynchronous events.
+func PendingTimers() int {
+	window()
+	return len(env.tasks)
+}
+
+// Alerts returns messages of all alert and confirm calls.
+func Alerts() []string {
+	window()
+	return append([]string{}, env.alerts...)
+}
+
+// Downloads returns files downloaded by clicking links with download attribute.
+func Downloads() []Download {
+	window()
+	return append([]Download{}, env.downloads...)
+}
+
+// Clipboard returns text copied by document.execCommand("copy").
+func Clipboard() string {
+	window()
+	return env.clipboard
+}
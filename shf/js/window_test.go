@@ -0,0 +1,68 @@
+//go:build !js || (!ecmascript && !wasm)
+
+package js
+
+import "testing"
+
+func TestLocationFragmentOnlyURL(t *testing.T) {
+	ResetDOM()
+	location := Global().Get("location")
+	hashChanges := 0
+	Global().Call("addEventListener", "hashchange", FuncOf(func(this Object, args []Object) any {
+		hashChanges++
+		return nil
+	}))
+
+	SetHash("Lbzj")
+	if got := location.Get("href").String(); got != "http://localhost/#Lbzj" {
+		t.Fatalf("href after SetHash = %q", got)
+	}
+
+	tests := []struct {
+		name       string
+		replace    string
+		href, hash string
+		changed    bool
+	}{
+		{"clear", "#", "http://localhost/#", "", true},
+		{"same empty", "#", "http://localhost/#", "", false},
+		{"set", "#Lbzj", "http://localhost/#Lbzj", "#Lbzj", true},
+		{"same", "#Lbzj", "http://localhost/#Lbzj", "#Lbzj", false},
+		{"promotion characters", "#/game/Lbzj^@$*~-_", "http://localhost/#/game/Lbzj^@$*~-_", "#/game/Lbzj^@$*~-_", true},
+		{"encoded", "#a b", "http://localhost/#a%20b", "#a%20b", true},
+	}
+	for _, test := range tests {
+		length := Global().Get("history").Get("length").Int()
+		before := hashChanges
+		location.Call("replace", test.replace)
+		RunPending()
+		if got := location.Get("href").String(); got != test.href {
+			t.Errorf("%s: href = %q, want %q", test.name, got, test.href)
+		}
+		if got := location.Get("hash").String(); got != test.hash {
+			t.Errorf("%s: hash = %q, want %q", test.name, got, test.hash)
+		}
+		if got := Global().Get("history").Get("length").Int(); got != length {
+			t.Errorf("%s: replace changed history length from %d to %d", test.name, length, got)
+		}
+		if changed := hashChanges != before; changed != test.changed {
+			t.Errorf("%s: hashchange fired %v, want %v", test.name, changed, test.changed)
+		}
+	}
+
+	// Setting location hash pushes an entry, which can be traversed back.
+	location.Set("hash", "")
+	location.Set("hash", "e4")
+	RunPending()
+	if got := location.Get("href").String(); got != "http://localhost/#e4" {
+		t.Errorf("href after setting hash = %q", got)
+	}
+	Back()
+	if got := location.Get("hash").String(); got != "" {
+		t.Errorf("hash after Back = %q, want empty", got)
+	}
+	Back()
+	if got := location.Get("hash").String(); got != "#a%20b" {
+		t.Errorf("hash after second Back = %q, want %q", got, "#a%20b")
+	}
+}
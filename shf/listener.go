@@ -0,0 +1,114 @@
+package shf
+
+import (
+	"URLchess/shf/js"
+	"errors"
+)
+
+// ListenerOptions are options of addEventListener.
+type ListenerOptions struct {
+	// Capture handles the event in the capture phase, before the target's descendants get it.
+	Capture bool
+	// Once removes the listener after it handled the first event.
+	Once bool
+	// Passive listeners promise not to call preventDefault, so the browser does not have to wait for them (e.g. while scrolling).
+	Passive bool
+}
+
+// Listener is a registered event listener. Use Remove to unregister it.
+type Listener struct {
+	app      *App
+	event    string
+	target   Element
+	options  ListenerOptions
+	callback js.Func
+	removed  bool
+}
+
+// Event returns name of the event the listener handles.
+func (l *Listener) Event() string { return l.event }
+
+// Target returns element the listener is registered on.
+func (l *Listener) Target() Element { return l.target }
+
+// Remove unregisters the listener. It is safe to call it more times, or from the listener's own handler.
+func (l *Listener) Remove() {
+	if l == nil || l.removed {
+		return
+	}
+	l.removed = true
+	l.target.Call("removeEventListener", l.event, l.callback, l.options.Capture)
+	l.callback.Release()
+	delete(l.app.listeners, l)
+}
+
+// Listen registers function as a listener of any DOM event (e.g. "contextmenu", "resize", "storage") on target.
+// Unlike Click, KeyDown, ..., which keep one function per event and target, any number of listeners can be added and each can be removed by its handle.
+func (app *App) Listen(target Element, eventName string, options ListenerOptions, function func(e Event) error) (*Listener, error) {
+	if app == nil {
+		return nil, errors.New("App is nil")
+	}
+	if eventName == "" {
+		return nil, errors.New("no event name")
+	}
+	target = app.resolve(target)
+	if target == nil {
+		return nil, errors.New("no target")
+	}
+	if function == nil {
+		return nil, errors.New("no function")
+	}
+
+	l := &Listener{
+		app:     app,
+		event:   eventName,
+		target:  target,
+		options: options,
+	}
+	l.callback = js.FuncOf(func(_ js.Object, args []js.Object) any {
+		if l.removed {
+			return nil
+		}
+		if l.options.Once {
+			l.Remove()
+		}
+		if len(args) < 1 {
+			app.ReportError(eventName, target, errors.New("called with no arguments"))
+			return nil
+		}
+		app.handle(eventName, target, func() error { return function(args[0]) })
+		return nil
+	})
+
+	if options.Once || options.Passive {
+		target.Call("addEventListener", eventName, l.callback, map[string]interface{}{
+			"capture": options.Capture,
+			"once":    options.Once,
+			"passive": options.Passive,
+		})
+	} else {
+		target.Call("addEventListener", eventName, l.callback, options.Capture)
+	}
+
+	if app.listeners == nil {
+		app.listeners = map[*Listener]struct{}{}
+	}
+	app.listeners[l] = struct{}{}
+	return l, nil
+}
+
+// RemoveListeners removes all listeners of event on target, registered by Listen, Click, KeyDown, ...
+// If eventName is empty, listeners of all events on target are removed.
+func (app *App) RemoveListeners(target Element, eventName string) {
+	target = app.resolve(target)
+	for l := range app.listeners {
+		if l.target == target && (eventName == "" || l.event == eventName) {
+			l.Remove()
+		}
+	}
+	for name, targets := range app.events {
+		if eventName == "" || name == eventName {
+			delete(targets, target)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package shf
+
+import (
+	"errors"
+	"strings"
+)
+
+// Route is a location hash matched by Router.
+type Route struct {
+	// Location hash without the leading "#".
+	Hash string
+	// Matched path, e.g. "/game/", empty if the fallback handler matched.
+	Path string
+	// Rest of the hash after a matched path prefix, or the whole hash for the fallback handler.
+	Param string
+}
+
+type route struct {
+	path    string
+	handler func(Route) error
+}
+
+// Router maps location hash paths to views, e.g. "#/settings" or "#/game/<moves>".
+// Paths start with "/", hashes not starting with "/" (or not matching any path) are passed to the fallback handler.
+type Router struct {
+	routes   []route
+	fallback func(Route) error
+}
+
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Handle registers handler of path. A path ending with "/" matches all hashes with this prefix, the rest is passed in Route.Param.
+// Other paths match the hash exactly. If more paths match, the longest wins.
+func (r *Router) Handle(path string, handler func(Route) error) *Router {
+	r.routes = append(r.routes, route{path, handler})
+	return r
+}
+
+// Fallback registers handler of hashes not matching any path, e.g. links from before paths were introduced.
+func (r *Router) Fallback(handler func(Route) error) *Router {
+	r.fallback = handler
+	return r
+}
+
+// Match returns route of hash and its handler, nil if no handler matches.
+func (r *Router) Match(hash string) (Route, func(Route) error) {
+	hash = strings.TrimPrefix(hash, "#")
+	var matched *route
+	for i, rt := range r.routes {
+		if !strings.HasPrefix(hash, "/") {
+			break
+		}
+		if hash != rt.path && !(strings.HasSuffix(rt.path, "/") && strings.HasPrefix(hash, rt.path)) {
+			continue
+		}
+		if matched == nil || len(rt.path) > len(matched.path) {
+			matched = &r.routes[i]
+		}
+	}
+	if matched == nil {
+		return Route{Hash: hash, Param: hash}, r.fallback
+	}
+	return Route{Hash: hash, Path: matched.path, Param: strings.TrimPrefix(hash, matched.path)}, matched.handler
+}
+
+// Dispatch calls handler matching hash.
+func (r *Router) Dispatch(hash string) error {
+	rt, handler := r.Match(hash)
+	if handler == nil {
+		return errors.New("no route for hash: #" + rt.Hash)
+	}
+	return handler(rt)
+}
+
+// Route dispatches the location hash by router on every hash change.
+func (app *App) Route(router *Router) error {
+	return app.HashChange(func(HashChangeEvent) error {
+		return router.Dispatch(LocationHash())
+	})
+}
+
+// LocationHash returns the current location hash, including the leading "#" (or empty).
+func LocationHash() string {
+	return Window.Get("location").Get("hash").String()
+}
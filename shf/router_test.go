@@ -0,0 +1,79 @@
+package shf_test
+
+import (
+	"URLchess/shf"
+	"testing"
+)
+
+func TestRouterMatch(t *testing.T) {
+	handled := ""
+	handler := func(name string) func(shf.Route) error {
+		return func(shf.Route) error {
+			handled = name
+			return nil
+		}
+	}
+	router := shf.NewRouter().
+		Handle("/game/", handler("game")).
+		Handle("/game/settings/", handler("game settings")).
+		Handle("/settings", handler("settings")).
+		Fallback(handler("fallback"))
+
+	for _, tc := range []struct {
+		hash    string
+		route   shf.Route
+		handler string
+	}{
+		{"", shf.Route{}, "fallback"},
+		{"#", shf.Route{}, "fallback"},
+		{"#Lbzj", shf.Route{Hash: "Lbzj", Param: "Lbzj"}, "fallback"},
+		{"Lbzj~-tCm~.3", shf.Route{Hash: "Lbzj~-tCm~.3", Param: "Lbzj~-tCm~.3"}, "fallback"},
+		{"#/settings", shf.Route{Hash: "/settings", Path: "/settings"}, "settings"},
+		{"#/settings/more", shf.Route{Hash: "/settings/more", Param: "/settings/more"}, "fallback"},
+		{"#/game/", shf.Route{Hash: "/game/", Path: "/game/"}, "game"},
+		{"#/game/Lbzj", shf.Route{Hash: "/game/Lbzj", Path: "/game/", Param: "Lbzj"}, "game"},
+		{"#/game/settings/x", shf.Route{Hash: "/game/settings/x", Path: "/game/settings/", Param: "x"}, "game settings"},
+		{"#/game", shf.Route{Hash: "/game", Param: "/game"}, "fallback"},
+		{"#/x", shf.Route{Hash: "/x", Param: "/x"}, "fallback"},
+	} {
+		route, h := router.Match(tc.hash)
+		if route != tc.route {
+			t.Errorf("Match(%q) route %+v, want %+v", tc.hash, route, tc.route)
+		}
+		handled = ""
+		if h == nil {
+			t.Errorf("Match(%q) has no handler", tc.hash)
+		} else if err := h(route); err != nil || handled != tc.handler {
+			t.Errorf("Match(%q) handler %q (error %v), want %q", tc.hash, handled, err, tc.handler)
+		}
+
+		handled = ""
+		if err := router.Dispatch(tc.hash); err != nil || handled != tc.handler {
+			t.Errorf("Dispatch(%q) handler %q (error %v), want %q", tc.hash, handled, err, tc.handler)
+		}
+	}
+}
+
+func TestRouterWithoutFallback(t *testing.T) {
+	var got shf.Route
+	router := shf.NewRouter().Handle("/game/", func(r shf.Route) error {
+		got = r
+		return nil
+	})
+
+	if err := router.Dispatch("#/game/Lb"); err != nil {
+		t.Fatal(err)
+	}
+	if want := (shf.Route{Hash: "/game/Lb", Path: "/game/", Param: "Lb"}); got != want {
+		t.Errorf("dispatched route %+v, want %+v", got, want)
+	}
+
+	for _, hash := range []string{"#Lb", "#/x"} {
+		if _, h := router.Match(hash); h != nil {
+			t.Errorf("Match(%q) has handler without fallback", hash)
+		}
+		if err := router.Dispatch(hash); err == nil {
+			t.Errorf("Dispatch(%q) is not an error without fallback", hash)
+		}
+	}
+}
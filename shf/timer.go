@@ -0,0 +1,146 @@
+package shf
+
+import (
+	"URLchess/shf/js"
+	"time"
+)
+
+// TimerOptions are options of scheduled callbacks.
+type TimerOptions struct {
+	// Owner element, the timer is cancelled when the owner is destroyed.
+	Owner Element
+	// NoUpdate skips the app update after the callback. The callback can still update components with MarkDirty or AppUpdate.
+	NoUpdate bool
+}
+
+type timerKind int
+
+const (
+	timeoutTimer timerKind = iota
+	intervalTimer
+	frameTimer
+)
+
+// Timer is a scheduled timeout, interval or animation frame callback. Use Cancel to stop it.
+type Timer struct {
+	app      *App
+	kind     timerKind
+	id       int
+	owner    Element
+	callback js.Func
+	active   bool
+}
+
+// Active returns true, if the callback is still going to be called.
+func (t *Timer) Active() bool {
+	return t != nil && t.active
+}
+
+// Cancel stops the timer. It is safe to call it on nil or stopped timer, or from the timer's own callback.
+func (t *Timer) Cancel() {
+	if !t.Active() {
+		return
+	}
+	switch t.kind {
+	case timeoutTimer:
+		js.Global().Call("clearTimeout", t.id)
+	case intervalTimer:
+		js.Global().Call("clearInterval", t.id)
+	case frameTimer:
+		js.Global().Call("cancelAnimationFrame", t.id)
+	}
+	t.stop()
+}
+
+func (t *Timer) stop() {
+	t.active = false
+	t.callback.Release()
+	delete(t.app.timers, t)
+}
+
+// Timer calls callback after duration and updates the app.
+func (app *App) Timer(duration time.Duration, callback func()) *Timer {
+	return app.After(duration, TimerOptions{}, callback)
+}
+
+// After calls callback once after duration.
+func (app *App) After(duration time.Duration, options TimerOptions, callback func()) *Timer {
+	t := app.newTimer(timeoutTimer, options)
+	t.callback = js.FuncOf(func(this js.Object, args []js.Object) any {
+		if !t.active {
+			return nil
+		}
+		t.stop()
+		app.handle("timer", t.owner, func() error {
+			callback()
+			if !options.NoUpdate {
+				app.dirtyApp = true
+			}
+			return nil
+		})
+		return nil
+	})
+	t.id = js.Global().Call("setTimeout", t.callback, milliseconds(duration)).Int()
+	return t
+}
+
+// Every calls callback repeatedly, every interval, until the timer is cancelled.
+func (app *App) Every(interval time.Duration, options TimerOptions, callback func()) *Timer {
+	t := app.newTimer(intervalTimer, options)
+	t.callback = js.FuncOf(func(this js.Object, args []js.Object) any {
+		if !t.active {
+			return nil
+		}
+		app.handle("interval", t.owner, func() error {
+			callback()
+			if !options.NoUpdate {
+				app.dirtyApp = true
+			}
+			return nil
+		})
+		return nil
+	})
+	t.id = js.Global().Call("setInterval", t.callback, milliseconds(interval)).Int()
+	return t
+}
+
+// AnimationFrame calls callback once before the next repaint, with the frame's timestamp in milliseconds.
+// Request the next frame from the callback to animate.
+func (app *App) AnimationFrame(options TimerOptions, callback func(timestamp float64)) *Timer {
+	t := app.newTimer(frameTimer, options)
+	t.callback = js.FuncOf(func(this js.Object, args []js.Object) any {
+		if !t.active {
+			return nil
+		}
+		t.stop()
+		timestamp := 0.0
+		if len(args) > 0 {
+			timestamp = args[0].Float()
+		}
+		app.handle("animation", t.owner, func() error {
+			callback(timestamp)
+			if !options.NoUpdate {
+				app.dirtyApp = true
+			}
+			return nil
+		})
+		return nil
+	})
+	t.id = js.Global().Call("requestAnimationFrame", t.callback).Int()
+	return t
+}
+
+func (app *App) newTimer(kind timerKind, options TimerOptions) *Timer {
+	t := &Timer{
+		app:    app,
+		kind:   kind,
+		owner:  app.resolve(options.Owner),
+		active: true,
+	}
+	app.timers[t] = struct{}{}
+	return t
+}
+
+func milliseconds(d time.Duration) int {
+	return int(d / time.Millisecond)
+}
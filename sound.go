@@ -0,0 +1,160 @@
+package main
+
+import (
+	"URLchess/shf/js"
+	"URLchess/urlchess"
+
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position"
+	"github.com/andrewbackes/chess/position/move"
+)
+
+type soundEffect int
+
+const (
+	soundNone soundEffect = iota
+	soundMove
+	soundCapture
+	soundCastling
+	soundCheck
+	soundGameEnd
+)
+
+// Tone is a single oscillator beep. Start and duration are in seconds from the effect start.
+type tone struct {
+	Type      string
+	Frequency float64
+	Start     float64
+	Duration  float64
+	Volume    float64
+}
+
+// Tones of sound effects. Effects are synthesized, so no sound samples have to be downloaded.
+var soundTones = map[soundEffect][]tone{
+	soundMove: {
+		{"triangle", 440, 0, 0.08, 0.3},
+	},
+	soundCapture: {
+		{"square", 220, 0, 0.06, 0.15},
+		{"triangle", 330, 0.05, 0.1, 0.3},
+	},
+	soundCastling: {
+		{"triangle", 440, 0, 0.07, 0.3},
+		{"triangle", 440, 0.1, 0.07, 0.3},
+	},
+	soundCheck: {
+		{"sine", 660, 0, 0.1, 0.3},
+		{"sine", 880, 0.1, 0.15, 0.3},
+	},
+	soundGameEnd: {
+		{"sine", 523.25, 0, 0.15, 0.3},
+		{"sine", 659.25, 0.15, 0.15, 0.3},
+		{"sine", 783.99, 0.3, 0.4, 0.3},
+	},
+}
+
+// Plays sound effects using Web Audio API.
+// The audio context is created lazily, because browsers allow audio only after user interaction with the page.
+type soundPlayer struct {
+	context js.Object
+	// Is false, if there is no Web Audio API in the browser.
+	supported bool
+	created   bool
+}
+
+// Returns audio context, or false if it can not be created.
+func (sp *soundPlayer) audioContext() (js.Object, bool) {
+	if sp.created {
+		return sp.context, sp.supported
+	}
+	sp.created = true
+
+	constructor := js.Global().Get("AudioContext")
+	if js.IsUndefined(constructor) {
+		constructor = js.Global().Get("webkitAudioContext")
+	}
+	if js.IsUndefined(constructor) {
+		return sp.context, false
+	}
+	sp.context = constructor.New()
+	sp.supported = true
+	return sp.context, true
+}
+
+// Plays sound effect. Does nothing if sound is not supported by the browser.
+func (sp *soundPlayer) Play(effect soundEffect) {
+	if sp == nil {
+		return
+	}
+	tones, ok := soundTones[effect]
+	if !ok {
+		return
+	}
+	ctx, ok := sp.audioContext()
+	if !ok {
+		return
+	}
+	if ctx.Get("state").String() == "suspended" {
+		ctx.Call("resume")
+	}
+
+	now := ctx.Get("currentTime").Float()
+	for _, t := range tones {
+		oscillator := ctx.Call("createOscillator")
+		oscillator.Set("type", t.Type)
+		oscillator.Get("frequency").Set("value", t.Frequency)
+
+		// Short attack & exponential decay, so the beep does not click.
+		gain := ctx.Call("createGain")
+		start, end := now+t.Start, now+t.Start+t.Duration
+		gain.Get("gain").Call("setValueAtTime", 0.0001, start)
+		gain.Get("gain").Call("exponentialRampToValueAtTime", t.Volume, start+0.01)
+		gain.Get("gain").Call("exponentialRampToValueAtTime", 0.0001, end)
+
+		oscillator.Call("connect", gain)
+		gain.Call("connect", ctx.Get("destination"))
+		oscillator.Call("start", start)
+		oscillator.Call("stop", end+0.05)
+	}
+}
+
+// Returns sound effect for the move which leads to position with number n.
+// The most important event of the move wins: game end, check, castling, capture and then a simple move.
+func (ch *ChessGameModel) moveSound(n int) soundEffect {
+	if ch == nil || n <= 0 || n >= len(ch.Game.Positions) {
+		return soundNone
+	}
+	prev, pos := ch.Game.Positions[n-1], ch.Game.Positions[n]
+
+	if n == len(ch.Game.Positions)-1 && ch.Game.Status() != game.InProgress {
+		return soundGameEnd
+	}
+	if pos.Check(pos.ActiveColor) {
+		return soundCheck
+	}
+	if isCastling(prev, pos.LastMove) {
+		return soundCastling
+	}
+	if top := urlchess.CapturedPiece(prev, pos.LastMove); top.Type != piece.None {
+		return soundCapture
+	}
+	return soundMove
+}
+
+// Returns true if the move is a king move by two files.
+func isCastling(p *position.Position, m move.Move) bool {
+	if p.OnSquare(m.From()).Type != piece.King {
+		return false
+	}
+	files := int(m.From())%8 - int(m.To())%8
+	return files == 2 || files == -2
+}
+
+// Plays sound of the last move in game, if sounds are enabled.
+func (m *Model) playLastMoveSound() {
+	if !m.Settings.Sounds.Get() {
+		return
+	}
+	m.sounds.Play(m.ChessGame.moveSound(len(m.ChessGame.Game.Positions) - 1))
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"URLchess/shf/js"
+	"strings"
+
+	"github.com/andrewbackes/chess/piece"
+)
+
+// Set of piece images. Pieces of set without Image function are rendered as font figurines.
+type pieceSet struct {
+	Id    string
+	Label string
+	// Returns image URL for piece.
+	Image func(p piece.Piece) string
+}
+
+var pieceSets = []pieceSet{
+	{Id: "font", Label: "classic"},
+	{Id: "flat", Label: "flat", Image: func(p piece.Piece) string {
+		color := "w"
+		if p.Color == piece.Black {
+			color = "b"
+		}
+		return "assets/pieces/flat/" + color + strings.ToUpper(p.Type.String()) + ".svg"
+	}},
+}
+
+// Board colour schemes. Ids are used as body class names "board-<id>", see URLchess.css.
+var boardThemes = [][2]string{
+	{"brown", "brown"},
+	{"green", "green"},
+	{"blue", "blue"},
+	{"gray", "gray"},
+}
+
+// Colour schemes of the page. Ids are used as body class names "<id>-mode", see URLchess.css.
+// The "auto" scheme follows browser's prefers-color-scheme.
+var colorSchemes = [][2]string{
+	{"auto", "automatic"},
+	{"dark", "dark"},
+	{"light", "light"},
+}
+
+// Currently used piece set.
+var currentPieceSet = pieceSets[0]
+
+// Sets piece classes & content of elm, to show piece p with current piece set.
+// Image pieces keep the figurine text (transparent), so they take up the same space as font pieces.
+func renderPiece(elm js.Object, p piece.Piece) {
+	elm.Set("className", "piece")
+	if p.Color != piece.NoColor {
+		elm.Get("classList").Call("add", strings.ToLower(p.Color.String()))
+	}
+	if p.Type != piece.None {
+		elm.Get("classList").Call("add", pieceTypesToName[p.Type])
+	}
+	elm.Set("textContent", p.Figurine())
+
+	if currentPieceSet.Image == nil || p.Color == piece.NoColor || p.Type == piece.None {
+		elm.Get("style").Set("backgroundImage", "")
+		return
+	}
+	elm.Get("classList").Call("add", "image")
+	elm.Get("style").Set("backgroundImage", "url(\""+currentPieceSet.Image(p)+"\")")
+}
+
+// Re-renders all pieces in document with current piece set. Piece is recognized by element's color & type classes.
+func redrawPieces() {
+	pieces := js.Global().Get("document").Call("querySelectorAll", ".piece")
+	for i := 0; i < pieces.Get("length").Int(); i++ {
+		elm := pieces.Call("item", i)
+		p := piece.New(piece.NoColor, piece.None)
+		classList := elm.Get("classList")
+		for _, color := range piece.Colors {
+			if classList.Call("contains", strings.ToLower(color.String())).Bool() {
+				p.Color = color
+			}
+		}
+		for t, name := range pieceTypesToName {
+			if classList.Call("contains", name).Bool() {
+				p.Type = t
+			}
+		}
+		renderPiece(elm, p)
+	}
+}
+
+// Sets board colour scheme by its id.
+func setBoardTheme(id string) {
+	classList := js.Global().Get("document").Get("body").Get("classList")
+	for _, t := range boardThemes {
+		classList.Call("toggle", "board-"+t[0], t[0] == id)
+	}
+}
+
+// Sets page colour scheme by its id.
+func setColorScheme(id string) {
+	classList := js.Global().Get("document").Get("body").Get("classList")
+	for _, s := range colorSchemes {
+		classList.Call("toggle", s[0]+"-mode", s[0] == id)
+	}
+}
+
+// Sets piece set by its id and redraws all pieces. Unknown id sets the default piece set.
+func setPieceSet(id string) {
+	currentPieceSet = pieceSets[0]
+	for _, ps := range pieceSets {
+		if ps.Id == id {
+			currentPieceSet = ps
+		}
+	}
+	redrawPieces()
+}
@@ -0,0 +1,538 @@
+//go:build !js || (!ecmascript && !wasm)
+
+package main
+
+import (
+	"URLchess/shf"
+	"URLchess/shf/js"
+	"strings"
+	"testing"
+	"time"
+)
+
+// UI tests run the app in the in-memory DOM of shf/js, see "Testing the UI natively" in README.md.
+
+// Starts the app in a new page with location hash and lets its initial animations finish.
+func startTestApp(t testing.TB, hash string) *Model {
+	t.Helper()
+	js.ResetDOM()
+	if hash != "" {
+		js.SetHash(hash)
+	}
+	model, err := start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	js.AdvanceTime(time.Second)
+	return model
+}
+
+func query(t testing.TB, selector string) js.Object {
+	t.Helper()
+	e := js.QuerySelector(selector)
+	if js.IsNull(e) {
+		t.Fatalf("no element %q", selector)
+	}
+	return e
+}
+
+// Clicks element and lets queued events, timers and animations run.
+func click(t *testing.T, selector string) {
+	t.Helper()
+	js.Click(query(t, selector))
+	js.AdvanceTime(time.Second)
+}
+
+// Clicks button with text in element.
+func clickButton(t *testing.T, selector, text string) {
+	t.Helper()
+	for _, b := range js.QuerySelectorAll(selector + " button") {
+		if b.Get("textContent").String() == text {
+			js.Click(b)
+			js.AdvanceTime(time.Second)
+			return
+		}
+	}
+	t.Fatalf("no button %q in %q", text, selector)
+}
+
+func attr(t *testing.T, selector, name string) string {
+	t.Helper()
+	return query(t, selector).Call("getAttribute", name).String()
+}
+
+// Returns what is on square for screen readers, e.g. "e4, white pawn, last move".
+func squareLabel(t *testing.T, sq string) string {
+	t.Helper()
+	return attr(t, "#"+sq, "aria-label")
+}
+
+func expectSquare(t *testing.T, sq, piece string) {
+	t.Helper()
+	label := squareLabel(t, sq)
+	if got := strings.Split(label, ", ")[1]; got != piece {
+		t.Errorf("square %s has %q, want %q (label %q)", sq, got, piece, label)
+	}
+}
+
+func expectHash(t *testing.T, hash string) {
+	t.Helper()
+	if got := shf.LocationHash(); got != hash {
+		t.Errorf("location hash is %q, want %q", got, hash)
+	}
+}
+
+// Returns game link hash of SAN moves played from the initial position.
+func gameHash(t *testing.T, moves ...string) string {
+	t.Helper()
+	hash, _ := playedGame(t, moves...)
+	return "#" + hash
+}
+
+func TestUISelectAndMovePiece(t *testing.T) {
+	startTestApp(t, "")
+	expectSquare(t, "e2", "white pawn")
+
+	click(t, "#e2")
+	if label := squareLabel(t, "e4"); !strings.Contains(label, "possible move") {
+		t.Errorf("e4 is not marked as possible move after selecting e2: %q", label)
+	}
+	if label := squareLabel(t, "e5"); strings.Contains(label, "possible move") {
+		t.Errorf("e5 is marked as possible move after selecting e2: %q", label)
+	}
+
+	// Clicking selected piece again cancels the selection.
+	click(t, "#e2")
+	if label := squareLabel(t, "e4"); strings.Contains(label, "possible move") {
+		t.Errorf("e4 is still marked as possible move after deselecting e2: %q", label)
+	}
+
+	click(t, "#e2")
+	click(t, "#e4")
+	expectSquare(t, "e2", "empty")
+	expectSquare(t, "e4", "white pawn")
+	expectHash(t, gameHash(t, "e4"))
+	if class := attr(t, "#move-status", "class"); strings.Contains(class, "hidden") {
+		t.Errorf("game link dialog is not shown after move, class %q", class)
+	}
+	if link := query(t, "#move-status input").Get("value").String(); !strings.HasSuffix(link, gameHash(t, "e4")) {
+		t.Errorf("game link is %q, want link to %q", link, gameHash(t, "e4"))
+	}
+	clickButton(t, "#move-status", "close")
+	if class := attr(t, "#move-status", "class"); !strings.Contains(class, "hidden") {
+		t.Errorf("game link dialog is shown after closing, class %q", class)
+	}
+
+	// Black can not move white pieces.
+	click(t, "#d2")
+	if label := squareLabel(t, "d4"); strings.Contains(label, "possible move") {
+		t.Errorf("white piece can be moved when black is on the move: %q", label)
+	}
+	click(t, "#e7")
+	click(t, "#e5")
+	expectSquare(t, "e5", "black pawn")
+	expectHash(t, gameHash(t, "e4", "e5"))
+}
+
+func TestUIPromotion(t *testing.T) {
+	moves := []string{"a4", "b5", "axb5", "a6", "bxa6", "Bb7", "axb7", "Nc6"}
+	startTestApp(t, gameHash(t, moves...))
+	expectSquare(t, "b7", "white pawn")
+
+	click(t, "#b7")
+	click(t, "#b8")
+	if hidden := attr(t, "#promotion-overlay", "aria-hidden"); hidden != "false" {
+		t.Fatalf("promotion overlay is not shown, aria-hidden %q", hidden)
+	}
+	expectHash(t, gameHash(t, moves...))
+
+	click(t, "#promote-to-knight")
+	if hidden := attr(t, "#promotion-overlay", "aria-hidden"); hidden != "true" {
+		t.Errorf("promotion overlay is shown after promotion, aria-hidden %q", hidden)
+	}
+	expectSquare(t, "b7", "empty")
+	expectSquare(t, "b8", "white knight")
+	expectHash(t, gameHash(t, append(moves, "b8=N")...))
+
+	// With always queen setting the piece is not asked for.
+	startTestApp(t, gameHash(t, moves...))
+	click(t, "#setting-alwaysQueen")
+	click(t, "#b7")
+	click(t, "#a8")
+	if hidden := attr(t, "#promotion-overlay", "aria-hidden"); hidden != "true" {
+		t.Errorf("promotion overlay is shown with always queen setting, aria-hidden %q", hidden)
+	}
+	expectSquare(t, "a8", "white queen")
+	expectHash(t, gameHash(t, append(moves, "bxa8=Q")...))
+}
+
+func TestUICaptureAnimation(t *testing.T) {
+	startTestApp(t, gameHash(t, "e4", "d5"))
+	click(t, "#e4")
+	js.Click(query(t, "#d5"))
+	js.AdvanceTime(100 * time.Millisecond)
+	if js.IsNull(js.QuerySelector("#d5 .ghost")) {
+		t.Error("captured piece is not fading out on d5")
+	}
+	js.AdvanceTime(time.Second)
+	if !js.IsNull(js.QuerySelector(".ghost")) {
+		t.Error("captured piece is still shown after the animation")
+	}
+	expectSquare(t, "d5", "white pawn")
+}
+
+func TestUIHashChangeBackAndForward(t *testing.T) {
+	startTestApp(t, "")
+	expectSquare(t, "e4", "empty")
+
+	js.SetHash(gameHash(t, "e4", "e5"))
+	js.AdvanceTime(time.Second)
+	expectSquare(t, "e4", "white pawn")
+	expectSquare(t, "e5", "black pawn")
+
+	click(t, "#g1")
+	click(t, "#f3")
+	expectSquare(t, "f3", "white knight")
+	expectHash(t, gameHash(t, "e4", "e5", "Nf3"))
+
+	js.Back()
+	js.AdvanceTime(time.Second)
+	expectHash(t, gameHash(t, "e4", "e5"))
+	expectSquare(t, "f3", "empty")
+	expectSquare(t, "g1", "white knight")
+
+	js.Back()
+	js.AdvanceTime(time.Second)
+	expectHash(t, "")
+	expectSquare(t, "e4", "empty")
+	expectSquare(t, "e2", "white pawn")
+
+	js.Forward()
+	js.AdvanceTime(time.Second)
+	js.Forward()
+	js.AdvanceTime(time.Second)
+	expectHash(t, gameHash(t, "e4", "e5", "Nf3"))
+	expectSquare(t, "f3", "white knight")
+
+	// Invalid link is reported and replaced by the game link, so going back skips it.
+	js.SetHash("#invalid!")
+	js.AdvanceTime(time.Second)
+	if hidden := attr(t, "#notification-overlay", "aria-hidden"); hidden != "false" {
+		t.Errorf("invalid link is not reported, notification aria-hidden %q", hidden)
+	}
+	expectHash(t, gameHash(t, "e4", "e5", "Nf3"))
+	expectSquare(t, "f3", "white knight")
+}
+
+func TestUIStartWithInvalidLink(t *testing.T) {
+	for _, hash := range []string{"#/library", "#zz!!", gameHash(t, "e4") + "!"} {
+		t.Run(hash, func(t *testing.T) {
+			startTestApp(t, hash)
+			if hidden := attr(t, "#notification-overlay", "aria-hidden"); hidden != "false" {
+				t.Errorf("invalid link is not reported, notification aria-hidden %q", hidden)
+			}
+			if text := query(t, "#notification-overlay").Get("textContent").String(); !strings.Contains(text, "Invalid game link") {
+				t.Errorf("notification %q does not report invalid link", text)
+			}
+			// New game is opened instead.
+			expectHash(t, "")
+			expectSquare(t, "e2", "white pawn")
+			expectSquare(t, "e4", "empty")
+
+			// The app works as usual.
+			click(t, "#e2")
+			click(t, "#e4")
+			expectHash(t, gameHash(t, "e4"))
+		})
+	}
+
+	// Known page is opened over a new game, without error.
+	startTestApp(t, "#/settings")
+	if hidden := attr(t, "#settings-overlay", "aria-hidden"); hidden != "false" {
+		t.Errorf("settings are not shown, aria-hidden %q", hidden)
+	}
+	if hidden := attr(t, "#notification-overlay", "aria-hidden"); hidden != "true" {
+		t.Errorf("notification is shown for settings link, aria-hidden %q", hidden)
+	}
+}
+
+func TestUIBrowseMoves(t *testing.T) {
+	hash := gameHash(t, "e4", "e5", "Nf3")
+	startTestApp(t, hash)
+
+	click(t, "#game-status-control .start")
+	expectHash(t, "")
+	expectSquare(t, "e4", "empty")
+	expectSquare(t, "g1", "white knight")
+
+	click(t, "#game-status-control .next")
+	expectHash(t, gameHash(t, "e4"))
+	expectSquare(t, "e4", "white pawn")
+	expectSquare(t, "e5", "empty")
+
+	click(t, "#game-status-control .end")
+	expectHash(t, hash)
+	expectSquare(t, "f3", "white knight")
+
+	click(t, "#game-status-control .previous")
+	expectHash(t, gameHash(t, "e4", "e5"))
+	expectSquare(t, "f3", "empty")
+
+	// Replay starts from the initial position and stops at the last move.
+	click(t, "#game-status-replay .play")
+	js.AdvanceTime(10 * time.Second)
+	expectHash(t, hash)
+	expectSquare(t, "f3", "white knight")
+	if class := attr(t, "#game-status-replay button", "class"); class != "play" {
+		t.Errorf("replay button class is %q after replay ended, want %q", class, "play")
+	}
+}
+
+func TestUIExport(t *testing.T) {
+	startTestApp(t, gameHash(t, "e4", "e5", "Nf3"))
+
+	click(t, "#header")
+	clickButton(t, "#notification-overlay", "export game")
+	if hidden := attr(t, "#export-overlay", "aria-hidden"); hidden != "false" {
+		t.Fatalf("export overlay is not shown, aria-hidden %q", hidden)
+	}
+	pgn := query(t, "#export-output").Get("value").String()
+	if !strings.Contains(pgn, "1. e4 e5 2. Nf3") {
+		t.Errorf("exported PGN does not contain the game moves:\n%s", pgn)
+	}
+	if fen := query(t, "#export-overlay .fen input").Get("value").String(); fen != "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2" {
+		t.Errorf("exported FEN is %q", fen)
+	}
+
+	// Options change the exported PGN.
+	options := js.QuerySelectorAll("#export-overlay .options input")
+	if len(options) != 2 {
+		t.Fatalf("%d export option checkboxes, want 2", len(options))
+	}
+	js.Click(options[1])
+	js.AdvanceTime(time.Second)
+	if tags := query(t, "#export-output").Get("value").String(); strings.Contains(tags, "[Event ") {
+		t.Errorf("exported PGN contains tags after unchecking include tags:\n%s", tags)
+	}
+	js.Click(options[1])
+	js.AdvanceTime(time.Second)
+	if got := query(t, "#export-output").Get("value").String(); got != pgn {
+		t.Errorf("exported PGN differs after checking include tags again:\n%s", got)
+	}
+
+	// Known tags, which are not in the editor, are offered to add.
+	offered := func() []string {
+		names := []string{}
+		for _, o := range js.QuerySelectorAll("#export-tag-names option") {
+			names = append(names, o.Get("value").String())
+		}
+		return names
+	}
+	if names := offered(); len(names) != 11 || names[0] != "WhiteElo" {
+		t.Errorf("offered tags %q, want 11 tags from WhiteElo", names)
+	}
+	js.ChangeValue(query(t, "#export-overlay .add input"), "WhiteElo")
+	clickButton(t, "#export-overlay .add", "add tag")
+	if names := offered(); len(names) != 10 || names[0] != "BlackElo" {
+		t.Errorf("offered tags after adding WhiteElo %q, want 10 tags from BlackElo", names)
+	}
+
+	clickButton(t, "#export-overlay", "download PGN")
+	downloads := js.Downloads()
+	if len(downloads) != 1 {
+		t.Fatalf("%d files downloaded, want 1", len(downloads))
+	}
+	if !strings.HasSuffix(downloads[0].Filename, ".pgn") {
+		t.Errorf("downloaded file name is %q, want .pgn file", downloads[0].Filename)
+	}
+	if string(downloads[0].Data) != pgn {
+		t.Errorf("downloaded PGN differs from the shown one:\n%s", downloads[0].Data)
+	}
+
+	clickButton(t, "#export-overlay", "close")
+	if hidden := attr(t, "#export-overlay", "aria-hidden"); hidden != "true" {
+		t.Errorf("export overlay is shown after closing, aria-hidden %q", hidden)
+	}
+}
+
+func TestUIBrowseKeepsConditions(t *testing.T) {
+	hash := "#" + conditionsHash(t, "Nc6 Bb5", 0, "e4", "e5", "Nf3")
+	conditions := strings.TrimPrefix(hash, gameHash(t, "e4", "e5", "Nf3"))
+	startTestApp(t, hash)
+
+	click(t, "#game-status-control .previous")
+	expectHash(t, gameHash(t, "e4", "e5")+conditions)
+	click(t, "#game-status-control .next")
+	expectHash(t, hash)
+	click(t, "#game-status-control .start")
+	click(t, "#game-status-control .end")
+	expectHash(t, hash)
+
+	// Moves list links too.
+	for _, a := range js.QuerySelectorAll("#game-status-moves a[href]") {
+		if href := a.Get("href").String(); !strings.HasSuffix(href, conditions) {
+			t.Errorf("move link %q does not keep conditions %q", href, conditions)
+		}
+	}
+
+	// Conditions apply only after the last move of the received game, not in browsed positions.
+	click(t, "#game-status-control .previous")
+	click(t, "#game-status-control .previous")
+	click(t, "#e7")
+	click(t, "#e5")
+	expectHash(t, gameHash(t, "e4", "e5"))
+	expectSquare(t, "f3", "empty")
+
+	// Back in the received game, the prepared reply is played.
+	click(t, "#game-status-control .end")
+	expectHash(t, hash)
+	click(t, "#b8")
+	click(t, "#c6")
+	expectHash(t, "#"+conditionsHash(t, "", 5, "e4", "e5", "Nf3", "Nc6", "Bb5"))
+	expectSquare(t, "b5", "white bishop")
+}
+
+func TestUIReplayKeepsConditions(t *testing.T) {
+	hash := "#" + conditionsHash(t, "Nc6 Bb5", 0, "e4", "e5", "Nf3")
+	startTestApp(t, hash)
+
+	js.Click(query(t, "#game-status-replay .play"))
+	js.RunPending()
+	expectHash(t, gameHash(t)+strings.TrimPrefix(hash, gameHash(t, "e4", "e5", "Nf3")))
+	js.AdvanceTime(10 * time.Second)
+	expectHash(t, hash)
+
+	// The prepared reply is still played after the replay.
+	click(t, "#b8")
+	click(t, "#c6")
+	expectSquare(t, "b5", "white bishop")
+}
+
+func TestUIHistoryModes(t *testing.T) {
+	for _, tc := range []struct {
+		mode string
+		// Game hashes shown by going back from the new game, see play below.
+		back [][]string
+	}{
+		{"moves", [][]string{{"e4"}, {"e4", "e5"}, {"e4"}, {}}},
+		{"all", [][]string{{"e4"}, {"e4", "e5"}, {"e4", "e5", "Nf3"}, {"e4", "e5"}, {"e4"}, {}}},
+		{"game", [][]string{{"e4"}}},
+	} {
+		t.Run(tc.mode, func(t *testing.T) {
+			model := startTestApp(t, "")
+			model.Settings.History.Set(tc.mode)
+
+			// Moves, browsing back to the first move and a new game.
+			click(t, "#e2")
+			click(t, "#e4")
+			click(t, "#e7")
+			click(t, "#e5")
+			click(t, "#g1")
+			click(t, "#f3")
+			click(t, "#game-status-control .previous")
+			click(t, "#game-status-control .previous")
+			expectHash(t, gameHash(t, "e4"))
+			click(t, "#header")
+			clickButton(t, "#notification-overlay", "new game")
+			expectHash(t, "")
+
+			// Shown game follows the location hash in both directions.
+			expectGame := func(moves []string) {
+				t.Helper()
+				hash := ""
+				if len(moves) > 0 {
+					hash = gameHash(t, moves...)
+				}
+				expectHash(t, hash)
+				if got := model.ChessGame.Hash(); got != strings.TrimPrefix(hash, "#") {
+					t.Errorf("game hash is %q, want %q", got, hash)
+				}
+			}
+			for _, moves := range tc.back {
+				js.Back()
+				js.AdvanceTime(time.Second)
+				expectGame(moves)
+			}
+			js.Back()
+			js.AdvanceTime(time.Second)
+			expectGame(tc.back[len(tc.back)-1])
+
+			for i := len(tc.back) - 2; i >= 0; i-- {
+				js.Forward()
+				js.AdvanceTime(time.Second)
+				expectGame(tc.back[i])
+			}
+			js.Forward()
+			js.AdvanceTime(time.Second)
+			expectGame(nil)
+			js.Forward()
+			js.AdvanceTime(time.Second)
+			expectGame(nil)
+		})
+	}
+}
+
+func TestUIPossibleMovesAfterPositionChange(t *testing.T) {
+	startTestApp(t, gameHash(t, "e4", "e5", "Nf3", "Nc6"))
+	expectPossible := func(from, to string) {
+		t.Helper()
+		click(t, "#"+from)
+		if label := squareLabel(t, to); !strings.Contains(label, "possible move") {
+			t.Errorf("%s is not marked as possible move after selecting %s: %q", to, from, label)
+		}
+		click(t, "#"+from)
+	}
+	expectPossible("f1", "b5")
+
+	click(t, "#game-status-control .previous")
+	expectPossible("b8", "c6")
+	click(t, "#game-status-control .next")
+	expectPossible("f3", "g5")
+
+	js.SetHash(gameHash(t, "d4"))
+	js.AdvanceTime(time.Second)
+	expectPossible("d7", "d5")
+}
+
+// Selects and deselects a piece in a long game, each click updates the whole board.
+func BenchmarkUpdateModelLongGame(b *testing.B) {
+	model := startTestApp(b, "#"+longGameHash(b, 200))
+	p := model.ChessGame.Game.Position()
+	from := ""
+	for m := range p.LegalMoves() {
+		from = m.From().String()
+		break
+	}
+	square := query(b, "#"+from)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		js.Click(square)
+		js.RunPending()
+	}
+}
+
+func TestUIMoveStatusTipChangesOnInteraction(t *testing.T) {
+	startTestApp(t, "")
+	click(t, "#e2")
+	click(t, "#e4")
+	tip := func() string { return query(t, "#move-status .tip").Get("textContent").String() }
+	first := tip()
+
+	// Opening a notification is an interaction, which shows next tip.
+	click(t, "#header")
+	second := tip()
+	if second == first {
+		t.Errorf("tip %q did not change after opening quick actions", first)
+	}
+
+	// Event, which changes nothing, keeps the tip.
+	click(t, "#footer a")
+	if got := tip(); got != second {
+		t.Errorf("tip changed from %q to %q after footer link click", second, got)
+	}
+
+	click(t, "#move-status .tip")
+	if got := tip(); got == second {
+		t.Errorf("tip %q did not change after clicking it", got)
+	}
+}
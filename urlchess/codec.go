@@ -1,10 +1,14 @@
-package main
+// Package urlchess holds the browser independent parts of URLchess:
+// encoding of game moves to URL hash & back, game replay with captured pieces and chess positions helpers.
+// Besides the web app, it is used by the command line tool (see cmd/urlchess).
+package urlchess
 
 import (
 	"errors"
 	"strconv"
 	"strings"
 
+	"github.com/andrewbackes/chess/game"
 	"github.com/andrewbackes/chess/piece"
 	"github.com/andrewbackes/chess/position/move"
 	"github.com/andrewbackes/chess/position/square"
@@ -27,7 +31,8 @@ var encodePieceToPromotionChar map[piece.Type]byte = func() map[piece.Type]byte
 	return res
 }()
 
-func encodeMove(m move.Move) (string, error) {
+// Encodes move to 2 characters (source & destination square), followed by promotion character, if the move is a promotion.
+func EncodeMove(m move.Move) (string, error) {
 	res := ""
 
 	if int(m.Source) < 0 || int(m.Source) >= len(encodePosAlphabet) {
@@ -51,11 +56,12 @@ func encodeMove(m move.Move) (string, error) {
 	return res, nil
 }
 
-func EncodeGame(g *ChessGameModel) (string, error) {
+// Encodes all moves of the game to moves hash.
+func EncodeGame(g *game.Game) (string, error) {
 	res := ""
-	for _, position := range g.game.Positions {
+	for _, position := range g.Positions {
 		if position.LastMove != move.Null {
-			if m, err := encodeMove(position.LastMove); err != nil {
+			if m, err := EncodeMove(position.LastMove); err != nil {
 				return "", err
 			} else {
 				res += m
@@ -65,6 +71,22 @@ func EncodeGame(g *ChessGameModel) (string, error) {
 	return res, nil
 }
 
+// Plays SAN moves (e.g. "e4", "Nf3", "O-O") from the initial position.
+func PlaySAN(moves ...string) (*game.Game, error) {
+	g := game.New()
+	for i, san := range moves {
+		m, err := g.Position().ParseMove(san)
+		if err == nil {
+			_, err = g.MakeMove(m)
+		}
+		if err != nil {
+			return nil, errors.New("move " + strconv.Itoa(i+1) + " " + strconv.Quote(san) + ": " + err.Error())
+		}
+	}
+	return g, nil
+}
+
+// Decodes moves hash to moves. Moves are not checked for legality, see DecodeGame.
 func DecodeMoves(moves string) ([]move.Move, error) {
 	res := []move.Move{}
 	if moves == "" {
@@ -0,0 +1,154 @@
+package urlchess
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position/move"
+	"github.com/andrewbackes/chess/position/square"
+)
+
+// Plays SAN moves from the initial position.
+func playSAN(t *testing.T, moves ...string) *game.Game {
+	t.Helper()
+	g, err := PlaySAN(moves...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// White pawn on b7 can promote on a8 (capture) or b8.
+var promotionGame = []string{"a4", "b5", "axb5", "a6", "bxa6", "Bb7", "axb7", "Nc6"}
+
+func TestEncodeMove(t *testing.T) {
+	tests := []struct {
+		move move.Move
+		code string
+	}{
+		{move.Move{Source: square.H1, Destination: square.H2}, "AI"},
+		{move.Move{Source: square.E2, Destination: square.E4}, "Lb"},
+		{move.Move{Source: square.A8, Destination: square.A1}, "_H"},
+		{move.Move{Source: square.B7, Destination: square.B8, Promote: piece.Rook}, "2-@"},
+		{move.Move{Source: square.B7, Destination: square.B8, Promote: piece.Knight}, "2-$"},
+		{move.Move{Source: square.B7, Destination: square.B8, Promote: piece.Bishop}, "2-^"},
+		{move.Move{Source: square.B7, Destination: square.A8, Promote: piece.Queen}, "2_*"},
+	}
+	for _, test := range tests {
+		code, err := EncodeMove(test.move)
+		if err != nil {
+			t.Errorf("EncodeMove(%s): %s", test.move, err)
+			continue
+		}
+		if code != test.code {
+			t.Errorf("EncodeMove(%s) = %q, want %q", test.move, code, test.code)
+		}
+		moves, err := DecodeMoves(code)
+		if err != nil {
+			t.Errorf("DecodeMoves(%q): %s", code, err)
+			continue
+		}
+		if len(moves) != 1 || moves[0] != test.move {
+			t.Errorf("DecodeMoves(%q) = %v, want [%s]", code, moves, test.move)
+		}
+	}
+
+	for _, m := range []move.Move{
+		{Source: square.NoSquare, Destination: square.E4},
+		{Source: square.E2, Destination: square.NoSquare},
+		{Source: square.B7, Destination: square.B8, Promote: piece.King},
+	} {
+		if code, err := EncodeMove(m); err == nil {
+			t.Errorf("EncodeMove(%#v) = %q, want error", m, code)
+		}
+	}
+}
+
+func TestGameRoundTrip(t *testing.T) {
+	tests := []struct {
+		moves []string
+		hash  string
+	}{
+		{nil, ""},
+		{[]string{"e4"}, "Lb"},
+		{[]string{"e4", "e5", "Nf3"}, "LbzjBS"},
+		// En passant & castling.
+		{[]string{"e4", "d5", "e5", "f5", "exf6", "Nc6", "Nf3", "Bg4", "Bc4", "Qd6", "O-O", "O-O-O"}, "Lb0kbjyijq-tBS9ZCd8sDB79"},
+		{append(promotionGame, "b8=R"), "Pf2mfm3vmv92v2-t2-@"},
+		{append(promotionGame, "b8=N"), "Pf2mfm3vmv92v2-t2-$"},
+		{append(promotionGame, "b8=B"), "Pf2mfm3vmv92v2-t2-^"},
+		{append(promotionGame, "bxa8=Q"), "Pf2mfm3vmv92v2-t2_*"},
+	}
+	for _, test := range tests {
+		g := playSAN(t, test.moves...)
+		hash, err := EncodeGame(g)
+		if err != nil {
+			t.Errorf("%v: %s", test.moves, err)
+			continue
+		}
+		if hash != test.hash {
+			t.Errorf("%v: hash %q, want %q", test.moves, hash, test.hash)
+		}
+
+		decoded, err := DecodeGame(hash)
+		if err != nil {
+			t.Errorf("%v: DecodeGame(%q): %s", test.moves, hash, err)
+			continue
+		}
+		if len(decoded.Positions) != len(g.Positions) {
+			t.Errorf("%v: decoded %d positions, want %d", test.moves, len(decoded.Positions), len(g.Positions))
+			continue
+		}
+		for i := range g.Positions {
+			if got, want := FEN(decoded.Positions[i]), FEN(g.Positions[i]); got != want {
+				t.Errorf("%v: decoded position %d is %q, want %q", test.moves, i, got, want)
+			}
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []string{
+		// Missing destination.
+		"L",
+		"LbL",
+		// Characters out of the alphabet.
+		"!b",
+		"L!",
+		"Lb zj",
+		"#Lb",
+		// Promotion character without move.
+		"*",
+		// Illegal moves.
+		"bL",
+		"LbLb",
+		"AI",
+		// Promotion of a piece, which can not promote.
+		"Lb*",
+		// Moves after checkmate (fool's mate).
+		"KZ1rUe4cLb",
+	}
+	for _, hash := range tests {
+		if g, err := DecodeGame(hash); err == nil {
+			t.Errorf("DecodeGame(%q) = game with %d half-moves, want error", hash, len(g.Positions)-1)
+		}
+	}
+}
+
+func TestPlaySANInvalid(t *testing.T) {
+	for _, test := range []struct {
+		moves []string
+		err   string
+	}{
+		{[]string{"e4", "e4"}, `move 2 "e4": `},
+		{[]string{"e4", "e5", "Ke3"}, `move 3 "Ke3": `},
+		{[]string{"xyz"}, `move 1 "xyz": `},
+	} {
+		_, err := PlaySAN(test.moves...)
+		if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("PlaySAN(%q) error %v, want prefix %q", test.moves, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package urlchess
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position"
+	"github.com/andrewbackes/chess/position/board"
+	"github.com/andrewbackes/chess/position/square"
+)
+
+// Returns position in Forsyth-Edwards Notation.
+// Unlike fen.Encode from chess package, the halfmove clock is in half-moves, as the notation requires.
+func FEN(p *position.Position) string {
+	var b strings.Builder
+	// Square 0 is H1, so the board is written from square 63 (A8) down.
+	for rank := 7; rank >= 0; rank-- {
+		empty := 0
+		for file := 7; file >= 0; file-- {
+			pce := p.OnSquare(square.Square(rank*8 + file))
+			if pce.Type == piece.None {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				b.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			b.WriteString(pce.String())
+		}
+		if empty > 0 {
+			b.WriteString(strconv.Itoa(empty))
+		}
+		if rank > 0 {
+			b.WriteByte('/')
+		}
+	}
+
+	b.WriteByte(' ')
+	if p.ActiveColor == piece.Black {
+		b.WriteByte('b')
+	} else {
+		b.WriteByte('w')
+	}
+
+	b.WriteByte(' ')
+	rights := ""
+	castles := map[piece.Color][2]string{piece.White: {"K", "Q"}, piece.Black: {"k", "q"}}
+	for _, c := range piece.Colors {
+		if p.CastlingRights[c][board.ShortSide] {
+			rights += castles[c][0]
+		}
+		if p.CastlingRights[c][board.LongSide] {
+			rights += castles[c][1]
+		}
+	}
+	if rights == "" {
+		rights = "-"
+	}
+	b.WriteString(rights)
+
+	b.WriteByte(' ')
+	if p.EnPassant != square.NoSquare {
+		b.WriteString(p.EnPassant.String())
+	} else {
+		b.WriteByte('-')
+	}
+
+	b.WriteString(" " + strconv.FormatUint(p.FiftyMoveCount, 10))
+	b.WriteString(" " + strconv.Itoa(p.MoveNumber))
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package urlchess
+
+import "testing"
+
+func TestFEN(t *testing.T) {
+	tests := []struct {
+		moves []string
+		fen   string
+	}{
+		{nil, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"},
+		// En passant square after double pawn push.
+		{[]string{"e4"}, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"},
+		// Half-move clock after a piece move.
+		{[]string{"e4", "e5", "Nf3"}, "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2"},
+		// White lost castling rights by king move.
+		{[]string{"e4", "e5", "Ke2"}, "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPPKPPP/RNBQ1BNR b kq - 1 2"},
+		// En passant capture.
+		{[]string{"e4", "d5", "e5", "f5", "exf6"}, "rnbqkbnr/ppp1p1pp/5P2/3p4/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 3"},
+		// Black lost queen side castling by rook capture.
+		{append(promotionGame, "bxa8=Q"), "Q2qkbnr/2pppppp/2n5/8/8/8/1PPPPPPP/RNBQKBNR b KQk - 0 5"},
+	}
+	for _, test := range tests {
+		g := playSAN(t, test.moves...)
+		if got := FEN(g.Position()); got != test.fen {
+			t.Errorf("%v: FEN %q, want %q", test.moves, got, test.fen)
+		}
+	}
+}
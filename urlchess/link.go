@@ -0,0 +1,22 @@
+package urlchess
+
+import "strings"
+
+// Conditional replies are appended to game moves in the link hash, each line separated by ConditionSeparator. They are not part of the game.
+const ConditionSeparator = "~"
+
+// Game links can have their moves under this hash path, e.g. "#/game/<moves>". Links without path hold the moves right after "#".
+const GamePath = "/game/"
+
+// LinkHash returns game moves hash from URLchess link (or its hash), without the game path & conditional replies.
+func LinkHash(link string) string {
+	link = strings.TrimSpace(link)
+	if i := strings.Index(link, "#"); i >= 0 {
+		link = link[i+1:]
+	}
+	link = strings.TrimPrefix(link, GamePath)
+	if i := strings.Index(link, ConditionSeparator); i >= 0 {
+		link = link[:i]
+	}
+	return link
+}
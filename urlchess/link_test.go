@@ -0,0 +1,24 @@
+package urlchess
+
+import "testing"
+
+func TestLinkHash(t *testing.T) {
+	tests := []struct {
+		link, hash string
+	}{
+		{"", ""},
+		{"Lbzj", "Lbzj"},
+		{"#Lbzj", "Lbzj"},
+		{" https://jezek.github.io/URLchess/#Lbzj\n", "Lbzj"},
+		{"https://jezek.github.io/URLchess/#/game/Lbzj", "Lbzj"},
+		{"#/game/Lbzj~-tCm~.3", "Lbzj"},
+		{"#Lbzj~-tCm", "Lbzj"},
+		{"#~-tCm", ""},
+		{"#/settings", "/settings"},
+	}
+	for _, test := range tests {
+		if got := LinkHash(test.link); got != test.hash {
+			t.Errorf("LinkHash(%q) = %q, want %q", test.link, got, test.hash)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package urlchess
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/andrewbackes/chess/game"
+	"github.com/andrewbackes/chess/piece"
+	"github.com/andrewbackes/chess/position"
+	"github.com/andrewbackes/chess/position/move"
+)
+
+// Count of captured pieces.
+type ThrownOuts map[piece.Piece]uint8
+
+// Captured pieces after every half-move of a game. Index 0 is the initial position with no captured pieces.
+type GameThrownOuts []ThrownOuts
+
+// Returns copy of thrown outs with captured piece p added. If p is no piece, the copy is the same.
+func (t ThrownOuts) with(p piece.Piece) ThrownOuts {
+	res := ThrownOuts{}
+	for pce, c := range t {
+		res[pce] = c
+	}
+	if p.Type != piece.None {
+		res[p] = res[p] + 1
+	}
+	return res
+}
+
+// Returns pieces, which are in next thrown outs, but not in prev thrown outs.
+func AddedThrownOuts(prev, next ThrownOuts) ThrownOuts {
+	added := ThrownOuts{}
+	for p, c := range prev {
+		if next[p] > c {
+			added[p] = added[p] + (next[p] - c)
+		}
+	}
+	for p, c := range next {
+		if _, ok := prev[p]; !ok {
+			added[p] = added[p] + c
+		}
+	}
+	return added
+}
+
+// Returns piece captured by move m made in position p, or no piece, if the move is not a capture.
+func CapturedPiece(p *position.Position, m move.Move) piece.Piece {
+	// was a piece thrown out regulary? = move destination contains some piece
+	if pce := p.OnSquare(m.To()); pce.Type != piece.None {
+		return pce
+	}
+
+	// was en passant throw out? = moved piece is pawn and move destination is an en passan square in previous move
+	if mp := p.OnSquare(m.From()); mp.Type == piece.Pawn && m.To() == p.EnPassant {
+		return piece.New(piece.Colors[(p.ActiveColor+1)%2], piece.Pawn)
+	}
+
+	return piece.New(piece.NoColor, piece.None)
+}
+
+// Replay is a game played from the initial position, with its moves hash and captured pieces after every half-move.
+type Replay struct {
+	// Moves hash of all game moves.
+	Moves      string
+	Game       *game.Game
+	ThrownOuts GameThrownOuts
+}
+
+// Returns replay of a game with no moves.
+func NewReplay() *Replay {
+	return &Replay{
+		Game:       game.New(),
+		ThrownOuts: GameThrownOuts{ThrownOuts{}},
+	}
+}
+
+// Replays game to match moves from the moves hash.
+// If the hash can not be decoded, or contains illegal moves or moves after the game has ended, error is returned and replay is not changed.
+func (r *Replay) UpdateToHash(moves string) error {
+	decoded, err := DecodeMoves(moves)
+	if err != nil {
+		return errors.New("decoding moves error: " + err.Error())
+	}
+
+	next := NewReplay()
+	for i, m := range decoded {
+		if next.Game.Status() != game.InProgress {
+			return errors.New("Too many moves in url string! " + strconv.Itoa(i+1) + " moves are enough")
+		}
+		if err := next.MakeMove(m); err != nil {
+			return errors.New("Erroneous move number " + strconv.Itoa(i+1) + ": " + err.Error())
+		}
+	}
+	next.Moves = moves
+
+	*r = *next
+	return nil
+}
+
+// Makes legal move m at the end of the game and updates moves hash & thrown outs.
+func (r *Replay) MakeMove(m move.Move) error {
+	if r.Game == nil || len(r.ThrownOuts) != len(r.Game.Positions) {
+		return errors.New("replay is not initialized")
+	}
+	em, err := EncodeMove(m)
+	if err != nil {
+		return err
+	}
+
+	before := r.Game.Position()
+	if _, err := r.Game.MakeMove(m); err != nil {
+		return err
+	}
+	r.Moves += em
+	r.ThrownOuts = append(r.ThrownOuts, r.ThrownOuts[len(r.ThrownOuts)-1].with(CapturedPiece(before, m)))
+	return nil
+}
+
+// Returns moves hash of the game after half-move n.
+func (r *Replay) HashForHalfMove(n int) (string, error) {
+	return HashForHalfMove(r.Game, n)
+}
+
+// Returns moves hash of game g after half-move n. Half-move 0 is the initial position.
+func HashForHalfMove(g *game.Game, n int) (string, error) {
+	if n < 0 || n >= len(g.Positions) {
+		return "", errors.New("move no " + strconv.Itoa(n) + " is out of bounds <0, " + strconv.Itoa(len(g.Positions)-1) + ">")
+	}
+
+	hash := ""
+	for i := 1; i <= n; i++ {
+		em, err := EncodeMove(g.Positions[i].LastMove)
+		if err != nil {
+			return "", err
+		}
+		hash += em
+	}
+
+	return hash, nil
+}
+
+// Decodes moves hash and plays the moves from the initial position. See Replay.UpdateToHash.
+func DecodeGame(moves string) (*game.Game, error) {
+	r := NewReplay()
+	if err := r.UpdateToHash(moves); err != nil {
+		return nil, err
+	}
+	return r.Game, nil
+}
@@ -0,0 +1,129 @@
+package urlchess
+
+import (
+	"testing"
+
+	"github.com/andrewbackes/chess/piece"
+)
+
+var (
+	whitePawn   = piece.New(piece.White, piece.Pawn)
+	blackPawn   = piece.New(piece.Black, piece.Pawn)
+	blackRook   = piece.New(piece.Black, piece.Rook)
+	blackBishop = piece.New(piece.Black, piece.Bishop)
+)
+
+func TestReplayUpdateToHash(t *testing.T) {
+	r := NewReplay()
+	if err := r.UpdateToHash("LbzjBS"); err != nil {
+		t.Fatal(err)
+	}
+	if r.Moves != "LbzjBS" || len(r.Game.Positions) != 4 || len(r.ThrownOuts) != 4 {
+		t.Fatalf("replay of %q: moves %q, %d positions, %d thrown outs", "LbzjBS", r.Moves, len(r.Game.Positions), len(r.ThrownOuts))
+	}
+
+	// Invalid hash leaves the replay as it was.
+	for _, hash := range []string{"LbzjB", "LbLb", "KZ1rUe4cLb"} {
+		if err := r.UpdateToHash(hash); err == nil {
+			t.Errorf("UpdateToHash(%q) succeeded", hash)
+		}
+		if r.Moves != "LbzjBS" || len(r.Game.Positions) != 4 {
+			t.Errorf("UpdateToHash(%q) changed replay to %q", hash, r.Moves)
+		}
+	}
+
+	m, err := r.Game.Position().ParseMove("Nc6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.MakeMove(m); err != nil {
+		t.Fatal(err)
+	}
+	if want := mustEncode(t, "e4", "e5", "Nf3", "Nc6"); r.Moves != want {
+		t.Errorf("moves after Nc6 %q, want %q", r.Moves, want)
+	}
+	if err := (&Replay{}).MakeMove(m); err == nil {
+		t.Error("move made in uninitialized replay")
+	}
+}
+
+// Returns moves hash of SAN moves.
+func mustEncode(t *testing.T, moves ...string) string {
+	t.Helper()
+	hash, err := EncodeGame(playSAN(t, moves...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestHashForHalfMove(t *testing.T) {
+	r := NewReplay()
+	if err := r.UpdateToHash("LbzjBS"); err != nil {
+		t.Fatal(err)
+	}
+	for n, want := range []string{"", "Lb", "Lbzj", "LbzjBS"} {
+		got, err := r.HashForHalfMove(n)
+		if err != nil {
+			t.Errorf("half-move %d: %s", n, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("half-move %d: hash %q, want %q", n, got, want)
+		}
+	}
+	for _, n := range []int{-1, 4} {
+		if hash, err := r.HashForHalfMove(n); err == nil {
+			t.Errorf("half-move %d out of bounds: hash %q, want error", n, hash)
+		}
+	}
+}
+
+func TestThrownOuts(t *testing.T) {
+	tests := []struct {
+		moves []string
+		// Captured piece by the last move.
+		captured piece.Piece
+		thrown   ThrownOuts
+	}{
+		{[]string{"e4"}, piece.New(piece.NoColor, piece.None), ThrownOuts{}},
+		{[]string{"e4", "d5", "exd5"}, blackPawn, ThrownOuts{blackPawn: 1}},
+		{[]string{"e4", "d5", "exd5", "Qxd5"}, whitePawn, ThrownOuts{blackPawn: 1, whitePawn: 1}},
+		// En passant, the captured pawn is not on the destination square.
+		{[]string{"e4", "d5", "e5", "f5", "exf6"}, blackPawn, ThrownOuts{blackPawn: 1}},
+		{append(promotionGame, "bxa8=Q"), blackRook, ThrownOuts{blackPawn: 2, blackBishop: 1, blackRook: 1}},
+	}
+	for _, test := range tests {
+		r := NewReplay()
+		if err := r.UpdateToHash(mustEncode(t, test.moves...)); err != nil {
+			t.Fatalf("%v: %s", test.moves, err)
+		}
+		n := len(r.Game.Positions) - 1
+		if got := CapturedPiece(r.Game.Positions[n-1], r.Game.Positions[n].LastMove); got != test.captured {
+			t.Errorf("%v: captured piece %v, want %v", test.moves, got, test.captured)
+		}
+		if got := r.ThrownOuts[n]; !equalThrownOuts(got, test.thrown) {
+			t.Errorf("%v: thrown outs %v, want %v", test.moves, got, test.thrown)
+		}
+		if got, want := AddedThrownOuts(r.ThrownOuts[n-1], r.ThrownOuts[n]), (ThrownOuts{}).with(test.captured); !equalThrownOuts(got, want) {
+			t.Errorf("%v: added thrown outs %v, want %v", test.moves, got, want)
+		}
+	}
+
+	// Going back more moves at once adds all captured pieces between.
+	if got, want := AddedThrownOuts(ThrownOuts{blackPawn: 1}, ThrownOuts{blackPawn: 3, whitePawn: 1}), (ThrownOuts{blackPawn: 2, whitePawn: 1}); !equalThrownOuts(got, want) {
+		t.Errorf("added thrown outs %v, want %v", got, want)
+	}
+}
+
+func equalThrownOuts(a, b ThrownOuts) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for p, c := range a {
+		if b[p] != c {
+			return false
+		}
+	}
+	return true
+}